@@ -26,6 +26,14 @@ func NewInit() *cli.Command {
 				Value: ".",
 				Usage: "use the Godot project found at 'PATH'",
 			},
+			&cli.BoolFlag{
+				Name:  "from-presets",
+				Usage: "generate targets from the project's 'export_presets.cfg' file",
+			},
+			&cli.StringFlag{
+				Name:  "layout",
+				Usage: "generate the starter targets 'LAYOUT' (one of 'client', 'client-server', or 'client-dlc'; cannot be used with '--from-presets')",
+			},
 		},
 
 		Action: func(c *cli.Context) error {
@@ -47,7 +55,17 @@ func NewInit() *cli.Command {
 				}
 			}
 
-			return config.Init(path)
+			layout, err := config.ParseLayout(c.String("layout"))
+			if err != nil {
+				return UsageError{ctx: c, err: err}
+			}
+
+			opts := config.InitOptions{
+				FromPresets: c.Bool("from-presets"),
+				Layout:      layout,
+			}
+
+			return config.Init(path, opts)
 		},
 	}
 }
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/config"
+	"github.com/coffeebeats/gdbuild/pkg/godot/encryption"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+// A 'urfave/cli' command to manage script encryption keys.
+func NewKey() *cli.Command { //nolint:funlen
+	return &cli.Command{
+		Name:     "key",
+		Category: "Configuration",
+
+		Usage:     "generate and inspect script encryption keys",
+		UsageText: "gdbuild key <COMMAND> [OPTIONS]",
+
+		Subcommands: []*cli.Command{
+			{
+				Name:      "generate",
+				Usage:     "generate a random 256-bit encryption key and print it (or write it to a file)",
+				UsageText: "gdbuild key generate [OPTIONS]",
+
+				Flags: []cli.Flag{
+					newVerboseFlag(),
+
+					&cli.PathFlag{
+						Name:    "out",
+						Aliases: []string{"o"},
+						Usage:   "write the key to the file at 'PATH' (must not exist)",
+					},
+				},
+
+				Action: func(c *cli.Context) error {
+					if err := checkNoArgs(c); err != nil {
+						return err
+					}
+
+					key, err := encryption.Generate()
+					if err != nil {
+						return err
+					}
+
+					path := c.Path("out")
+					if path == "" {
+						fmt.Println(key) //nolint:forbidigo
+
+						return nil
+					}
+
+					f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, osutil.ModeUserRW)
+					if err != nil {
+						if errors.Is(err, fs.ErrExist) {
+							return fmt.Errorf("%w: refusing to overwrite key file: %s", fs.ErrExist, path)
+						}
+
+						return err
+					}
+
+					// NOTE: Don't leave behind a partially-written key file, which
+					// would otherwise block a subsequent attempt.
+					if _, err := f.WriteString(key + "\n"); err != nil {
+						return errors.Join(err, f.Close(), os.Remove(path))
+					}
+
+					if err := f.Close(); err != nil {
+						return errors.Join(err, os.Remove(path))
+					}
+
+					log.Infof("wrote encryption key to: %s (SHA-512/224 sum: %s)", path, encryption.Fingerprint(key))
+
+					return nil
+				},
+			},
+			{
+				Name:      "fingerprint",
+				Usage:     "print the SHA-512/224 sum of the encryption key configured for the project",
+				UsageText: "gdbuild key fingerprint [OPTIONS]",
+
+				Flags: []cli.Flag{
+					newVerboseFlag(),
+
+					&cli.PathFlag{
+						Name:    "config",
+						Aliases: []string{"c"},
+						Usage:   "use the 'gdbuild' configuration file found at 'PATH'",
+					},
+					&cli.PathFlag{
+						Name:  "project",
+						Usage: "use the Godot project found at 'PATH'",
+					},
+				},
+
+				Action: func(c *cli.Context) error {
+					if err := checkNoArgs(c); err != nil {
+						return err
+					}
+
+					pathManifest, _, err := parseConfigAndProjectPaths(c)
+					if err != nil {
+						return err
+					}
+
+					m, err := config.ParseFile(pathManifest)
+					if err != nil {
+						return err
+					}
+
+					rc := run.Context{PathManifest: osutil.Path(pathManifest)} //nolint:exhaustruct
+
+					ks, err := config.Encryption(&rc, m)
+					if err != nil {
+						return err
+					}
+
+					key, err := ks.Key(c.Context)
+					if err != nil {
+						return err
+					}
+
+					if key == "" {
+						return fmt.Errorf("%w: no encryption key is set", ErrMissingInput)
+					}
+
+					fmt.Println(encryption.Fingerprint(key)) //nolint:forbidigo
+
+					return nil
+				},
+			},
+		},
+	}
+}
+
+/* -------------------------- Function: checkNoArgs -------------------------- */
+
+func checkNoArgs(c *cli.Context) error {
+	if c.Args().Len() > 0 {
+		return UsageError{
+			ctx: c,
+			err: fmt.Errorf("%w: %s", ErrTooManyArguments, strings.Join(c.Args().Slice(), " ")),
+		}
+	}
+
+	return nil
+}
@@ -65,11 +65,19 @@ func main() { //nolint:funlen
 			/* ---------------------------- Configuration ---------------------------- */
 
 			NewInit(),
+			NewKey(),
 
 			/* ----------------------------- Build/Export ---------------------------- */
 
+			NewPatch(),
+			NewServe(),
+			NewSteam(),
 			NewTarget(),
 			NewTemplate(),
+
+			/* ------------------------------- Inspect ------------------------------- */
+
+			NewPack(),
 		},
 	}
 
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/coffeebeats/gdbuild/internal/archive"
+	"github.com/coffeebeats/gdbuild/pkg/godot/encryption"
+	"github.com/coffeebeats/gdbuild/pkg/godot/pck"
+	"github.com/coffeebeats/gdbuild/pkg/store"
+)
+
+// A 'urfave/cli' command to inspect the contents of Godot pack files.
+func NewPack() *cli.Command {
+	return &cli.Command{
+		Name:     "pack",
+		Category: "Inspect",
+
+		Usage:     "inspect the contents of Godot pack files or executables with an embedded pack",
+		UsageText: "gdbuild pack <COMMAND> [OPTIONS] <PATH>",
+
+		Subcommands: []*cli.Command{
+			{
+				Name:      "ls",
+				Usage:     "list the files contained in the pack file at 'PATH'",
+				UsageText: "gdbuild pack ls [OPTIONS] <PATH>",
+
+				Flags: []cli.Flag{
+					newVerboseFlag(),
+					newJSONFlag(),
+				},
+
+				Action: func(c *cli.Context) error {
+					p, err := openPackFromArgs(c)
+					if err != nil {
+						return err
+					}
+
+					if c.Bool("json") {
+						files := p.Files
+						if files == nil {
+							files = []pck.File{}
+						}
+
+						return printJSON(files)
+					}
+
+					return printPackFiles(p)
+				},
+			},
+			{
+				Name:      "info",
+				Usage:     "summarize the header of the pack file at 'PATH'",
+				UsageText: "gdbuild pack info [OPTIONS] <PATH>",
+
+				Flags: []cli.Flag{
+					newVerboseFlag(),
+					newJSONFlag(),
+				},
+
+				Action: func(c *cli.Context) error {
+					p, err := openPackFromArgs(c)
+					if err != nil {
+						return err
+					}
+
+					info := newPackInfo(p)
+
+					if c.Bool("json") {
+						return printJSON(info)
+					}
+
+					return printPackInfo(info)
+				},
+			},
+			{
+				Name:      "diff",
+				Usage:     "compare the files of the builds at 'OLD' and 'NEW', sorted by change in size",
+				UsageText: "gdbuild pack diff [OPTIONS] <OLD> <NEW>",
+
+				Flags: []cli.Flag{
+					newVerboseFlag(),
+					newJSONFlag(),
+				},
+
+				Action: func(c *cli.Context) error {
+					if c.Args().Len() < 2 { //nolint:gomnd
+						return UsageError{ctx: c, err: fmt.Errorf("%w: expected 'OLD' and 'NEW'", ErrMissingInput)}
+					}
+
+					if c.Args().Len() > 2 { //nolint:gomnd
+						return UsageError{
+							ctx: c,
+							err: fmt.Errorf("%w: %s", ErrTooManyArguments, strings.Join(c.Args().Slice()[2:], " "))}
+					}
+
+					before, err := readBuildFiles(c.Context, c.Args().Get(0))
+					if err != nil {
+						return err
+					}
+
+					after, err := readBuildFiles(c.Context, c.Args().Get(1))
+					if err != nil {
+						return err
+					}
+
+					diff := pck.DiffFiles(before, after)
+
+					if c.Bool("json") {
+						return printJSON(diff)
+					}
+
+					return printPackDiff(diff)
+				},
+			},
+		},
+	}
+}
+
+/* --------------------------- Function: newJSONFlag ------------------------- */
+
+func newJSONFlag() *cli.BoolFlag {
+	return &cli.BoolFlag{
+		Name:  "json",
+		Usage: "print output as JSON",
+	}
+}
+
+/* ------------------------ Function: openPackFromArgs ----------------------- */
+
+func openPackFromArgs(c *cli.Context) (*pck.Pack, error) {
+	path := c.Args().First()
+	if path == "" {
+		return nil, UsageError{ctx: c, err: fmt.Errorf("%w: path", ErrMissingInput)}
+	}
+
+	if c.Args().Len() > 1 {
+		return nil, UsageError{
+			ctx: c,
+			err: fmt.Errorf("%w: %s", ErrTooManyArguments, strings.Join(c.Args().Slice()[1:], " "))}
+	}
+
+	key, err := encryption.Decode(os.Getenv(encryption.EnvKey))
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := pck.OpenWithKey(path, key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read pack file: %s: %w", path, err)
+	}
+
+	if p.IsDirectoryEncrypted() && len(key) == 0 {
+		log.Warn(
+			"Pack directory is encrypted; set the encryption key to list its files.",
+			"path",
+			path,
+			"env",
+			encryption.EnvKey,
+		)
+	}
+
+	return p, nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                              Struct: packInfo                              */
+/* -------------------------------------------------------------------------- */
+
+// packInfo is a summary of a pack file's header and directory.
+type packInfo struct {
+	FormatVersion      uint32      `json:"format_version"`
+	GodotVersion       pck.Version `json:"godot_version"`
+	Embedded           bool        `json:"embedded"`
+	Offset             int64       `json:"offset"`
+	DirectoryEncrypted bool        `json:"directory_encrypted"`
+	FileCount          int         `json:"file_count"`
+	EncryptedFileCount int         `json:"encrypted_file_count"`
+	TotalSize          int64       `json:"total_size"`
+}
+
+/* -------------------------- Function: newPackInfo -------------------------- */
+
+func newPackInfo(p *pck.Pack) packInfo {
+	info := packInfo{
+		FormatVersion:      p.FormatVersion,
+		GodotVersion:       p.GodotVersion,
+		Embedded:           p.Embedded,
+		Offset:             p.Offset,
+		DirectoryEncrypted: p.IsDirectoryEncrypted(),
+		FileCount:          len(p.Files),
+		EncryptedFileCount: 0,
+		TotalSize:          0,
+	}
+
+	for _, f := range p.Files {
+		if f.IsEncrypted() {
+			info.EncryptedFileCount++
+		}
+
+		info.TotalSize += f.Size
+	}
+
+	return info
+}
+
+/* ------------------------- Function: printPackInfo ------------------------- */
+
+func printPackInfo(info packInfo) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+
+	fmt.Fprintf(w, "format version:\t%d\n", info.FormatVersion)
+	fmt.Fprintf(w, "godot version:\t%s\n", info.GodotVersion)
+	fmt.Fprintf(w, "embedded:\t%t (offset: %d)\n", info.Embedded, info.Offset)
+	fmt.Fprintf(w, "directory encrypted:\t%t\n", info.DirectoryEncrypted)
+
+	if !info.DirectoryEncrypted {
+		fmt.Fprintf(w, "files:\t%d (encrypted: %d)\n", info.FileCount, info.EncryptedFileCount)
+		fmt.Fprintf(w, "total size:\t%d bytes\n", info.TotalSize)
+	}
+
+	return w.Flush()
+}
+
+/* ------------------------ Function: printPackFiles ------------------------- */
+
+func printPackFiles(p *pck.Pack) error {
+	if p.IsDirectoryEncrypted() {
+		return fmt.Errorf(
+			"%w: cannot list files; the pack directory is encrypted",
+			ErrInvalidInput,
+		)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+
+	fmt.Fprintln(w, "OFFSET\tSIZE\tENCRYPTED\tPATH")
+
+	for _, f := range p.Files {
+		fmt.Fprintf(w, "%d\t%d\t%t\t%s\n", f.Offset, f.Size, f.IsEncrypted(), f.Path)
+	}
+
+	return w.Flush()
+}
+
+/* ------------------------- Function: readBuildFiles ------------------------ */
+
+// readBuildFiles reads the files of all pack files in the build at 'path'; see
+// 'resolveBuild' for the supported arguments.
+func readBuildFiles(ctx context.Context, path string) ([]pck.File, error) {
+	key, err := encryption.Decode(os.Getenv(encryption.EnvKey))
+	if err != nil {
+		return nil, err
+	}
+
+	path, cleanup, err := resolveBuild(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cleanup()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		p, err := openPackWithKey(path, key)
+		if err != nil {
+			return nil, err
+		}
+
+		return p.Files, nil
+	}
+
+	var files []pck.File
+
+	// NOTE: 'WalkDir' visits files in lexical order, so the result is stable.
+	if err := filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		p, err := openPackWithKey(path, key)
+		if err != nil {
+			if errors.Is(err, pck.ErrInvalidFormat) {
+				return nil // Not a pack file.
+			}
+
+			return err
+		}
+
+		files = append(files, p.Files...)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no pack files found: %s", ErrInvalidInput, path)
+	}
+
+	return files, nil
+}
+
+/* -------------------------- Function: resolveBuild ------------------------- */
+
+// resolveBuild returns a local path to the build at 'path', which may be a
+// pack file (or executable with an embedded pack), a directory of exported
+// artifacts, an archive of exported artifacts from the store, or the checksum
+// of an export cached in the store. The returned function must be called to
+// clean up any temporary files once the build is no longer needed.
+func resolveBuild(ctx context.Context, path string) (string, func(), error) {
+	noop := func() {}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) || strings.ContainsAny(path, `/\.`) {
+			return "", noop, err
+		}
+
+		// Interpret the argument as the checksum of a cached export.
+		storePath, err := store.Path()
+		if err != nil {
+			return "", noop, err
+		}
+
+		path, err = store.TargetArchive(storePath, path)
+		if err != nil {
+			return "", noop, err
+		}
+
+		if info, err = os.Stat(path); err != nil {
+			return "", noop, fmt.Errorf("%w: export not found in store: %w", ErrInvalidInput, err)
+		}
+	}
+
+	if info.IsDir() || !strings.HasSuffix(path, archive.FileExtension) {
+		return path, noop, nil
+	}
+
+	tmp, err := os.MkdirTemp("", "gdbuild-*")
+	if err != nil {
+		return "", noop, err
+	}
+
+	cleanup := func() { _ = os.RemoveAll(tmp) }
+
+	if err := archive.Extract(ctx, path, tmp); err != nil {
+		cleanup()
+
+		return "", noop, err
+	}
+
+	return tmp, cleanup, nil
+}
+
+/* ------------------------ Function: openPackWithKey ------------------------ */
+
+func openPackWithKey(path string, key []byte) (*pck.Pack, error) {
+	p, err := pck.OpenWithKey(path, key)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read pack file: %s: %w", path, err)
+	}
+
+	if p.IsDirectoryEncrypted() && len(key) == 0 {
+		return nil, fmt.Errorf("%w: pack directory is encrypted: %s", pck.ErrMissingKey, path)
+	}
+
+	return p, nil
+}
+
+/* ------------------------- Function: printPackDiff ------------------------- */
+
+func printPackDiff(diff pck.Diff) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+
+	fmt.Fprintln(w, "STATUS\tDELTA\tOLD\tNEW\tPATH")
+
+	for _, c := range diff.Changes {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", c.Status, formatDelta(c.Delta), c.SizeOld, c.SizeNew, c.Path)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "ADDED\tCHANGED\tREMOVED\tDELTA\tOLD\tNEW\tDIRECTORY")
+
+	for _, t := range append(diff.Directories, diff.Total) {
+		dir := t.Directory
+		if dir == "" {
+			dir = "(total)"
+		}
+
+		fmt.Fprintf(
+			w,
+			"%d\t%d\t%d\t%s\t%d\t%d\t%s\n",
+			t.Added,
+			t.Changed,
+			t.Removed,
+			formatDelta(t.Delta),
+			t.SizeOld,
+			t.SizeNew,
+			dir,
+		)
+	}
+
+	return w.Flush()
+}
+
+/* -------------------------- Function: formatDelta -------------------------- */
+
+func formatDelta(n int64) string {
+	if n > 0 {
+		return "+" + strconv.FormatInt(n, 10)
+	}
+
+	return strconv.FormatInt(n, 10)
+}
+
+/* --------------------------- Function: printJSON --------------------------- */
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/coffeebeats/gdbuild/pkg/delta"
+)
+
+// A 'urfave/cli' command to generate binary delta updates between two builds.
+func NewPatch() *cli.Command { //nolint:funlen
+	return &cli.Command{
+		Name:     "patch",
+		Category: "Build",
+
+		Usage:     "generate binary patches which update the exported build at 'OLD' to the build at 'NEW'",
+		UsageText: "gdbuild patch [OPTIONS] <OLD> <NEW>",
+
+		Flags: []cli.Flag{
+			newVerboseFlag(),
+
+			&cli.PathFlag{
+				Name:    "out",
+				Aliases: []string{"o"},
+				Value:   ".",
+				Usage:   "write the patch index and patches to 'PATH'",
+			},
+		},
+
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() < 2 { //nolint:gomnd
+				return UsageError{ctx: c, err: fmt.Errorf("%w: expected 'OLD' and 'NEW'", ErrMissingInput)}
+			}
+
+			if c.Args().Len() > 2 { //nolint:gomnd
+				return UsageError{
+					ctx: c,
+					err: fmt.Errorf("%w: %s", ErrTooManyArguments, strings.Join(c.Args().Slice()[2:], " "))}
+			}
+
+			pathOld, cleanupOld, err := resolveBuildDir(c, c.Args().Get(0))
+			if err != nil {
+				return err
+			}
+
+			defer cleanupOld()
+
+			pathNew, cleanupNew, err := resolveBuildDir(c, c.Args().Get(1))
+			if err != nil {
+				return err
+			}
+
+			defer cleanupNew()
+
+			pathOut := c.Path("out")
+
+			idx, err := delta.Create(c.Context, pathOld, pathNew, pathOut)
+			if err != nil {
+				return err
+			}
+
+			// Verify the patches by applying them to the old build.
+			tmp, err := os.MkdirTemp("", "gdbuild-patch-*")
+			if err != nil {
+				return err
+			}
+
+			defer os.RemoveAll(tmp)
+
+			if err := idx.Apply(c.Context, pathOld, pathOut, tmp); err != nil {
+				return fmt.Errorf("failed to verify patches: %w", err)
+			}
+
+			counts := make(map[string]int)
+			for _, e := range idx.Files {
+				counts[e.Action]++
+			}
+
+			log.Infof(
+				"wrote verified patches to: %s (patched: %d, replaced: %d, added: %d, removed: %d, unchanged: %d)",
+				pathOut,
+				counts[delta.ActionPatch],
+				counts[delta.ActionReplace],
+				counts[delta.ActionAdd],
+				counts[delta.ActionRemove],
+				counts[delta.ActionKeep],
+			)
+
+			return nil
+		},
+	}
+}
+
+/* ------------------------- Function: resolveBuildDir ----------------------- */
+
+// resolveBuildDir resolves the build at 'path' (see 'resolveBuild'), which must
+// be a directory of exported artifacts.
+func resolveBuildDir(c *cli.Context, path string) (string, func(), error) {
+	out, cleanup, err := resolveBuild(c.Context, path)
+	if err != nil {
+		return "", cleanup, err
+	}
+
+	info, err := os.Stat(out)
+	if err != nil {
+		cleanup()
+
+		return "", func() {}, err
+	}
+
+	if !info.IsDir() {
+		cleanup()
+
+		return "", func() {}, UsageError{ctx: c, err: fmt.Errorf("%w: expected a directory: %s", ErrInvalidInput, path)}
+	}
+
+	return out, cleanup, nil
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+)
+
+const serveShutdownTimeout = 5 * time.Second
+
+// A 'urfave/cli' command to export a web target and serve it locally.
+func NewServe() *cli.Command { //nolint:cyclop,funlen
+	return &cli.Command{
+		Name:     "serve",
+		Category: "Build",
+
+		Usage:     "export the specified web 'TARGET' and serve it locally with cross-origin isolation headers",
+		UsageText: "gdbuild serve [OPTIONS] <TARGET>",
+
+		Flags: []cli.Flag{
+			newVerboseFlag(),
+
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "export the target even if it was cached in the store (does not rebuild the export template)",
+			},
+			&cli.PathFlag{
+				Name:    "config",
+				Aliases: []string{"c"},
+				Usage:   "use the 'gdbuild' configuration file found at 'PATH'",
+			},
+			&cli.PathFlag{
+				Name:  "project",
+				Usage: "use the Godot project found at 'PATH'",
+			},
+			&cli.PathFlag{
+				Name:    "out",
+				Aliases: []string{"o"},
+				Usage:   "write generated artifacts to 'PATH' (defaults to a temporary directory)",
+			},
+			&cli.PathFlag{
+				Name:  "template-archive",
+				Usage: "extract the template from the archive found at 'PATH' (skips template build)",
+			},
+			&cli.StringFlag{
+				Name:  "host",
+				Value: "localhost",
+				Usage: "listen on the specified 'HOST'",
+			},
+			&cli.UintFlag{
+				Name:  "port",
+				Value: 8060, //nolint:gomnd
+				Usage: "listen on the specified 'PORT'",
+			},
+			&cli.StringSliceFlag{
+				Name:     "feature",
+				Aliases:  []string{"f"},
+				Category: "Export",
+				Usage:    "enable the provided feature tag 'FEATURE' (can be specified more than once)",
+			},
+			&cli.BoolFlag{
+				Name:     "release",
+				Category: "Profile",
+				Usage:    "use a release export template (cannot be used with '--release_debug' or '--debug')",
+			},
+			&cli.BoolFlag{
+				Name:     "release_debug",
+				Category: "Profile",
+				Usage:    "use a release export template with debug symbols (cannot be used with '--release' or '--debug')",
+			},
+			&cli.BoolFlag{
+				Name:     "debug",
+				Category: "Profile",
+				Usage:    "use a debug export template (cannot be used with '--release' or '--release_debug')",
+			},
+		},
+
+		Action: func(c *cli.Context) error {
+			// Validate arguments.
+			targetName := c.Args().First()
+			if targetName == "" {
+				return UsageError{ctx: c, err: fmt.Errorf("%w: target", ErrMissingInput)}
+			}
+
+			if c.Args().Len() > 1 {
+				return UsageError{
+					ctx: c,
+					err: fmt.Errorf("%w: %s", ErrTooManyArguments, strings.Join(c.Args().Slice()[1:], " "))}
+			}
+
+			// Validate flag options.
+			if c.IsSet("release") && c.IsSet("release_debug") {
+				return UsageError{ctx: c, err: ErrTargetUsageProfiles}
+			}
+
+			// Determine output path.
+			pathOut := c.Path("out")
+			if pathOut == "" {
+				tmp, err := os.MkdirTemp("", "gdbuild-serve-*")
+				if err != nil {
+					return err
+				}
+
+				defer os.RemoveAll(tmp)
+
+				pathOut = tmp
+			}
+
+			pathOut, err := parseOutDir(pathOut, false)
+			if err != nil {
+				return err
+			}
+
+			exportAction, cleanup, err := newExportTargetAction(
+				c,
+				targetName,
+				platform.OSWeb.String(),
+				pathOut,
+			)
+
+			defer cleanup()
+
+			if err != nil {
+				return err
+			}
+
+			if err := exportAction.Run(c.Context); err != nil {
+				return err
+			}
+
+			address := net.JoinHostPort(c.String("host"), strconv.FormatUint(uint64(c.Uint("port")), 10))
+
+			return serve(c.Context, address, pathOut, targetName)
+		},
+	}
+}
+
+/* ---------------------------- Function: serve ----------------------------- */
+
+// serve hosts the contents of the directory 'root' at 'address' until the
+// provided context is cancelled.
+func serve(ctx context.Context, address, root, targetName string) error {
+	server := &http.Server{ //nolint:exhaustruct
+		Addr:              address,
+		Handler:           newCrossOriginIsolationHandler(root),
+		ReadHeaderTimeout: serveShutdownTimeout,
+	}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+
+	url := "http://" + address + "/"
+	if _, err := os.Stat(filepath.Join(root, targetName+".html")); err == nil {
+		url += targetName + ".html"
+	}
+
+	log.Infof("serving exported target at: %s", url)
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Info("shutting down server")
+
+	ctx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		return err
+	}
+
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+/* --------------- Function: newCrossOriginIsolationHandler --------------- */
+
+// newCrossOriginIsolationHandler creates an 'http.Handler' which serves files
+// from 'root' with the headers required by threaded web exports. Precompressed
+// files ('.br' or '.gz') are served instead of the originals when supported by
+// the client.
+func newCrossOriginIsolationHandler(root string) http.Handler {
+	files := http.FileServer(http.Dir(root))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cross-Origin-Opener-Policy", "same-origin")
+		w.Header().Set("Cross-Origin-Embedder-Policy", "require-corp")
+		w.Header().Set("Cache-Control", "no-store")
+
+		name := path.Clean("/" + r.URL.Path)
+		accepted := r.Header.Get("Accept-Encoding")
+
+		for _, encoding := range []struct{ name, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+			if !strings.Contains(accepted, encoding.name) {
+				continue
+			}
+
+			f, err := os.Open(filepath.Join(root, filepath.FromSlash(name)+encoding.ext))
+			if err != nil {
+				continue
+			}
+
+			defer f.Close()
+
+			info, err := f.Stat()
+			if err != nil || info.IsDir() {
+				continue
+			}
+
+			if contentType := mime.TypeByExtension(path.Ext(name)); contentType != "" {
+				w.Header().Set("Content-Type", contentType)
+			}
+
+			w.Header().Set("Content-Encoding", encoding.name)
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			http.ServeContent(w, r, name, info.ModTime(), f)
+
+			return
+		}
+
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v2"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/config"
+	"github.com/coffeebeats/gdbuild/pkg/distribution/steam"
+)
+
+// A 'urfave/cli' command to assemble Steam depot contents and build scripts.
+func NewSteam() *cli.Command { //nolint:funlen
+	return &cli.Command{
+		Name:     "steam",
+		Category: "Distribute",
+
+		Usage:     "export the target of each Steam depot and generate 'steamcmd' build scripts",
+		UsageText: "gdbuild steam [OPTIONS]",
+
+		Flags: []cli.Flag{
+			newVerboseFlag(),
+
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "log the build commands without running them",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "export the targets even if they were cached in the store (does not rebuild export templates)",
+			},
+			&cli.PathFlag{
+				Name:    "config",
+				Aliases: []string{"c"},
+				Usage:   "use the 'gdbuild' configuration file found at 'PATH'",
+			},
+			&cli.PathFlag{
+				Name:  "project",
+				Usage: "use the Godot project found at 'PATH'",
+			},
+			&cli.PathFlag{
+				Name:    "out",
+				Aliases: []string{"o"},
+				Value:   ".",
+				Usage:   "write depot contents and build scripts to 'PATH'",
+			},
+			&cli.StringSliceFlag{
+				Name:     "feature",
+				Aliases:  []string{"f"},
+				Category: "Export",
+				Usage:    "enable the provided feature tag 'FEATURE' (can be specified more than once)",
+			},
+			&cli.BoolFlag{
+				Name:     "release",
+				Category: "Profile",
+				Usage:    "use a release export template (cannot be used with '--release_debug' or '--debug')",
+			},
+			&cli.BoolFlag{
+				Name:     "release_debug",
+				Category: "Profile",
+				Usage:    "use a release export template with debug symbols (cannot be used with '--release' or '--debug')",
+			},
+			&cli.BoolFlag{
+				Name:     "debug",
+				Category: "Profile",
+				Usage:    "use a debug export template (cannot be used with '--release' or '--release_debug')",
+			},
+		},
+
+		Action: func(c *cli.Context) error {
+			// Validate arguments.
+			if c.Args().Len() > 0 {
+				return UsageError{
+					ctx: c,
+					err: fmt.Errorf("%w: %s", ErrTooManyArguments, strings.Join(c.Args().Slice(), " "))}
+			}
+
+			// Validate flag options.
+			if c.IsSet("release") && c.IsSet("release_debug") {
+				return UsageError{ctx: c, err: ErrTargetUsageProfiles}
+			}
+
+			dryRun := c.Bool("dry-run")
+
+			// Determine output path.
+			pathOut, err := parseOutDir(c.Path("out"), dryRun)
+			if err != nil {
+				return err
+			}
+
+			pathManifest, _, err := parseConfigAndProjectPaths(c)
+			if err != nil {
+				return err
+			}
+
+			m, err := config.ParseFile(pathManifest)
+			if err != nil {
+				return err
+			}
+
+			rc, err := buildTemplateContext(c, m, pathManifest, "", "", dryRun, false)
+
+			defer cleanTemporaryDirectory(&rc)
+
+			if err != nil {
+				return err
+			}
+
+			cfg, err := config.Steam(&rc, m)
+			if err != nil {
+				return err
+			}
+
+			steamAction, cleanup, err := newSteamAction(c, cfg, pathOut)
+
+			defer cleanup()
+
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				log.Print(steamAction.Sprint())
+
+				return nil
+			}
+
+			return steamAction.Run(c.Context)
+		},
+	}
+}
+
+/* ------------------------- Function: newSteamAction ------------------------ */
+
+// newSteamAction constructs the action which exports the target of each depot
+// into its content root within 'pathOut' and then writes the build scripts.
+func newSteamAction( //nolint:ireturn
+	c *cli.Context,
+	cfg *steam.Config,
+	pathOut string,
+) (action.Action, func(), error) {
+	var cleanups []func()
+
+	cleanup := func() {
+		for _, fn := range cleanups {
+			fn()
+		}
+	}
+
+	depots := slices.Clone(cfg.Depots)
+	slices.SortFunc(depots, func(a, b steam.Depot) int {
+		return cmp.Compare(a.DepotID, b.DepotID)
+	})
+
+	actions := make([]action.Action, 0, 2*len(depots)+1) //nolint:gomnd
+
+	for _, d := range depots {
+		pathContent := filepath.Join(pathOut, d.ContentRoot())
+
+		log.Infof("exporting target '%s' for depot: %s", d.Target, d.ID())
+
+		exportAction, fn, err := newExportTargetAction(c, d.Target, d.Platform, pathContent)
+
+		cleanups = append(cleanups, fn)
+
+		if err != nil {
+			return nil, cleanup, err
+		}
+
+		actions = append(
+			actions,
+			newCleanDirAction(pathContent),
+			exportAction,
+		)
+	}
+
+	fn := func(_ context.Context) error {
+		return cfg.WriteScripts(pathOut)
+	}
+
+	actions = append(actions, action.WithDescription[action.Function]{
+		Action:      fn,
+		Description: "write 'steamcmd' build scripts to path: " + filepath.Join(pathOut, steam.DirScripts),
+	})
+
+	return action.InOrder(actions...), cleanup, nil
+}
+
+/* ------------------------ Function: newCleanDirAction ---------------------- */
+
+// newCleanDirAction creates an action which replaces the directory at 'path'
+// with an empty one so that stale files aren't distributed.
+func newCleanDirAction(path string) action.WithDescription[action.Function] {
+	fn := func(_ context.Context) error {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+
+		return os.MkdirAll(path, osutil.ModeUserRWXGroupRX)
+	}
+
+	return action.WithDescription[action.Function]{
+		Action:      fn,
+		Description: "clean directory: " + path,
+	}
+}
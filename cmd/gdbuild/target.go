@@ -2,8 +2,6 @@ package main
 
 import (
 	"context"
-	"crypto/sha512"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
@@ -17,7 +15,9 @@ import (
 	"github.com/coffeebeats/gdbuild/internal/archive"
 	"github.com/coffeebeats/gdbuild/internal/osutil"
 	"github.com/coffeebeats/gdbuild/pkg/config"
+	"github.com/coffeebeats/gdbuild/pkg/godot/encryption"
 	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+	"github.com/coffeebeats/gdbuild/pkg/godot/project"
 	"github.com/coffeebeats/gdbuild/pkg/godot/template"
 	"github.com/coffeebeats/gdbuild/pkg/run"
 	"github.com/coffeebeats/gdbuild/pkg/store"
@@ -27,7 +27,7 @@ import (
 var ErrTargetUsageProfiles = errors.New("cannot specify more than one of '--debug', '--release_debug', and '--release'")
 
 // A 'urfave/cli' command to compile and export a Godot project target.
-func NewTarget() *cli.Command { //nolint:cyclop,funlen,gocognit,gocyclo,maintidx
+func NewTarget() *cli.Command { //nolint:funlen
 	return &cli.Command{
 		Name:     "target",
 		Category: "Build",
@@ -132,149 +132,194 @@ func NewTarget() *cli.Command { //nolint:cyclop,funlen,gocognit,gocyclo,maintidx
 			}
 
 			dryRun := c.Bool("dry-run")
-			force := c.Bool("force")
-			printHash := c.Bool("print-hash")
-			hasTemplateArchive := c.IsSet("template-archive")
 
-			// Determine path to store.
-			storePath, err := touchStore()
+			// Determine output path.
+			pathOut, err := parseOutDir(c.Path("out"), dryRun)
 			if err != nil {
 				return err
 			}
 
-			log.Debugf("using store at path: %s", storePath)
+			exportAction, cleanup, err := newExportTargetAction(c, targetName, c.String("platform"), pathOut)
 
-			entries, err := store.ListTemplates(storePath)
-			if err == nil {
-				for _, entry := range entries {
-					log.Debugf("found template in store: %s", entry)
-				}
-			}
-
-			entries, err = store.ListExports(storePath)
-			if err == nil {
-				for _, entry := range entries {
-					log.Debugf("found target export in store: %s", entry)
-				}
-			}
+			defer cleanup()
 
-			// Determine output path.
-			pathOut, err := parseOutDir(c.Path("out"), dryRun)
 			if err != nil {
 				return err
 			}
 
-			pathConfig := c.Path("config")
-			pathProject := c.Path("project")
-
-			switch {
-			case pathConfig == "" && pathProject != "":
-				pathConfig = filepath.Join(pathProject, config.DefaultFilename())
-			case pathProject == "" && pathConfig != "":
-				pathProject = filepath.Dir(pathConfig)
-			case pathProject == "" && pathConfig == "":
-				pathProject = "."
-				pathConfig = config.DefaultFilename()
-			}
+			if dryRun {
+				log.Print(exportAction.Sprint())
 
-			// Parse manifest.
-			pathManifest, err := parseManifestPath(pathConfig)
-			if err != nil {
-				return err
+				return nil
 			}
 
-			m, err := config.ParseFile(pathManifest)
-			if err != nil {
-				return err
-			}
+			return exportAction.Run(c.Context)
+		},
+	}
+}
 
-			// Evaluate build context.
-			rc, err := buildTemplateContext(c, pathManifest, "", c.String("platform"), dryRun, printHash)
-			if err != nil {
-				return err
-			}
+/* -------------------- Function: newExportTargetAction -------------------- */
+
+// newExportTargetAction constructs the action which builds the required export
+// template and then exports the target 'targetName' to 'pathOut'. The returned
+// cleanup function must be called once the action is no longer needed, even
+// if an error is returned.
+func newExportTargetAction( //nolint:cyclop,funlen,ireturn
+	c *cli.Context,
+	targetName string,
+	platformInput string,
+	pathOut string,
+) (action.Action, func(), error) {
+	var contexts []*run.Context
+
+	cleanup := func() {
+		for _, rc := range contexts {
+			cleanTemporaryDirectory(rc)
+		}
+	}
 
-			defer cleanTemporaryDirectory(&rc)
+	dryRun := c.Bool("dry-run")
+	force := c.Bool("force")
+	printHash := c.Bool("print-hash")
+	hasTemplateArchive := c.IsSet("template-archive")
 
-			tl, err := config.Template(&rc, m)
-			if err != nil {
-				return err
-			}
+	// Determine path to store.
+	storePath, err := touchStore()
+	if err != nil {
+		return nil, cleanup, err
+	}
 
-			ec, err := buildExportContext(rc, targetName, pathProject, pathOut)
-			if err != nil {
-				return err
-			}
+	log.Debugf("using store at path: %s", storePath)
 
-			defer cleanTemporaryDirectory(&ec)
+	entries, err := store.ListTemplates(storePath)
+	if err == nil {
+		for _, entry := range entries {
+			log.Debugf("found template in store: %s", entry)
+		}
+	}
 
-			xp, err := config.Export(&ec, m, tl, targetName)
-			if err != nil {
-				return err
-			}
+	entries, err = store.ListExports(storePath)
+	if err == nil {
+		for _, entry := range entries {
+			log.Debugf("found target export in store: %s", entry)
+		}
+	}
 
-			pathTemplateArchive, err := templateArchivePath(c, storePath, tl)
-			if err != nil {
-				return err
-			}
+	pathManifest, pathProject, err := parseConfigAndProjectPaths(c)
+	if err != nil {
+		return nil, cleanup, err
+	}
 
-			if hasTemplateArchive {
-				xp.PathTemplateArchive = pathTemplateArchive
-			}
+	m, err := config.ParseFile(pathManifest)
+	if err != nil {
+		return nil, cleanup, err
+	}
 
-			if printHash {
-				return printTargetHash(&ec, xp)
-			}
+	// Evaluate build context.
+	rc, err := buildTemplateContext(c, m, pathManifest, "", platformInput, dryRun, printHash)
+	if err != nil {
+		return nil, cleanup, err
+	}
 
-			templateAction := action.Action(action.NoOp{})
-
-			if !hasTemplateArchive {
-				action, err := exportTemplate(
-					c.Context,
-					&rc,
-					storePath,
-					tl,
-					/* force= */ false,
-				)
-				if err != nil {
-					return err
-				}
+	contexts = append(contexts, &rc)
 
-				templateAction = action
-			}
+	tl, err := config.Template(&rc, m)
+	if err != nil {
+		return nil, cleanup, err
+	}
 
-			exportAction, err := exportProject(
-				c.Context,
-				&ec,
-				storePath,
-				tl,
-				xp,
-				force,
-			)
-			if err != nil {
-				return err
-			}
+	ec, err := buildExportContext(rc, targetName, pathProject, pathOut)
+	if err != nil {
+		return nil, cleanup, err
+	}
 
-			extractTemplateAction, err := target.NewExtractTemplateAction(&ec, pathTemplateArchive)
-			if err != nil {
-				return err
-			}
+	contexts = append(contexts, &ec)
 
-			exportAction = action.InOrder(
-				templateAction,
-				extractTemplateAction,
-				exportAction,
-			)
+	xp, err := config.Export(c.Context, &ec, m, tl, targetName)
+	if err != nil {
+		return nil, cleanup, err
+	}
 
-			if dryRun {
-				log.Print(exportAction.Sprint())
+	pathTemplateArchive, err := templateArchivePath(c, storePath, tl)
+	if err != nil {
+		return nil, cleanup, err
+	}
 
-				return nil
-			}
+	if hasTemplateArchive {
+		xp.PathTemplateArchive = pathTemplateArchive
+	}
 
-			return exportAction.Run(c.Context)
-		},
+	if printHash {
+		return action.NoOp{}, cleanup, printTargetHash(&ec, xp)
 	}
+
+	templateAction := action.Action(action.NoOp{})
+
+	if !hasTemplateArchive {
+		action, err := exportTemplate(
+			c.Context,
+			&rc,
+			storePath,
+			tl,
+			/* force= */ false,
+		)
+		if err != nil {
+			return nil, cleanup, err
+		}
+
+		templateAction = action
+	}
+
+	exportAction, err := exportProject(
+		c.Context,
+		&ec,
+		storePath,
+		tl,
+		xp,
+		force,
+	)
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	extractTemplateAction, err := target.NewExtractTemplateAction(&ec, pathTemplateArchive)
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	return action.InOrder(
+		templateAction,
+		extractTemplateAction,
+		exportAction,
+	), cleanup, nil
+}
+
+/* ------------------- Function: parseConfigAndProjectPaths ------------------ */
+
+// parseConfigAndProjectPaths determines the path to the GDBuild manifest and
+// the Godot project directory from the '--config' and '--project' flags, using
+// one to infer the other if only one is set.
+func parseConfigAndProjectPaths(c *cli.Context) (string, string, error) {
+	pathConfig := c.Path("config")
+	pathProject := c.Path("project")
+
+	switch {
+	case pathConfig == "" && pathProject != "":
+		pathConfig = filepath.Join(pathProject, config.DefaultFilename())
+	case pathProject == "" && pathConfig != "":
+		pathProject = filepath.Dir(pathConfig)
+	case pathProject == "" && pathConfig == "":
+		pathProject = "."
+		pathConfig = config.DefaultFilename()
+	}
+
+	// Parse manifest.
+	pathManifest, err := parseManifestPath(pathConfig)
+	if err != nil {
+		return "", "", err
+	}
+
+	return pathManifest, pathProject, nil
 }
 
 /* ---------------------- Function: buildExportContext ---------------------- */
@@ -326,6 +371,22 @@ func buildExportContext(rc run.Context, targetName, pathProject, pathOut string)
 		)
 	}
 
+	p, err := project.Load(rc.PathWorkspace.String())
+	if err != nil {
+		return run.Context{}, fmt.Errorf("cannot parse Godot project: %w", err)
+	}
+
+	rc.Project = p
+
+	// Expose the project's settings for interpolation within the manifest and
+	// hook commands.
+	for _, env := range p.Environment() {
+		key, value, _ := strings.Cut(env, "=")
+		if err := os.Setenv(key, value); err != nil {
+			return run.Context{}, err
+		}
+	}
+
 	return rc, nil
 }
 
@@ -347,11 +408,9 @@ func exportProject( //nolint:funlen,ireturn
 	log.Infof("computed checksum for target export: %s", cs)
 
 	if xp.EncryptionKey != "" {
-		sum := sha512.Sum512_224([]byte(xp.EncryptionKey))
-
 		log.Infof(
 			"exporting target with encryption: %s (SHA-512/224 sum)",
-			hex.EncodeToString(sum[:]),
+			encryption.Fingerprint(xp.EncryptionKey),
 		)
 	}
 
@@ -2,8 +2,6 @@ package main
 
 import (
 	"context"
-	"crypto/sha512"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -18,6 +16,7 @@ import (
 	"github.com/coffeebeats/gdbuild/internal/archive"
 	"github.com/coffeebeats/gdbuild/internal/osutil"
 	"github.com/coffeebeats/gdbuild/pkg/config"
+	"github.com/coffeebeats/gdbuild/pkg/godot/encryption"
 	godottemplate "github.com/coffeebeats/gdbuild/pkg/godot/template"
 	"github.com/coffeebeats/gdbuild/pkg/run"
 	"github.com/coffeebeats/gdbuild/pkg/store"
@@ -178,7 +177,7 @@ func NewTemplate() *cli.Command { //nolint:cyclop,funlen,gocognit,gocyclo
 			}
 
 			// Evaluate build context.
-			rc, err := buildTemplateContext(c, pathManifest, pathOut, platformInput, dryRun, printHash)
+			rc, err := buildTemplateContext(c, m, pathManifest, pathOut, platformInput, dryRun, printHash)
 			if err != nil {
 				return err
 			}
@@ -220,6 +219,7 @@ func NewTemplate() *cli.Command { //nolint:cyclop,funlen,gocognit,gocyclo
 
 func buildTemplateContext(
 	c *cli.Context,
+	m *config.Manifest,
 	pathManifest,
 	pathOut,
 	platformInput string,
@@ -265,6 +265,16 @@ func buildTemplateContext(
 		return run.Context{}, err
 	}
 
+	ks, err := config.Encryption(&rc, m)
+	if err != nil {
+		return run.Context{}, err
+	}
+
+	rc.EncryptionKey, err = ks.Key(c.Context)
+	if err != nil {
+		return run.Context{}, err
+	}
+
 	return rc, nil
 }
 
@@ -298,11 +308,9 @@ func exportTemplate( //nolint:funlen,ireturn
 	}
 
 	if encryptionKey != "" {
-		sum := sha512.Sum512_224([]byte(encryptionKey))
-
 		log.Infof(
 			"compiling export template with encryption: %s (SHA-512/224 sum)",
-			hex.EncodeToString(sum[:]),
+			encryption.Fingerprint(encryptionKey),
 		)
 	}
 
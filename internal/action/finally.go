@@ -0,0 +1,99 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                               Struct: Finally                              */
+/* -------------------------------------------------------------------------- */
+
+// Finally is an action which always executes a cleanup action after the wrapped
+// action, even if the wrapped action fails or its context is canceled.
+type Finally struct {
+	Action  Action
+	Cleanup Action
+}
+
+// Compile-time check that 'Action' is implemented.
+var _ Action = (*Finally)(nil)
+
+/* ------------------------------ Impl: Runner ------------------------------ */
+
+// Run executes the wrapped action and then the cleanup action. The cleanup
+// action is run with a context that isn't canceled when 'ctx' is, so that an
+// interrupt can't prevent it from completing.
+func (f Finally) Run(ctx context.Context) error {
+	var err error
+
+	if f.Action != nil {
+		err = f.Action.Run(ctx)
+	}
+
+	if f.Cleanup != nil {
+		err = errors.Join(err, f.Cleanup.Run(context.WithoutCancel(ctx)))
+	}
+
+	return err
+}
+
+/* -------------------------- Interface: Combinable ------------------------- */
+
+// After creates a new action which executes the provided action and then the
+// wrapped action (followed by its cleanup).
+func (f Finally) After(a Action) Action { //nolint:ireturn
+	if a == nil {
+		return f
+	}
+
+	return Sequence{Action: f, Pre: a} //nolint:exhaustruct
+}
+
+// AndThen creates a new action which executes the wrapped action (followed by
+// its cleanup) and then the provided action.
+func (f Finally) AndThen(a Action) Action { //nolint:ireturn
+	if a == nil {
+		return f
+	}
+
+	return Sequence{Action: f, Post: a} //nolint:exhaustruct
+}
+
+/* ------------------------------ Impl: Printer ----------------------------- */
+
+// Sprint displays the action without actually executing it.
+func (f Finally) Sprint() string {
+	cmds := make([]string, 0, 2) //nolint:gomnd
+
+	for _, a := range []Action{f.Action, f.Cleanup} {
+		if a == nil {
+			continue
+		}
+
+		if text := a.Sprint(); text != "" {
+			cmds = append(cmds, text)
+		}
+	}
+
+	return strings.Join(cmds, "\n")
+}
+
+/* --------------------------- Impl: fmt.Stringer --------------------------- */
+
+func (f Finally) String() string {
+	cmds := make([]string, 0, 2) //nolint:gomnd
+
+	for _, a := range []Action{f.Action, f.Cleanup} {
+		if a == nil {
+			continue
+		}
+
+		if text := a.String(); text != "" {
+			cmds = append(cmds, text)
+		}
+	}
+
+	return strings.Join(cmds, "\n")
+}
@@ -47,6 +47,14 @@ type Merger interface {
 	MergeInto(other any) error
 }
 
+/* --------------------------- Interface: Replacer -------------------------- */
+
+// Replacer is a type whose values replace, rather than merge into, existing
+// values when they're set (e.g. a choice between mutually exclusive options).
+type Replacer interface {
+	IsSet() bool
+}
+
 /* -------------------------------------------------------------------------- */
 /*                               Function: Merge                              */
 /* -------------------------------------------------------------------------- */
@@ -93,6 +101,17 @@ func (ct configTransformer) Transformer(ty reflect.Type) func(dst, src reflect.V
 		}
 	}
 
+	// Handle types which replace existing values when set.
+	if ty.Implements(reflect.TypeOf((*Replacer)(nil)).Elem()) {
+		return func(dst, src reflect.Value) error {
+			if r, ok := src.Interface().(Replacer); ok && dst.CanSet() && r.IsSet() {
+				dst.Set(src)
+			}
+
+			return nil
+		}
+	}
+
 	return nil
 }
 
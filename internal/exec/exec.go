@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
 var ErrMissingInput = errors.New("missing input")
@@ -24,6 +27,11 @@ type Process struct {
 
 	Verbose bool
 
+	// GracePeriod, if set, causes the process to be interrupted, rather than
+	// killed, when its context is done. The process is then killed if it's
+	// still running after the grace period elapses.
+	GracePeriod time.Duration
+
 	Args []string
 }
 
@@ -39,6 +47,10 @@ func (p Process) args() ([]string, error) {
 		shell = DefaultShell()
 	}
 
+	if shell == ShellNone {
+		return slices.Clone(p.Args), nil
+	}
+
 	var flag string
 
 	switch shell {
@@ -59,18 +71,51 @@ func (p Process) args() ([]string, error) {
 
 // Run executes the underlying function.
 func (p Process) Run(ctx context.Context) error {
-	args, err := p.args()
+	cmd, err := p.command(ctx)
 	if err != nil {
 		return err
 	}
 
-	if len(args) < 3 { //nolint:gomnd
-		return fmt.Errorf("%w: missing arguments: %s", ErrMissingInput, args)
+	if p.Verbose {
+		cmd.Stderr = os.Stderr
+		cmd.Stdout = os.Stdout
+	}
+
+	return cmd.Run()
+}
+
+/* ----------------------------- Method: Output ----------------------------- */
+
+// Output executes the underlying function and returns its standard output. The
+// process is connected to the terminal's standard input and error so that it
+// can prompt the user, if necessary.
+func (p Process) Output(ctx context.Context) ([]byte, error) {
+	cmd, err := p.command(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	return cmd.Output()
+}
+
+/* ----------------------------- Method: command ---------------------------- */
+
+func (p Process) command(ctx context.Context) (*exec.Cmd, error) {
+	args, err := p.args()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) == 0 || args[0] == "" {
+		return nil, fmt.Errorf("%w: missing arguments: %s", ErrMissingInput, args)
 	}
 
 	program, err := exec.LookPath(args[0])
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	cmd := exec.CommandContext(ctx, program, args[1:]...)
@@ -78,12 +123,20 @@ func (p Process) Run(ctx context.Context) error {
 	cmd.Dir = p.Directory
 	cmd.Env = p.Environment
 
-	if p.Verbose {
-		cmd.Stderr = os.Stderr
-		cmd.Stdout = os.Stdout
+	if p.GracePeriod > 0 {
+		cmd.Cancel = func() error {
+			// NOTE: Interrupts aren't supported on all platforms (e.g. Windows).
+			if err := cmd.Process.Signal(os.Interrupt); err != nil {
+				return cmd.Process.Kill()
+			}
+
+			return nil
+		}
+
+		cmd.WaitDelay = p.GracePeriod
 	}
 
-	return cmd.Run()
+	return cmd, nil
 }
 
 /* --------------------------- Impl: fmt.Stringer --------------------------- */
@@ -94,5 +147,16 @@ func (p Process) String() string {
 		return ""
 	}
 
+	if p.Shell != ShellNone {
+		return strings.Join(args, " ")
+	}
+
+	// NOTE: Quote arguments which would otherwise be ambiguous when displayed.
+	for i, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\n\"'\\") {
+			args[i] = strconv.Quote(a)
+		}
+	}
+
 	return strings.Join(args, " ")
 }
@@ -27,6 +27,11 @@ const (
 	ShellPwsh
 	ShellSh
 	ShellZsh
+
+	// ShellNone runs the program directly, without a shell, so that arguments
+	// are passed through as-is (e.g. paths containing spaces). It can't be
+	// selected by users.
+	ShellNone
 )
 
 /* ----------------------------- Impl: Stringer ----------------------------- */
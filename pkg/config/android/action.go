@@ -0,0 +1,92 @@
+package android
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/exec"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+	"github.com/coffeebeats/gdbuild/pkg/store"
+)
+
+// NOTE: These match the well-known values used by the Android SDK when
+// generating a debug keystore.
+const (
+	debugKeystorePassword = "android"
+	debugKeystoreUser     = "androiddebugkey"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                         Function: DebugKeystorePath                        */
+/* -------------------------------------------------------------------------- */
+
+// DebugKeystorePath returns the path to the debug keystore generated by
+// 'gdbuild'. The keystore is located in the store so that it's reused across
+// exports (and projects) instead of being regenerated.
+func DebugKeystorePath() (osutil.Path, error) {
+	pathStore, err := store.Path()
+	if err != nil {
+		return "", err
+	}
+
+	return osutil.Path(filepath.Join(pathStore, "android", "debug.keystore")), nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                  Function: NewGenerateDebugKeystoreAction                  */
+/* -------------------------------------------------------------------------- */
+
+// NewGenerateDebugKeystoreAction creates an 'action.Action' which generates a
+// debug keystore at the specified path using 'keytool'. If the keystore
+// already exists then this action is a no-op.
+func NewGenerateDebugKeystoreAction(
+	rc *run.Context,
+	path osutil.Path,
+) action.WithDescription[action.Function] {
+	fn := func(ctx context.Context) error {
+		info, err := os.Stat(path.String())
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		if info != nil {
+			return nil
+		}
+
+		if err := os.MkdirAll(path.Dir().String(), osutil.ModeUserRWX); err != nil {
+			return err
+		}
+
+		cmd := exec.Process{
+			Args: []string{
+				"keytool",
+				"-genkeypair",
+				"-v",
+				"-keystore", path.String(),
+				"-storepass", debugKeystorePassword,
+				"-alias", debugKeystoreUser,
+				"-keypass", debugKeystorePassword,
+				"-keyalg", "RSA",
+				"-validity", "9999",
+				"-dname", "CN=Android Debug,O=Android,C=US",
+			},
+
+			// NOTE: Run 'keytool' directly so that the distinguished name and
+			// the keystore path are passed through unchanged.
+			Shell:   exec.ShellNone,
+			Verbose: rc.Verbose,
+		}
+
+		return cmd.Run(ctx)
+	}
+
+	return action.WithDescription[action.Function]{
+		Action:      fn,
+		Description: "generate debug keystore: " + path.String(),
+	}
+}
@@ -0,0 +1,306 @@
+package android
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/config/common"
+	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+	"github.com/coffeebeats/gdbuild/pkg/godot/template"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+const (
+	envKeystoreDebugPassword   = "GODOT_ANDROID_KEYSTORE_DEBUG_PASSWORD"   //nolint:gosec
+	envKeystoreReleasePassword = "GODOT_ANDROID_KEYSTORE_RELEASE_PASSWORD" //nolint:gosec
+)
+
+var (
+	ErrInvalidInput = errors.New("invalid input")
+	ErrMissingInput = errors.New("missing input")
+)
+
+// packageNameRegex matches a valid Android application ID; see
+// https://developer.android.com/build/configure-app-module#set-application-id.
+var packageNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*(\.[a-zA-Z][a-zA-Z0-9_]*)+$`)
+
+/* -------------------------------------------------------------------------- */
+/*                               Struct: Target                               */
+/* -------------------------------------------------------------------------- */
+
+type Target struct {
+	*common.Target
+
+	// Gradle determines whether the project is exported using a custom Gradle
+	// build instead of the prebuilt APK export template. Note that the Android
+	// build template must already be installed in the Godot project.
+	Gradle *bool `toml:"use_gradle_build"`
+	// Keystore defines the keystore used to sign the exported package.
+	Keystore Keystore `toml:"keystore"`
+	// PackageName is the unique application identifier (e.g. 'com.example.game').
+	PackageName string `toml:"package_name"`
+	// Permissions is a list of Android permissions (e.g. 'internet') to request
+	// in the exported application's manifest.
+	Permissions []string `toml:"permissions"`
+	// VersionCode is the internal version number of the exported application.
+	VersionCode *uint `toml:"version_code"`
+	// VersionName is the user-visible version of the exported application.
+	VersionName string `toml:"version_name"`
+}
+
+/* ----------------------------- Impl: Exporter ----------------------------- */
+
+func (t *Target) Collect(rc *run.Context, tl *template.Template, ev engine.Version) *export.Export {
+	out := t.Target.Collect(rc, tl, ev)
+
+	if out.Options == nil {
+		out.Options = map[string]any{}
+	}
+
+	out.Options["package/unique_name"] = t.PackageName
+
+	if t.VersionCode != nil {
+		out.Options["version/code"] = *t.VersionCode
+	}
+
+	if t.VersionName != "" {
+		out.Options["version/name"] = t.VersionName
+	}
+
+	for _, p := range t.Permissions {
+		out.Options["permissions/"+p] = true
+	}
+
+	if config.Dereference(t.Gradle) {
+		out.Options["gradle_build/use_gradle_build"] = true
+	}
+
+	// NOTE: Godot chooses a keystore based on the export command, which is
+	// determined by whether the profile is a release profile.
+	kind := "debug"
+	if rc.Profile.IsRelease() {
+		kind = "release"
+	}
+
+	ks := t.Keystore
+
+	if ks.Path != "" {
+		out.RegisterDependencyPath(ks.Path)
+	}
+
+	if ks.Path == "" && !rc.Profile.IsRelease() {
+		// NOTE: Errors are reported during validation.
+		if path, err := DebugKeystorePath(); err == nil {
+			ks = Keystore{Path: path, User: debugKeystoreUser}
+
+			out.Options["keystore/debug_password"] = debugKeystorePassword
+			out.RunBefore = action.InOrder(
+				NewGenerateDebugKeystoreAction(rc, path),
+				out.RunBefore,
+			)
+		}
+	}
+
+	if out.PathOptions == nil {
+		out.PathOptions = map[string]osutil.Path{}
+	}
+
+	// NOTE: Passwords are deliberately not written to the export presets; the
+	// Godot editor reads them directly from the environment.
+	out.PathOptions["keystore/"+kind] = ks.Path
+	out.Options["keystore/"+kind+"_user"] = ks.User
+
+	return out
+}
+
+/* ------------------------- Impl: config.Configurer ------------------------ */
+
+func (t *Target) Configure(rc *run.Context) error {
+	if err := t.Target.Configure(rc); err != nil {
+		return err
+	}
+
+	if err := t.Keystore.Configure(rc); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (t *Target) Validate(rc *run.Context) error {
+	if err := t.Target.Validate(rc); err != nil {
+		return err
+	}
+
+	if t.PackageName == "" {
+		return fmt.Errorf("%w: 'package_name'", ErrMissingInput)
+	}
+
+	if !packageNameRegex.MatchString(t.PackageName) {
+		return fmt.Errorf(
+			"%w: invalid Android package name: %s",
+			ErrInvalidInput,
+			t.PackageName,
+		)
+	}
+
+	if t.VersionCode != nil && *t.VersionCode == 0 {
+		return fmt.Errorf("%w: 'version_code' must be positive", ErrInvalidInput)
+	}
+
+	if err := t.Keystore.Validate(rc); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/* --------------------------- Impl: config.Merger -------------------------- */
+
+func (t *Target) MergeInto(other any) error {
+	if t == nil || other == nil {
+		return nil
+	}
+
+	dst, ok := other.(*Target)
+	if !ok {
+		return fmt.Errorf(
+			"%w: expected a '%T' but was '%T'",
+			config.ErrInvalidInput,
+			new(Target),
+			other,
+		)
+	}
+
+	return config.Merge(dst, *t)
+}
+
+/* -------------------------------------------------------------------------- */
+/*                              Struct: Keystore                              */
+/* -------------------------------------------------------------------------- */
+
+// Keystore defines the keystore used to sign an exported Android package. The
+// keystore password must be set via the 'GODOT_ANDROID_KEYSTORE_DEBUG_PASSWORD'
+// or 'GODOT_ANDROID_KEYSTORE_RELEASE_PASSWORD' environment variable, depending
+// on the export profile.
+type Keystore struct {
+	// Path is a path to the keystore file. If omitted for a debug export, a
+	// debug keystore will be generated in the 'gdbuild' store.
+	Path osutil.Path `toml:"path"`
+	// User is the alias of the signing key within the keystore.
+	User string `toml:"user"`
+}
+
+/* ------------------------- Impl: config.Configurer ------------------------ */
+
+func (k *Keystore) Configure(rc *run.Context) error {
+	if err := k.Path.RelTo(rc.PathManifest); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (k *Keystore) Validate(rc *run.Context) error {
+	// NOTE: Don't check if the keystore exists since it might be generated by
+	// a hook.
+
+	if k.Path == "" {
+		if rc.Profile.IsRelease() {
+			return fmt.Errorf(
+				"%w: a keystore is required for release exports: 'keystore.path'",
+				ErrMissingInput,
+			)
+		}
+
+		if _, err := DebugKeystorePath(); err != nil {
+			return fmt.Errorf("cannot generate debug keystore: %w", err)
+		}
+
+		return nil
+	}
+
+	if k.User == "" {
+		return fmt.Errorf("%w: 'keystore.user'", ErrMissingInput)
+	}
+
+	env := envKeystoreDebugPassword
+	if rc.Profile.IsRelease() {
+		env = envKeystoreReleasePassword
+	}
+
+	if os.Getenv(env) == "" {
+		return fmt.Errorf(
+			"%w: keystore password must be set via environment variable: %s",
+			ErrMissingInput,
+			env,
+		)
+	}
+
+	return nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                    Struct: TargetWithFeaturesAndProfile                    */
+/* -------------------------------------------------------------------------- */
+
+type TargetWithFeaturesAndProfile struct {
+	*Target
+
+	Feature map[string]TargetWithProfile `toml:"feature"`
+	Profile map[engine.Profile]Target    `toml:"profile"`
+}
+
+/* ------------------------ Struct: TargetWithProfile ----------------------- */
+
+type TargetWithProfile struct {
+	*Target
+
+	Profile map[engine.Profile]Target `toml:"profile"`
+}
+
+/* ---------------------- Impl: platform.targetBuilder ---------------------- */
+
+func (t *TargetWithFeaturesAndProfile) Build(rc *run.Context, dst *Target) error {
+	if t == nil {
+		return nil
+	}
+
+	// Root-level params
+	if err := t.Target.MergeInto(dst); err != nil {
+		return err
+	}
+
+	// Feature-constrained params
+	for _, f := range rc.Features {
+		if err := t.Feature[f].Target.MergeInto(dst); err != nil {
+			return err
+		}
+	}
+
+	// Profile-constrained params
+	l := t.Profile[rc.Profile]
+	if err := l.MergeInto(dst); err != nil {
+		return err
+	}
+
+	// Feature-and-profile-constrained params
+	for _, f := range rc.Features {
+		l := t.Feature[f].Profile[rc.Profile]
+		if err := l.MergeInto(dst); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
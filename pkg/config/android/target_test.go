@@ -0,0 +1,282 @@
+package android_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/config/android"
+	"github.com/coffeebeats/gdbuild/pkg/config/common"
+	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/godot/template"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+func TestTargetValidate(t *testing.T) {
+	tests := []struct {
+		name string
+
+		profile     engine.Profile
+		packageName string
+		versionCode *uint
+		keystore    android.Keystore
+		env         map[string]string
+
+		err error
+	}{
+		{
+			name:        "valid package name with a default debug keystore succeeds",
+			profile:     engine.ProfileDebug,
+			packageName: "com.example.game",
+		},
+		{
+			name:        "package name with underscores, digits, and capitals succeeds",
+			profile:     engine.ProfileDebug,
+			packageName: "Com.Example_2.game_3",
+		},
+		{
+			name:    "missing package name fails",
+			profile: engine.ProfileDebug,
+			err:     android.ErrMissingInput,
+		},
+		{
+			name:        "package name with a single segment fails",
+			profile:     engine.ProfileDebug,
+			packageName: "game",
+			err:         android.ErrInvalidInput,
+		},
+		{
+			name:        "package name with a hyphen fails",
+			profile:     engine.ProfileDebug,
+			packageName: "com.example-studio.game",
+			err:         android.ErrInvalidInput,
+		},
+		{
+			name:        "package name with a segment starting with a digit fails",
+			profile:     engine.ProfileDebug,
+			packageName: "com.1example.game",
+			err:         android.ErrInvalidInput,
+		},
+		{
+			name:        "package name with an empty segment fails",
+			profile:     engine.ProfileDebug,
+			packageName: "com..game",
+			err:         android.ErrInvalidInput,
+		},
+		{
+			name:        "package name with a trailing separator fails",
+			profile:     engine.ProfileDebug,
+			packageName: "com.example.",
+			err:         android.ErrInvalidInput,
+		},
+		{
+			name:        "zero version code fails",
+			profile:     engine.ProfileDebug,
+			packageName: "com.example.game",
+			versionCode: pointer(uint(0)),
+			err:         android.ErrInvalidInput,
+		},
+		{
+			name:        "release export without a keystore fails",
+			profile:     engine.ProfileRelease,
+			packageName: "com.example.game",
+			err:         android.ErrMissingInput,
+		},
+		{
+			name:        "keystore without a user fails",
+			profile:     engine.ProfileDebug,
+			packageName: "com.example.game",
+			keystore:    android.Keystore{Path: "debug.keystore"}, //nolint:exhaustruct
+			env:         map[string]string{"GODOT_ANDROID_KEYSTORE_DEBUG_PASSWORD": "secret"},
+			err:         android.ErrMissingInput,
+		},
+		{
+			name:        "debug keystore with its password set succeeds",
+			profile:     engine.ProfileDebug,
+			packageName: "com.example.game",
+			keystore:    android.Keystore{Path: "debug.keystore", User: "debug"},
+			env:         map[string]string{"GODOT_ANDROID_KEYSTORE_DEBUG_PASSWORD": "secret"},
+		},
+		{
+			name:        "debug keystore without its password set fails",
+			profile:     engine.ProfileDebug,
+			packageName: "com.example.game",
+			keystore:    android.Keystore{Path: "debug.keystore", User: "debug"},
+			env:         map[string]string{"GODOT_ANDROID_KEYSTORE_RELEASE_PASSWORD": "secret"},
+			err:         android.ErrMissingInput,
+		},
+		{
+			name:        "release keystore with its password set succeeds",
+			profile:     engine.ProfileRelease,
+			packageName: "com.example.game",
+			keystore:    android.Keystore{Path: "release.keystore", User: "release"},
+			env:         map[string]string{"GODOT_ANDROID_KEYSTORE_RELEASE_PASSWORD": "secret"},
+		},
+		{
+			name:        "release keystore without its password set fails",
+			profile:     engine.ProfileRelease,
+			packageName: "com.example.game",
+			keystore:    android.Keystore{Path: "release.keystore", User: "release"},
+			env:         map[string]string{"GODOT_ANDROID_KEYSTORE_DEBUG_PASSWORD": "secret"},
+			err:         android.ErrMissingInput,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A store in which to generate a debug keystore.
+			t.Setenv("GDBUILD_HOME", t.TempDir())
+
+			// Given: The keystore passwords are set in the environment.
+			for _, env := range []string{
+				"GODOT_ANDROID_KEYSTORE_DEBUG_PASSWORD",
+				"GODOT_ANDROID_KEYSTORE_RELEASE_PASSWORD",
+			} {
+				t.Setenv(env, tc.env[env])
+			}
+
+			target := android.Target{ //nolint:exhaustruct
+				Target:      &common.Target{}, //nolint:exhaustruct
+				Keystore:    tc.keystore,
+				PackageName: tc.packageName,
+				VersionCode: tc.versionCode,
+			}
+
+			rc := run.Context{Platform: platform.OSAndroid, Profile: tc.profile, Target: "game"} //nolint:exhaustruct
+
+			// When: The target is validated.
+			err := target.Validate(&rc)
+
+			// Then: The resulting error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestTargetCollect(t *testing.T) {
+	tests := []struct {
+		name string
+
+		profile  engine.Profile
+		keystore android.Keystore
+
+		key         string // The keystore path's export option.
+		wantOptions map[string]any
+		wantDebug   bool // Whether a debug keystore is generated.
+	}{
+		{
+			name:    "debug export without a keystore uses a generated debug keystore",
+			profile: engine.ProfileDebug,
+
+			key: "keystore/debug",
+			wantOptions: map[string]any{
+				"keystore/debug_password": "android",
+				"keystore/debug_user":     "androiddebugkey",
+			},
+			wantDebug: true,
+		},
+		{
+			name:     "debug export with a keystore uses the keystore",
+			profile:  engine.ProfileDebug,
+			keystore: android.Keystore{Path: "debug.keystore", User: "debug"},
+
+			key:         "keystore/debug",
+			wantOptions: map[string]any{"keystore/debug_user": "debug"},
+		},
+		{
+			name:     "release export with a keystore uses the keystore",
+			profile:  engine.ProfileRelease,
+			keystore: android.Keystore{Path: "release.keystore", User: "release"},
+
+			key:         "keystore/release",
+			wantOptions: map[string]any{"keystore/release_user": "release"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: The user's keystore, if any, exists.
+			ks := tc.keystore
+			if ks.Path != "" {
+				ks.Path = osutil.Path(filepath.Join(t.TempDir(), ks.Path.String()))
+				require.NoError(t, os.WriteFile(ks.Path.String(), []byte("keystore"), 0o600))
+			}
+
+			target := android.Target{ //nolint:exhaustruct
+				Target:      &common.Target{}, //nolint:exhaustruct
+				Keystore:    ks,
+				PackageName: "com.example.game",
+			}
+
+			rc := run.Context{ //nolint:exhaustruct
+				Platform:     platform.OSAndroid,
+				PathManifest: osutil.Path(filepath.Join(t.TempDir(), "gdbuild.toml")),
+				Profile:      tc.profile,
+				Target:       "game",
+			}
+
+			// collect collects the target using a new store and returns the
+			// export and the expected keystore path.
+			collect := func() (*export.Export, osutil.Path) {
+				pathStore := t.TempDir()
+				t.Setenv("GDBUILD_HOME", pathStore)
+
+				want := ks.Path
+				if want == "" {
+					want = osutil.Path(filepath.Join(pathStore, "android", "debug.keystore"))
+				}
+
+				return target.Collect(&rc, &template.Template{}, engine.Version{}), want //nolint:exhaustruct
+			}
+
+			// When: The target is collected.
+			got, wantPath := collect()
+
+			// Then: The keystore path matches expectations.
+			assert.Equal(t, map[string]osutil.Path{tc.key: wantPath}, got.PathOptions)
+
+			// Then: The keystore path isn't included in the hashed options.
+			assert.NotContains(t, got.Options, tc.key)
+
+			// Then: The keystore options match expectations.
+			for key, want := range tc.wantOptions {
+				assert.Equal(t, want, got.Options[key])
+			}
+
+			// Then: A debug keystore is generated only if required.
+			assert.Equal(t, tc.wantDebug, got.RunBefore != nil)
+
+			// Then: Only the user's keystore is a dependency of the export.
+			if ks.Path != "" {
+				assert.Equal(t, []osutil.Path{ks.Path}, got.Paths)
+			} else {
+				assert.Empty(t, got.Paths)
+			}
+
+			// When: The target is collected again with a different store.
+			again, _ := collect()
+
+			// Then: The export checksum doesn't depend on the store's location.
+			want, err := export.Checksum(&rc, got)
+			require.NoError(t, err)
+
+			cs, err := export.Checksum(&rc, again)
+			require.NoError(t, err)
+
+			assert.Equal(t, want, cs)
+		})
+	}
+}
+
+func pointer[T any](value T) *T {
+	return &value
+}
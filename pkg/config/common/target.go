@@ -1,13 +1,19 @@
 package common
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
+	"slices"
+	"time"
 
 	"github.com/charmbracelet/log"
+	"golang.org/x/exp/maps"
 
+	"github.com/coffeebeats/gdbuild/internal/action"
 	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/pkg/godot/encryption"
 	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
 	"github.com/coffeebeats/gdbuild/pkg/godot/export"
 	"github.com/coffeebeats/gdbuild/pkg/godot/template"
@@ -31,14 +37,23 @@ type Target struct {
 	DefaultFeatures []string `toml:"default_features"`
 	// Encrypt sets whether the exported artifacts will be encrypted or not.
 	Encrypt *bool `toml:"encrypt"`
+	// Encryption overrides the manifest's encryption key source for this
+	// target, allowing targets to be encrypted with different keys.
+	Encryption encryption.KeySource `toml:"encryption"`
 	// Hook defines commands to be run before or after the target artifact is
 	// generated.
 	Hook run.Hook `toml:"hook"`
+	// ImportTimeout is the maximum duration (e.g. '30m') to wait for the Godot
+	// editor to import the project's assets prior to exporting.
+	ImportTimeout string `toml:"import_timeout"`
 	// Options are 'export_presets.cfg' overrides, specifically the preset
 	// 'options' table, for the exported artifact.
 	Options map[string]any `toml:"options"`
 	// PackFiles defines the game files exported as part of this artifact.
 	PackFiles []export.PackFile `toml:"pack_files"`
+	// Patch configures the target to export only the game files which changed
+	// since a baseline build.
+	Patch export.Patch `toml:"patch"`
 	// ExcludeFiles are a list of file globs that specify files to exclude from
 	// this target's exports. These will be applied to *all* pack files.
 	ExcludeFiles []string `toml:"exclude_files"`
@@ -48,6 +63,13 @@ type Target struct {
 	// Server configures the target as a server-only executable, enabling some
 	// optimizations like disabling graphics.
 	Server *bool `toml:"server"`
+	// VerifyPacks sets whether the contents of exported pack files are checked
+	// against the files selected by each pack file's configuration.
+	VerifyPacks *bool `toml:"verify_packs"`
+
+	// patch describes the changes since the baseline build; it's set by
+	// 'PatchPackFiles' when exporting a patch.
+	patch *export.PatchDiff
 }
 
 /* ----------------------------- Impl: Exporter ----------------------------- */
@@ -55,16 +77,20 @@ type Target struct {
 func (t *Target) Collect(rc *run.Context, tl *template.Template, ev engine.Version) *export.Export {
 	// Set the encryption key environment variable; see
 	// https://docs.godotengine.org/en/stable/contributing/development/compiling/compiling_with_script_encryption_key.html.
-	encryptionKey := template.EncryptionKeyFromEnv()
+	encryptionKey := rc.EncryptionKey
 
 	ff := make([]string, 0, len(t.DefaultFeatures)+len(rc.Features))
 	ff = append(ff, t.DefaultFeatures...)
 	ff = append(ff, rc.Features...)
 
-	return &export.Export{
+	// NOTE: The timeout is checked during validation, so ignore the error.
+	importTimeout, _ := time.ParseDuration(t.ImportTimeout)
+
+	out := &export.Export{
 		Arch:                tl.Arch,
 		EncryptionKey:       encryptionKey,
 		Features:            ff,
+		ImportTimeout:       importTimeout,
 		Options:             t.Options,
 		PackFiles:           t.PackFiles,
 		PathTemplate:        "",
@@ -74,8 +100,85 @@ func (t *Target) Collect(rc *run.Context, tl *template.Template, ev engine.Versi
 		Runnable:            config.Dereference(t.Runnable),
 		Server:              config.Dereference(t.Server),
 		Template:            tl,
+		VerifyPacks:         config.Dereference(t.VerifyPacks),
 		Version:             ev,
 	}
+
+	if t.patch != nil {
+		out.Patch = t.patch
+		out.ExtraArtifacts = append(out.ExtraArtifacts, t.patch.ManifestFilenames(rc.Target)...)
+		out.Postexport = action.InOrder(out.Postexport, export.NewWritePatchManifestsAction(rc, t.patch))
+	}
+
+	return out
+}
+
+/* ---------------------- Method: ResolveEncryptionKey ---------------------- */
+
+// ResolveEncryptionKey reads the target's encryption key, if the target has its
+// own key source, and sets it on the run context. This should be called before
+// the target is validated.
+func (t *Target) ResolveEncryptionKey(ctx context.Context, rc *run.Context) error {
+	if !t.Encryption.IsSet() {
+		return nil
+	}
+
+	key, err := t.Encryption.Key(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot read encryption key for target '%s': %w", rc.Target, err)
+	}
+
+	rc.EncryptionKey = key
+
+	return nil
+}
+
+/* ----------------------- Method: PartitionPackFiles ----------------------- */
+
+// PartitionPackFiles expands pack files with partitioning rules into one pack
+// file per partition. This should be called after the target is validated.
+func (t *Target) PartitionPackFiles(rc *run.Context) error {
+	packs, err := export.PartitionPackFiles(rc, t.PackFiles)
+	if err != nil {
+		return err
+	}
+
+	t.PackFiles = packs
+
+	return nil
+}
+
+/* ------------------------- Method: PatchPackFiles ------------------------- */
+
+// PatchPackFiles restricts the pack files to only the game files which changed
+// since the baseline build. This should be called after the target is
+// validated and has no effect if the target doesn't export a patch.
+func (t *Target) PatchPackFiles(rc *run.Context) error {
+	if !t.Patch.IsEnabled() {
+		return nil
+	}
+
+	key, err := encryption.Decode(rc.EncryptionKey)
+	if err != nil {
+		return err
+	}
+
+	packs, diff, err := export.PatchPackFiles(rc, &t.Patch, t.PackFiles, key)
+	if err != nil {
+		return err
+	}
+
+	log.Infof(
+		"exporting patch: %d added, %d changed, %d deleted",
+		len(diff.Added),
+		len(diff.Changed),
+		len(diff.Deleted),
+	)
+
+	t.PackFiles = packs
+	t.patch = &diff
+
+	return nil
 }
 
 /* ------------------------- Impl: config.Configurer ------------------------ */
@@ -84,6 +187,14 @@ func (t *Target) Configure(rc *run.Context) error {
 	hasEncrypt := false
 	isEncrypted := config.Dereference(t.Encrypt)
 
+	if err := t.Encryption.Configure(rc); err != nil {
+		return err
+	}
+
+	if err := t.Patch.Configure(rc); err != nil {
+		return err
+	}
+
 	for i, pf := range t.PackFiles {
 		pf.Exclude = append(pf.Exclude, t.ExcludeFiles...)
 		t.PackFiles[i] = pf
@@ -100,7 +211,7 @@ func (t *Target) Configure(rc *run.Context) error {
 		pf.Encrypt = &encrypt
 	}
 
-	if template.EncryptionKeyFromEnv() != "" && !isEncrypted {
+	if rc.EncryptionKey != "" && !isEncrypted {
 		log.Warn("ignoring encryption key because encryption is disabled.")
 	}
 
@@ -121,6 +232,14 @@ func (t *Target) Validate(rc *run.Context) error { //nolint:cyclop,funlen
 		return err
 	}
 
+	if err := t.Encryption.Validate(rc); err != nil {
+		return err
+	}
+
+	if err := t.Patch.Validate(rc); err != nil {
+		return err
+	}
+
 	hasEmbed := false
 	hasVisualsStripped := false
 	packNames := make(map[string]struct{})
@@ -176,6 +295,13 @@ func (t *Target) Validate(rc *run.Context) error { //nolint:cyclop,funlen
 		}
 	}
 
+	if t.Patch.IsEnabled() && hasEmbed {
+		return fmt.Errorf(
+			"%w: cannot export a patch for a target with an embedded pack file",
+			ErrInvalidInput,
+		)
+	}
+
 	if !isRunnable && hasEmbed {
 		return fmt.Errorf(
 			"%w: cannot embed a pack file into a non-runnable target",
@@ -197,13 +323,33 @@ func (t *Target) Validate(rc *run.Context) error { //nolint:cyclop,funlen
 		)
 	}
 
-	if config.Dereference(t.Encrypt) && template.EncryptionKeyFromEnv() == "" {
+	if config.Dereference(t.Encrypt) && rc.EncryptionKey == "" {
 		return fmt.Errorf(
 			"%w: encryption is enabled but no encryption key is set",
 			ErrInvalidInput,
 		)
 	}
 
+	if t.ImportTimeout != "" {
+		d, err := time.ParseDuration(t.ImportTimeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf(
+				"%w: expected a positive duration for 'import_timeout': %s",
+				ErrInvalidInput,
+				t.ImportTimeout,
+			)
+		}
+	}
+
+	keys := maps.Keys(t.Options)
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if _, err := export.EncodeOption(t.Options[key]); err != nil {
+			return fmt.Errorf("%w: option '%s': %w", ErrInvalidInput, key, err)
+		}
+	}
+
 	return nil
 }
 
@@ -64,7 +64,7 @@ func (t Template) Collect(src engine.Source, rc *run.Context) *template.Template
 				CustomModules:   t.CustomModules,
 				CustomPy:        t.PathCustomPy,
 				DoublePrecision: config.Dereference(t.DoublePrecision),
-				EncryptionKey:   template.EncryptionKeyFromEnv(),
+				EncryptionKey:   rc.EncryptionKey,
 				Env:             t.Env,
 				Source:          src,
 				Optimize:        t.Optimize,
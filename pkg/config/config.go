@@ -1,17 +1,9 @@
 package config
 
 import (
-	"bytes"
-	"errors"
-	"fmt"
-	"io"
-	"io/fs"
-	"os"
-	"path/filepath"
-	"strings"
-
 	"github.com/coffeebeats/gdbuild/internal/config"
 	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/godot/encryption"
 	"github.com/coffeebeats/gdbuild/pkg/run"
 )
 
@@ -28,6 +20,10 @@ var (
 type Manifest struct {
 	// Config contains GDBuild configuration-related settings.
 	Config Config `toml:"config"`
+	// Distribution contains settings for distributing exported targets.
+	Distribution Distribution `toml:"distribution"`
+	// Encryption defines where to read the script encryption key from.
+	Encryption encryption.KeySource `toml:"encryption"`
 	// Godot contains settings on which Godot version/source code to use.
 	Godot Godot `toml:"godot"`
 	// Target includes settings for exporting Godot game executables and packs.
@@ -36,101 +32,6 @@ type Manifest struct {
 	Template Templates `toml:"template"`
 }
 
-/* ----------------------------- Function: Init ----------------------------- */
-
-// Init initializes a GDBuild manifest at the specified path. Note that 'path'
-// can be a directory or a '.toml' file.
-func Init(path string) error { //nolint:cyclop
-	if path == "" {
-		return fmt.Errorf("%w: 'path'", ErrMissingInput)
-	}
-
-	info, err := os.Stat(path)
-	if err != nil {
-		if !errors.Is(err, fs.ErrNotExist) {
-			return err
-		}
-
-		// Assume the path is a directory if it doesn't end with '.toml'.
-		if !strings.HasSuffix(path, ".toml") {
-			if strings.Contains(filepath.Base(path), ".") {
-				return fmt.Errorf(
-					"%w: path must be a directory or a '.toml' file: %s",
-					ErrInvalidInput,
-					path,
-				)
-			}
-
-			path = filepath.Join(path, DefaultFilename())
-		}
-	}
-
-	if info != nil {
-		if !info.IsDir() {
-			return fmt.Errorf("%w: %s", fs.ErrExist, path)
-		}
-
-		path = filepath.Join(path, DefaultFilename())
-	}
-
-	// Check again if the file exists.
-	info, err = os.Stat(path)
-	if err != nil {
-		if !errors.Is(err, fs.ErrNotExist) {
-			return err
-		}
-	}
-
-	if info != nil {
-		return fmt.Errorf("%w: %s", fs.ErrExist, path)
-	}
-
-	f, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-
-	defer f.Close()
-
-	if _, err := io.Copy(f, bytes.NewReader([]byte(defaultContents()))); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-/* ------------------------ Function: defaultContents ----------------------- */
-
-// defaultContents contains the default GDBuild manifest contents.
-func defaultContents() string {
-	return `[config]
-  # Inherit from the specified manifest file, merging the configuration in
-  # this file on top of the settings in the specified file. 
-  extends = ""
-
-[godot]
-  # The version of Godot to use for compiling and exporting.
-  version = "4.2.1-stable"
-
-[target.client]
-  runnable         = true
-  default_features = []
-
-  pack_files = [{include = ["*"], embed = true, encrypt = true}]
-
-[target.client.profile.release]
-  # NOTE: Set the encryption key via the 'SCRIPT_AES256_ENCRYPTION_KEY' variable.
-  encrypt        = true
-
-[template]
-  # A path to a 'custom.py' file which defines export template build options.
-  custom_py_path = "custom.py"
-
-[template.scons]
-  command = ["python3", "-m", "SCons"]
-`
-}
-
 /* -------------------------- Struct: configuration ------------------------- */
 
 type configuration struct {
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/distribution/steam"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                            Struct: Distribution                            */
+/* -------------------------------------------------------------------------- */
+
+// Distribution contains settings for distributing exported targets to
+// storefronts.
+type Distribution struct {
+	// Steam contains settings for distributing targets on Steam.
+	Steam *steam.Config `toml:"steam"`
+}
+
+/* -------------------------------------------------------------------------- */
+/*                               Function: Steam                              */
+/* -------------------------------------------------------------------------- */
+
+// Steam returns the validated Steam distribution settings, merged across all
+// inherited manifests.
+func Steam(rc *run.Context, m *Manifest) (*steam.Config, error) {
+	var out *steam.Config
+
+	toBuild := []configuration{{context: rc, manifest: m}}
+	visited := map[osutil.Path]struct{}{}
+
+	for len(toBuild) > 0 {
+		// Remove the next manifest from the queue.
+		cfg := toBuild[0]
+		toBuild = toBuild[1:]
+
+		// Copy build context so it can be modified.
+		rc := *cfg.context
+
+		// First, determine whether this manifest extends another one.
+
+		if err := cfg.manifest.Config.Extends.RelTo(rc.PathManifest); err != nil {
+			return nil, fmt.Errorf(
+				"%w: cannot find inherited manifest: %w",
+				ErrInvalidInput,
+				err,
+			)
+		}
+
+		extends := cfg.manifest.Config.Extends
+
+		// Skip block below if this manifest has already been "visited".
+		if _, ok := visited[extends]; !ok && extends != "" {
+			baseManifest, err := ParseFile(extends.String())
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse inherited manifest: %w", err)
+			}
+
+			rc.PathManifest = extends
+
+			base := configuration{context: &rc, manifest: baseManifest}
+			toBuild = append(toBuild, base, cfg)
+
+			visited[extends] = struct{}{}
+
+			continue
+		}
+
+		s := cfg.manifest.Distribution.Steam
+		if s == nil {
+			continue
+		}
+
+		if out == nil {
+			out = &steam.Config{} //nolint:exhaustruct
+		}
+
+		if err := s.MergeInto(out); err != nil {
+			return nil, err
+		}
+	}
+
+	if out == nil {
+		return nil, fmt.Errorf("%w: 'distribution.steam'", ErrMissingInput)
+	}
+
+	if err := out.Validate(rc); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
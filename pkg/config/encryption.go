@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/godot/encryption"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                             Function: Encryption                           */
+/* -------------------------------------------------------------------------- */
+
+// Encryption returns the validated encryption key source, merged across all
+// inherited manifests.
+func Encryption(rc *run.Context, m *Manifest) (*encryption.KeySource, error) {
+	var out encryption.KeySource
+
+	toBuild := []configuration{{context: rc, manifest: m}}
+	visited := map[osutil.Path]struct{}{}
+
+	for len(toBuild) > 0 {
+		// Remove the next manifest from the queue.
+		cfg := toBuild[0]
+		toBuild = toBuild[1:]
+
+		// Copy build context so it can be modified.
+		rc := *cfg.context
+
+		// First, determine whether this manifest extends another one.
+
+		if err := cfg.manifest.Config.Extends.RelTo(rc.PathManifest); err != nil {
+			return nil, fmt.Errorf(
+				"%w: cannot find inherited manifest: %w",
+				ErrInvalidInput,
+				err,
+			)
+		}
+
+		extends := cfg.manifest.Config.Extends
+
+		// Skip block below if this manifest has already been "visited".
+		if _, ok := visited[extends]; !ok && extends != "" {
+			baseManifest, err := ParseFile(extends.String())
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse inherited manifest: %w", err)
+			}
+
+			rc.PathManifest = extends
+
+			base := configuration{context: &rc, manifest: baseManifest}
+			toBuild = append(toBuild, base, cfg)
+
+			visited[extends] = struct{}{}
+
+			continue
+		}
+
+		ks := cfg.manifest.Encryption
+		if !ks.IsSet() {
+			continue
+		}
+
+		if err := ks.Configure(&rc); err != nil {
+			return nil, err
+		}
+
+		if err := ks.MergeInto(&out); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := out.Validate(rc); err != nil {
+		return nil, err
+	}
+
+	return &out, nil
+}
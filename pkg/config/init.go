@@ -0,0 +1,382 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+	"github.com/coffeebeats/gdbuild/pkg/godot/project"
+)
+
+const (
+	// defaultGodotVersion is the Godot version set in newly-initialized
+	// manifests when the project's version can't be detected.
+	defaultGodotVersion = "4.2.1-stable"
+
+	filenameCustomPy     = "custom.py"
+	filenameGodotVersion = ".godot-version"
+)
+
+var ErrUnrecognizedLayout = errors.New("unrecognized layout")
+
+/* -------------------------------------------------------------------------- */
+/*                                Enum: Layout                                */
+/* -------------------------------------------------------------------------- */
+
+// Layout is a starter set of targets for a newly-initialized manifest.
+type Layout string
+
+const (
+	LayoutUnknown      Layout = ""
+	LayoutClient       Layout = "client"
+	LayoutClientDLC    Layout = "client-dlc"
+	LayoutClientServer Layout = "client-server"
+)
+
+/* -------------------------- Function: ParseLayout ------------------------- */
+
+// ParseLayout parses an input string as a starter 'Layout'.
+func ParseLayout(input string) (Layout, error) {
+	switch l := Layout(strings.ToLower(strings.TrimSpace(input))); l {
+	case LayoutUnknown, LayoutClient, LayoutClientDLC, LayoutClientServer:
+		return l, nil
+	default:
+		return LayoutUnknown, fmt.Errorf("%w: '%s'", ErrUnrecognizedLayout, input)
+	}
+}
+
+/* -------------------------------------------------------------------------- */
+/*                             Struct: InitOptions                            */
+/* -------------------------------------------------------------------------- */
+
+// InitOptions configures how a new GDBuild manifest is generated.
+type InitOptions struct {
+	// FromPresets requires that targets be generated from the project's
+	// 'export_presets.cfg' file.
+	FromPresets bool
+	// Layout is the starter layout of targets to generate. If unset, targets
+	// are generated from the project's export presets if present, otherwise
+	// 'LayoutClient' is used.
+	Layout Layout
+}
+
+/* ----------------------------- Function: Init ----------------------------- */
+
+// Init initializes a GDBuild manifest at the specified path. Note that 'path'
+// can be a directory or a '.toml' file. The Godot project in the directory
+// containing the manifest is inspected to determine the manifest's contents.
+func Init(path string, opts InitOptions) error {
+	if opts.FromPresets && opts.Layout != LayoutUnknown {
+		return fmt.Errorf(
+			"%w: cannot generate targets from presets with a layout",
+			ErrConflictingValue,
+		)
+	}
+
+	path, err := resolveManifestPath(path)
+	if err != nil {
+		return err
+	}
+
+	contents, err := generateManifest(filepath.Dir(path), opts)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644) //nolint:gomnd
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	if _, err := f.WriteString(contents); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/* ---------------------- Function: resolveManifestPath --------------------- */
+
+// resolveManifestPath returns the path to a new GDBuild manifest given a path
+// to a directory or a '.toml' file. The manifest must not already exist.
+func resolveManifestPath(path string) (string, error) { //nolint:cyclop
+	if path == "" {
+		return "", fmt.Errorf("%w: 'path'", ErrMissingInput)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		// Assume the path is a directory if it doesn't end with '.toml'.
+		if !strings.HasSuffix(path, ".toml") {
+			if strings.Contains(filepath.Base(path), ".") {
+				return "", fmt.Errorf(
+					"%w: path must be a directory or a '.toml' file: %s",
+					ErrInvalidInput,
+					path,
+				)
+			}
+
+			path = filepath.Join(path, DefaultFilename())
+		}
+	}
+
+	if info != nil {
+		if !info.IsDir() {
+			return "", fmt.Errorf("%w: %s", fs.ErrExist, path)
+		}
+
+		path = filepath.Join(path, DefaultFilename())
+	}
+
+	// Check again if the file exists.
+	info, err = os.Stat(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	if info != nil {
+		return "", fmt.Errorf("%w: %s", fs.ErrExist, path)
+	}
+
+	return path, nil
+}
+
+/* ----------------------- Function: generateManifest ----------------------- */
+
+// generateManifest generates the contents of a GDBuild manifest for the Godot
+// project in the directory 'path'.
+func generateManifest(path string, opts InitOptions) (string, error) { //nolint:cyclop,funlen
+	p, err := project.Load(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		log.Warnf("no Godot project found; using defaults: %s", path)
+	}
+
+	var out strings.Builder
+
+	if p != nil {
+		fmt.Fprintf(&out, "# GDBuild manifest for the Godot project '%s'", p.Name)
+
+		if v := p.Version(); v != "" {
+			fmt.Fprintf(&out, " (Godot %s, renderer '%s'", v, p.RenderingMethod)
+		} else {
+			fmt.Fprintf(&out, " (renderer '%s'", p.RenderingMethod)
+		}
+
+		if p.IsDotNet {
+			out.WriteString(", C#")
+		}
+
+		out.WriteString(").\n\n")
+	}
+
+	out.WriteString(`[config]
+  # Inherit from the specified manifest file, merging the configuration in
+  # this file on top of the settings in the specified file.
+  extends = ""
+
+[godot]
+`)
+
+	switch {
+	case isFile(filepath.Join(path, filenameGodotVersion)):
+		out.WriteString("  # A file containing the version of Godot to use for compiling and exporting.\n")
+		out.WriteString(`  version_file = "` + filenameGodotVersion + "\"\n")
+	default:
+		out.WriteString("  # The version of Godot to use for compiling and exporting.\n")
+		out.WriteString(`  version = "` + godotVersionOf(p) + "\"\n")
+	}
+
+	// Generate targets.
+
+	pathPresets := filepath.Join(path, export.FilenameExportPresets)
+
+	switch hasPresets := isFile(pathPresets); {
+	case opts.FromPresets && !hasPresets:
+		return "", fmt.Errorf("%w: export presets file not found: %s", ErrMissingInput, pathPresets)
+
+	case opts.FromPresets || (opts.Layout == LayoutUnknown && hasPresets):
+		cfg, err := os.ReadFile(pathPresets)
+		if err != nil {
+			return "", err
+		}
+
+		targets, err := FromPresets(string(cfg))
+		if err != nil {
+			return "", err
+		}
+
+		out.WriteString(targets)
+
+	default:
+		out.WriteString(layoutTargets(opts.Layout))
+	}
+
+	// Generate export template settings.
+
+	out.WriteString("\n[template]\n")
+
+	if isFile(filepath.Join(path, filenameCustomPy)) {
+		out.WriteString("  # A path to a 'custom.py' file which defines export template build options.\n")
+		out.WriteString(`  custom_py_path = "` + filenameCustomPy + "\"\n")
+	}
+
+	modules, err := findCustomModules(path)
+	if err != nil {
+		return "", err
+	}
+
+	if len(modules) > 0 {
+		out.WriteString("  # Paths to directories containing custom engine modules.\n")
+		out.WriteString("  custom_modules = " + tomlValue(modules) + "\n")
+	}
+
+	out.WriteString(`
+[template.scons]
+  command = ["python3", "-m", "SCons"]
+`)
+
+	return out.String(), nil
+}
+
+/* ------------------------ Function: godotVersionOf ------------------------ */
+
+// godotVersionOf returns the Godot version label to use for the project 'p',
+// which may be nil.
+func godotVersionOf(p *project.Project) string {
+	if p == nil || p.Version() == "" {
+		return defaultGodotVersion
+	}
+
+	// NOTE: C# projects require a .NET-enabled editor.
+	if p.IsDotNet {
+		return p.Version() + "-stable_mono"
+	}
+
+	return p.Version() + "-stable"
+}
+
+/* ------------------------- Function: layoutTargets ------------------------ */
+
+// layoutTargets returns the '[target.*]' tables of the specified layout.
+func layoutTargets(l Layout) string {
+	var out strings.Builder
+
+	exclude := ""
+	if l == LayoutClientDLC {
+		exclude = `, exclude = ["dlc"]`
+	}
+
+	out.WriteString(`
+[target.client]
+  runnable         = true
+  default_features = []
+
+  pack_files = [{include = ["*"]` + exclude + `, embed = true, encrypt = true}]
+
+[target.client.profile.release]
+  # NOTE: Set the encryption key via the 'SCRIPT_AES256_ENCRYPTION_KEY' variable
+  # or one of the sources in the 'encryption' section. Generate a key with
+  # 'gdbuild key generate'.
+  encrypt = true
+`)
+
+	switch l { //nolint:exhaustive
+	case LayoutClientServer:
+		out.WriteString(`
+[target.server]
+  runnable         = true
+  server           = true
+  default_features = []
+
+  # NOTE: Visuals (e.g. textures) are stripped from the server's resources.
+  pack_files = [{include = ["*"], embed = true, visuals = false}]
+`)
+
+	case LayoutClientDLC:
+		out.WriteString(`
+[target.dlc]
+  # NOTE: Downloadable content is exported from the 'dlc' directory into a
+  # separate pack file.
+  pack_files = [{include = ["dlc"], encrypt = true}]
+
+[target.dlc.profile.release]
+  encrypt = true
+`)
+	}
+
+	return out.String()
+}
+
+/* ---------------------- Function: findCustomModules ----------------------- */
+
+// findCustomModules returns the paths, relative to 'root', of directories which
+// either are or directly contain custom engine modules. A module is a directory
+// containing both an 'SCsub' and a 'config.py' file.
+func findCustomModules(root string) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(root, e.Name())
+
+		if isModule(path) {
+			out = append(out, e.Name())
+
+			continue
+		}
+
+		children, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if slices.ContainsFunc(children, func(c fs.DirEntry) bool {
+			return c.IsDir() && isModule(filepath.Join(path, c.Name()))
+		}) {
+			out = append(out, e.Name())
+		}
+	}
+
+	return out, nil
+}
+
+/* --------------------------- Function: isModule --------------------------- */
+
+func isModule(path string) bool {
+	return isFile(filepath.Join(path, "SCsub")) && isFile(filepath.Join(path, "config.py"))
+}
+
+/* ---------------------------- Function: isFile ---------------------------- */
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+
+	return err == nil && info.Mode().IsRegular()
+}
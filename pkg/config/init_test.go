@@ -0,0 +1,190 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/config"
+	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+)
+
+func TestInit(t *testing.T) {
+	const projectGodot = `config_version=5
+
+[application]
+
+config/name="Game"
+config/features=PackedStringArray("4.3", "Forward Plus")
+`
+
+	tests := []struct {
+		name string
+
+		files map[string]string
+		opts  config.InitOptions
+
+		wantVersion     string
+		wantVersionFile osutil.Path
+		wantTargets     []string
+		wantCustomPy    osutil.Path
+		wantModules     []osutil.Path
+		err             error
+	}{
+		{
+			name: "missing project uses defaults",
+
+			wantVersion: "4.2.1-stable",
+			wantTargets: []string{"client"},
+		},
+		{
+			name: "project version is detected",
+
+			files: map[string]string{"project.godot": projectGodot},
+
+			wantVersion: "4.3-stable",
+			wantTargets: []string{"client"},
+		},
+		{
+			name: "version file is preferred to project version",
+
+			files: map[string]string{"project.godot": projectGodot, ".godot-version": "4.3.1-stable"},
+
+			wantVersionFile: ".godot-version",
+			wantTargets:     []string{"client"},
+		},
+		{
+			name: "template files are detected",
+
+			files: map[string]string{
+				"project.godot":              projectGodot,
+				"custom.py":                  "",
+				"modules/a/SCsub":            "",
+				"modules/a/config.py":        "",
+				"module/SCsub":               "",
+				"module/config.py":           "",
+				"addons/plugin/plugin.cfg":   "",
+				"notamodule/nested/SCsub":    "",
+				"notamodule/nested/other.py": "",
+			},
+
+			wantVersion:  "4.3-stable",
+			wantTargets:  []string{"client"},
+			wantCustomPy: "custom.py",
+			wantModules:  []osutil.Path{"module", "modules"},
+		},
+		{
+			name: "client-server layout is generated",
+
+			files: map[string]string{"project.godot": projectGodot},
+			opts:  config.InitOptions{Layout: config.LayoutClientServer}, //nolint:exhaustruct
+
+			wantVersion: "4.3-stable",
+			wantTargets: []string{"client", "server"},
+		},
+		{
+			name: "client-dlc layout is generated",
+
+			files: map[string]string{"project.godot": projectGodot},
+			opts:  config.InitOptions{Layout: config.LayoutClientDLC}, //nolint:exhaustruct
+
+			wantVersion: "4.3-stable",
+			wantTargets: []string{"client", "dlc"},
+		},
+		{
+			name: "export presets are used by default",
+
+			files: map[string]string{
+				"project.godot":      projectGodot,
+				"export_presets.cfg": "[preset.0]\n\nname=\"Web\"\nplatform=\"Web\"\nrunnable=true\n\n[preset.0.options]\n",
+			},
+
+			wantVersion: "4.3-stable",
+			wantTargets: []string{"web"},
+		},
+		{
+			name: "layout overrides export presets",
+
+			files: map[string]string{
+				"project.godot":      projectGodot,
+				"export_presets.cfg": "[preset.0]\n\nname=\"Web\"\nplatform=\"Web\"\nrunnable=true\n\n[preset.0.options]\n",
+			},
+			opts: config.InitOptions{Layout: config.LayoutClient}, //nolint:exhaustruct
+
+			wantVersion: "4.3-stable",
+			wantTargets: []string{"client"},
+		},
+		{
+			name: "missing export presets returns an error",
+
+			files: map[string]string{"project.godot": projectGodot},
+			opts:  config.InitOptions{FromPresets: true}, //nolint:exhaustruct
+
+			err: config.ErrMissingInput,
+		},
+		{
+			name: "presets and layout conflict",
+
+			opts: config.InitOptions{FromPresets: true, Layout: config.LayoutClient},
+
+			err: config.ErrConflictingValue,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A Godot project directory.
+			root := t.TempDir()
+
+			for name, contents := range tc.files {
+				path := filepath.Join(root, name)
+
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+				require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+			}
+
+			// When: A manifest is initialized.
+			err := config.Init(root, tc.opts)
+
+			// Then: The returned error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+
+			if tc.err != nil {
+				return
+			}
+
+			// Then: The manifest can be parsed.
+			m, err := config.ParseFile(filepath.Join(root, config.DefaultFilename()))
+			require.NoError(t, err)
+
+			// Then: The Godot version matches expectations.
+			require.NotNil(t, m.Godot.Source)
+
+			var wantVersion engine.Version
+			require.NoError(t, wantVersion.UnmarshalText([]byte(tc.wantVersion)))
+
+			assert.Equal(t, wantVersion, m.Godot.Version)
+			assert.Equal(t, tc.wantVersionFile, m.Godot.VersionFile)
+
+			// Then: The expected targets are generated.
+			targets := make([]string, 0, len(m.Target))
+			for name := range m.Target {
+				targets = append(targets, name)
+			}
+
+			assert.ElementsMatch(t, tc.wantTargets, targets)
+
+			// Then: The template settings match expectations.
+			require.NotNil(t, m.Template.Template)
+			assert.Equal(t, tc.wantCustomPy, m.Template.PathCustomPy)
+			assert.Equal(t, tc.wantModules, m.Template.CustomModules)
+		})
+	}
+}
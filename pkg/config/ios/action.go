@@ -0,0 +1,165 @@
+package ios
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+// NOTE: These match the layout of 'misc/dist/ios_xcode' in the Godot source.
+const (
+	xcframeworkDevice    = "ios-arm64"
+	xcframeworkSimulator = "ios-arm64_x86_64-simulator"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                        Function: NewXCFrameworkAction                       */
+/* -------------------------------------------------------------------------- */
+
+// NewXCFrameworkAction creates an 'action.Action' which assembles the iOS
+// export template archive from the compiled device and simulator libraries.
+// The libraries are placed into both the debug and release 'xcframework'
+// directories so that the archive can be used for either export mode.
+func NewXCFrameworkAction(
+	rc *run.Context,
+	device, simulator string,
+	pathMoltenVK osutil.Path,
+) action.WithDescription[action.Function] {
+	fn := func(ctx context.Context) error {
+		tmp, err := rc.TempDir()
+		if err != nil {
+			return err
+		}
+
+		pathXcode := filepath.Join(tmp, "ios_xcode")
+
+		if err := osutil.CopyDir(
+			rc.PathWorkspace.Join("misc/dist/ios_xcode").String(),
+			pathXcode,
+		); err != nil {
+			return err
+		}
+
+		for _, mode := range []string{"debug", "release"} {
+			pathFramework := filepath.Join(pathXcode, "libgodot.ios."+mode+".xcframework")
+
+			for dir, lib := range map[string]string{
+				xcframeworkDevice:    device,
+				xcframeworkSimulator: simulator,
+			} {
+				pathDst := filepath.Join(pathFramework, dir, "libgodot.a")
+
+				if err := os.MkdirAll(filepath.Dir(pathDst), osutil.ModeUserRWX); err != nil {
+					return err
+				}
+
+				if err := osutil.CopyFile(
+					ctx,
+					rc.BinPath().Join(lib).String(),
+					pathDst,
+				); err != nil {
+					return err
+				}
+			}
+		}
+
+		if pathMoltenVK != "" {
+			if err := osutil.CopyDir(
+				pathMoltenVK.String(),
+				filepath.Join(pathXcode, "MoltenVK.xcframework"),
+			); err != nil {
+				return err
+			}
+		}
+
+		return zipDir(
+			osutil.Path(pathXcode),
+			rc.BinPath().Join(templateArchiveName),
+		)
+	}
+
+	return action.WithDescription[action.Function]{
+		Action:      fn,
+		Description: "create xcframework template archive: " + templateArchiveName,
+	}
+}
+
+/* ---------------------------- Function: zipDir ---------------------------- */
+
+// zipDir writes the contents of the directory 'root' to a zip archive at 'out'.
+// Unlike an app bundle, the contents are placed at the root of the archive.
+func zipDir(root, out osutil.Path) error {
+	if err := root.CheckIsDir(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(out.Dir().String(), osutil.ModeUserRWX); err != nil {
+		return err
+	}
+
+	f, err := os.Create(out.String())
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(f)
+
+	if err := fs.WalkDir(
+		os.DirFS(root.String()),
+		".",
+		func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if path == "." {
+				return nil
+			}
+
+			if d.IsDir() {
+				_, err := archive.Create(path + "/")
+
+				return err
+			}
+
+			return zipFile(archive, root, path)
+		},
+	); err != nil {
+		return errors.Join(err, archive.Close(), f.Close())
+	}
+
+	if err := archive.Close(); err != nil {
+		return errors.Join(err, f.Close())
+	}
+
+	return f.Close()
+}
+
+/* ---------------------------- Function: zipFile --------------------------- */
+
+// zipFile writes the file at 'path', relative to 'root', to the zip archive.
+func zipFile(archive *zip.Writer, root osutil.Path, path string) error {
+	w, err := archive.Create(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(filepath.Join(root.String(), path))
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, f); err != nil {
+		return errors.Join(err, f.Close())
+	}
+
+	return f.Close()
+}
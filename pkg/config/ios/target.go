@@ -0,0 +1,209 @@
+package ios
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/pkg/config/common"
+	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+	"github.com/coffeebeats/gdbuild/pkg/godot/template"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+var (
+	ErrInvalidInput = errors.New("invalid input")
+	ErrMissingInput = errors.New("missing input")
+)
+
+var (
+	// bundleIdentifierRegex matches a valid iOS bundle identifier; see
+	// https://developer.apple.com/documentation/bundleresources/information_property_list/cfbundleidentifier.
+	bundleIdentifierRegex = regexp.MustCompile(`^[a-zA-Z0-9-]+(\.[a-zA-Z0-9-]+)+$`)
+
+	// capabilityRegex matches the name of a Godot iOS export capability.
+	capabilityRegex = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+	// teamIDRegex matches a valid Apple Developer team ID.
+	teamIDRegex = regexp.MustCompile(`^[A-Z0-9]{10}$`)
+)
+
+/* -------------------------------------------------------------------------- */
+/*                               Struct: Target                               */
+/* -------------------------------------------------------------------------- */
+
+type Target struct {
+	*common.Target
+
+	// BundleIdentifier is the unique application identifier (e.g.
+	// 'com.example.game').
+	BundleIdentifier string `toml:"bundle_identifier"`
+	// Capabilities is a list of app capabilities (e.g. 'access_wifi') to enable
+	// in the exported Xcode project.
+	Capabilities []string `toml:"capabilities"`
+	// ExportProjectOnly determines whether only the Xcode project is exported;
+	// otherwise, an '.ipa' file is also built (requires Xcode).
+	ExportProjectOnly *bool `toml:"export_project_only"`
+	// TeamID is the Apple Developer team ID used for code signing.
+	TeamID string `toml:"team_id"`
+}
+
+/* ----------------------------- Impl: Exporter ----------------------------- */
+
+func (t *Target) Collect(rc *run.Context, tl *template.Template, ev engine.Version) *export.Export {
+	out := t.Target.Collect(rc, tl, ev)
+
+	if out.Options == nil {
+		out.Options = map[string]any{}
+	}
+
+	out.Options["application/app_store_team_id"] = t.TeamID
+	out.Options["application/bundle_identifier"] = t.BundleIdentifier
+
+	for _, c := range t.Capabilities {
+		out.Options["capabilities/"+c] = true
+	}
+
+	exportProjectOnly := config.Dereference(t.ExportProjectOnly)
+	out.Options["application/export_project_only"] = exportProjectOnly
+
+	// Register the additional files generated by the iOS export. These are
+	// named after the embedded pack file, which Godot exports as the project's
+	// resource directory.
+	for i, pf := range out.PackFiles {
+		if !config.Dereference(pf.Embed) {
+			continue
+		}
+
+		name := pf.Filename(rc.Platform, rc.Target, i)
+
+		out.ExtraArtifacts = append(out.ExtraArtifacts, name+".xcodeproj/")
+
+		if !exportProjectOnly {
+			out.ExtraArtifacts = append(out.ExtraArtifacts, name+".ipa")
+		}
+
+		break
+	}
+
+	return out
+}
+
+/* ------------------------- Impl: config.Configurer ------------------------ */
+
+func (t *Target) Configure(rc *run.Context) error {
+	return t.Target.Configure(rc)
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (t *Target) Validate(rc *run.Context) error {
+	if err := t.Target.Validate(rc); err != nil {
+		return err
+	}
+
+	if t.BundleIdentifier == "" {
+		return fmt.Errorf("%w: 'bundle_identifier'", ErrMissingInput)
+	}
+
+	if !bundleIdentifierRegex.MatchString(t.BundleIdentifier) {
+		return fmt.Errorf(
+			"%w: invalid bundle identifier: %s",
+			ErrInvalidInput,
+			t.BundleIdentifier,
+		)
+	}
+
+	if t.TeamID == "" {
+		return fmt.Errorf("%w: 'team_id'", ErrMissingInput)
+	}
+
+	if !teamIDRegex.MatchString(t.TeamID) {
+		return fmt.Errorf("%w: invalid team ID: %s", ErrInvalidInput, t.TeamID)
+	}
+
+	for _, c := range t.Capabilities {
+		if !capabilityRegex.MatchString(c) {
+			return fmt.Errorf("%w: invalid capability: %s", ErrInvalidInput, c)
+		}
+	}
+
+	return nil
+}
+
+/* --------------------------- Impl: config.Merger -------------------------- */
+
+func (t *Target) MergeInto(other any) error {
+	if t == nil || other == nil {
+		return nil
+	}
+
+	dst, ok := other.(*Target)
+	if !ok {
+		return fmt.Errorf(
+			"%w: expected a '%T' but was '%T'",
+			config.ErrInvalidInput,
+			new(Target),
+			other,
+		)
+	}
+
+	return config.Merge(dst, *t)
+}
+
+/* -------------------------------------------------------------------------- */
+/*                    Struct: TargetWithFeaturesAndProfile                    */
+/* -------------------------------------------------------------------------- */
+
+type TargetWithFeaturesAndProfile struct {
+	*Target
+
+	Feature map[string]TargetWithProfile `toml:"feature"`
+	Profile map[engine.Profile]Target    `toml:"profile"`
+}
+
+/* ------------------------ Struct: TargetWithProfile ----------------------- */
+
+type TargetWithProfile struct {
+	*Target
+
+	Profile map[engine.Profile]Target `toml:"profile"`
+}
+
+/* ---------------------- Impl: platform.targetBuilder ---------------------- */
+
+func (t *TargetWithFeaturesAndProfile) Build(rc *run.Context, dst *Target) error {
+	if t == nil {
+		return nil
+	}
+
+	// Root-level params
+	if err := t.Target.MergeInto(dst); err != nil {
+		return err
+	}
+
+	// Feature-constrained params
+	for _, f := range rc.Features {
+		if err := t.Feature[f].Target.MergeInto(dst); err != nil {
+			return err
+		}
+	}
+
+	// Profile-constrained params
+	l := t.Profile[rc.Profile]
+	if err := l.MergeInto(dst); err != nil {
+		return err
+	}
+
+	// Feature-and-profile-constrained params
+	for _, f := range rc.Features {
+		l := t.Feature[f].Profile[rc.Profile]
+		if err := l.MergeInto(dst); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,256 @@
+package ios
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/internal/exec"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/config/common"
+	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/godot/template"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+// templateArchiveName is the name of the export template archive expected by
+// Godot for iOS exports.
+const templateArchiveName = "ios.zip"
+
+/* -------------------------------------------------------------------------- */
+/*                              Struct: Template                              */
+/* -------------------------------------------------------------------------- */
+
+type Template struct {
+	*common.Template
+
+	// LipoCommand contains arguments used to invoke 'lipo'. Defaults to
+	// ["lipo"]. Only used if more than one simulator architecture is built.
+	LipoCommand []string `toml:"lipo_command"`
+
+	// PathMoltenVK is a path to a 'MoltenVK.xcframework' directory which will
+	// be included in the export template archive. Required for Vulkan support.
+	PathMoltenVK osutil.Path `toml:"moltenvk_path"`
+
+	// SimulatorArchs is the list of architectures to build simulator libraries
+	// for. Defaults to ["arm64", "x86_64"].
+	SimulatorArchs []platform.Arch `toml:"simulator_archs"`
+}
+
+/* ----------------------------- Impl: Template ----------------------------- */
+
+func (t *Template) Collect(g engine.Source, rc *run.Context) *template.Template {
+	out := t.Template.Collect(g, rc)
+
+	// NOTE: Only 'arm64' devices are supported by Godot 4.
+	device := t.collectBuild(out.Builds[0], platform.ArchArm64, false)
+
+	builds := []template.Build{device}
+
+	simulatorArchs := t.SimulatorArchs
+	if len(simulatorArchs) == 0 {
+		simulatorArchs = []platform.Arch{platform.ArchArm64, platform.ArchAmd64}
+	}
+
+	simulators := make([]string, 0, len(simulatorArchs))
+
+	for _, a := range simulatorArchs {
+		b := t.collectBuild(out.Builds[0], a, true)
+		builds = append(builds, b)
+		simulators = append(simulators, b.Basename(rc))
+	}
+
+	out.Arch = platform.ArchArm64
+	out.Builds = builds
+	out.NameOverride = templateArchiveName
+
+	// Combine the simulator libraries into a single, multi-architecture static
+	// library (if needed).
+	simulator := simulators[0]
+
+	if len(simulators) > 1 {
+		simulator = strings.Replace(
+			simulators[0],
+			"."+simulatorArchs[0].String()+".simulator",
+			".simulator",
+			1,
+		)
+
+		lipo := slices.Clone(t.LipoCommand)
+		if len(lipo) == 0 {
+			lipo = append(lipo, "lipo")
+		}
+
+		args := append(lipo, "-create") //nolint:gocritic
+		args = append(args, simulators...)
+		args = append(args, "-output", simulator)
+
+		out.ExtraArtifacts = append(out.ExtraArtifacts, simulator)
+		out.Postbuild = action.InOrder(
+			&action.Process{
+				Directory:   rc.BinPath().String(),
+				Environment: nil,
+
+				// NOTE: Run 'lipo' directly so that the library paths are
+				// passed through unchanged.
+				Shell:   exec.ShellNone,
+				Verbose: rc.Verbose,
+
+				Args: args,
+			},
+			out.Postbuild,
+		)
+	}
+
+	if t.PathMoltenVK != "" {
+		out.RegisterDependencyPath(t.PathMoltenVK)
+	}
+
+	out.ExtraArtifacts = append(out.ExtraArtifacts, templateArchiveName)
+	out.Postbuild = action.InOrder(
+		out.Postbuild,
+		NewXCFrameworkAction(rc, device.Basename(rc), simulator, t.PathMoltenVK),
+	)
+
+	return out
+}
+
+/* --------------------------- Method: collectBuild -------------------------- */
+
+// collectBuild derives a 'template.Build' for the specified architecture from
+// the provided base build.
+func (t *Template) collectBuild(base template.Build, arch platform.Arch, simulator bool) template.Build {
+	b := base
+
+	b.Arch = arch
+	b.Platform = platform.OSIOS
+
+	b.SCons.ExtraArgs = slices.Clone(base.SCons.ExtraArgs)
+
+	if simulator {
+		b.ExtraSuffix = ".simulator"
+		b.SCons.ExtraArgs = append(b.SCons.ExtraArgs, "ios_simulator=yes")
+	}
+
+	return b
+}
+
+/* ------------------------- Impl: config.Configurer ------------------------ */
+
+func (t *Template) Configure(rc *run.Context) error {
+	if err := t.Template.Configure(rc); err != nil {
+		return err
+	}
+
+	if err := t.PathMoltenVK.RelTo(rc.PathManifest); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (t *Template) Validate(rc *run.Context) error {
+	if err := t.Template.Validate(rc); err != nil {
+		return err
+	}
+
+	if !t.Arch.IsOneOf(platform.ArchArm64, platform.ArchUniversal, platform.ArchUnknown) {
+		return fmt.Errorf("%w: unsupport architecture: %s", config.ErrInvalidInput, t.Arch)
+	}
+
+	for _, a := range t.SimulatorArchs {
+		if !a.IsOneOf(platform.ArchAmd64, platform.ArchArm64) {
+			return fmt.Errorf("%w: unsupport simulator architecture: %s", config.ErrInvalidInput, a)
+		}
+	}
+
+	if t.PathMoltenVK != "" {
+		if err := t.PathMoltenVK.CheckIsDir(); err != nil {
+			return fmt.Errorf("%w: missing path to MoltenVK framework", err)
+		}
+	}
+
+	// NOTE: Don't check for 'lipo', that should be a runtime check.
+
+	return nil
+}
+
+/* --------------------------- Impl: config.Merger -------------------------- */
+
+func (t *Template) MergeInto(other any) error {
+	if t == nil || other == nil {
+		return nil
+	}
+
+	dst, ok := other.(*Template)
+	if !ok {
+		return fmt.Errorf(
+			"%w: expected a '%T' but was '%T'",
+			config.ErrInvalidInput,
+			new(Template),
+			other,
+		)
+	}
+
+	return config.Merge(dst, *t)
+}
+
+/* -------------------------------------------------------------------------- */
+/*                   Struct: TemplateWithFeaturesAndProfile                   */
+/* -------------------------------------------------------------------------- */
+
+type TemplateWithFeaturesAndProfile struct {
+	*Template
+
+	Feature map[string]TemplateWithProfile `toml:"feature"`
+	Profile map[engine.Profile]Template    `toml:"profile"`
+}
+
+/* ----------------------- Struct: TemplateWithProfile ---------------------- */
+
+type TemplateWithProfile struct {
+	*Template
+
+	Profile map[engine.Profile]Template `toml:"profile"`
+}
+
+/* --------------------- Impl: platform.templateBuilder --------------------- */
+
+func (t *TemplateWithFeaturesAndProfile) Build(rc *run.Context, dst *Template) error {
+	if t == nil {
+		return nil
+	}
+
+	// Root-level params
+	if err := t.Template.MergeInto(dst); err != nil {
+		return err
+	}
+
+	// Feature-constrained params
+	for _, f := range rc.Features {
+		if err := t.Feature[f].Template.MergeInto(dst); err != nil {
+			return err
+		}
+	}
+
+	// Profile-constrained params
+	l := t.Profile[rc.Profile]
+	if err := l.MergeInto(dst); err != nil {
+		return err
+	}
+
+	// Feature-and-profile-constrained params
+	for _, f := range rc.Features {
+		l := t.Feature[f].Profile[rc.Profile]
+		if err := l.MergeInto(dst); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package ios_test
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/pkg/config/common"
+	"github.com/coffeebeats/gdbuild/pkg/config/ios"
+	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+func TestTemplateCollect(t *testing.T) {
+	jobs := "-j" + strconv.Itoa(runtime.NumCPU())
+
+	tests := []struct {
+		name string
+
+		template ios.Template
+
+		wantArgs      [][]string
+		wantBasenames []string
+		wantLipo      []string
+		wantArtifacts []string
+	}{
+		{
+			name: "default template builds device and universal simulator libraries",
+
+			template: ios.Template{Template: &common.Template{}},
+
+			wantArgs: [][]string{
+				{"scons", jobs, "platform=ios", "arch=arm64", "target=template_release", "warnings=extra", "werror=yes", "production=yes", "optimize=speed"},
+				{"scons", jobs, "platform=ios", "arch=arm64", "target=template_release", "warnings=extra", "werror=yes", "production=yes", "optimize=speed", "ios_simulator=yes"},
+				{"scons", jobs, "platform=ios", "arch=x86_64", "target=template_release", "warnings=extra", "werror=yes", "production=yes", "optimize=speed", "ios_simulator=yes"},
+			},
+			wantBasenames: []string{
+				"libgodot.ios.template_release.arm64.a",
+				"libgodot.ios.template_release.arm64.simulator.a",
+				"libgodot.ios.template_release.x86_64.simulator.a",
+			},
+			wantLipo: []string{
+				"lipo",
+				"-create",
+				"libgodot.ios.template_release.arm64.simulator.a",
+				"libgodot.ios.template_release.x86_64.simulator.a",
+				"-output",
+				"libgodot.ios.template_release.simulator.a",
+			},
+			wantArtifacts: []string{"libgodot.ios.template_release.simulator.a", "ios.zip"},
+		},
+		{
+			name: "single simulator architecture skips 'lipo'",
+
+			template: ios.Template{
+				Template: &common.Template{
+					DoublePrecision: pointer(true),
+				},
+				SimulatorArchs: []platform.Arch{platform.ArchArm64},
+			},
+
+			wantArgs: [][]string{
+				{"scons", jobs, "platform=ios", "arch=arm64", "target=template_release", "warnings=extra", "werror=yes", "precision=double", "production=yes", "optimize=speed"},
+				{"scons", jobs, "platform=ios", "arch=arm64", "target=template_release", "warnings=extra", "werror=yes", "precision=double", "production=yes", "optimize=speed", "ios_simulator=yes"},
+			},
+			wantBasenames: []string{
+				"libgodot.ios.template_release.double.arm64.a",
+				"libgodot.ios.template_release.double.arm64.simulator.a",
+			},
+			wantArtifacts: []string{"ios.zip"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A run context for the iOS platform.
+			rc := run.Context{Platform: platform.OSIOS, Profile: engine.ProfileRelease}
+
+			// When: The template is collected.
+			got := tc.template.Collect(engine.Source{}, &rc)
+
+			// Then: The SCons commands and artifact names match expectations.
+			require.Len(t, got.Builds, len(tc.wantArgs))
+
+			for i, b := range got.Builds {
+				assert.Equal(t, tc.wantArgs[i], b.SConsCommand(&rc).Args)
+				assert.Equal(t, tc.wantBasenames[i], b.Basename(&rc))
+			}
+
+			// Then: The 'lipo' command matches expectations.
+			postbuild := got.Postbuild.Sprint()
+
+			if tc.wantLipo != nil {
+				assert.Contains(t, postbuild, strings.Join(tc.wantLipo, " "))
+			} else {
+				assert.NotContains(t, postbuild, "lipo")
+			}
+
+			// Then: The template archive is the expected artifact.
+			assert.Equal(t, "ios.zip", got.Basename(&rc))
+			assert.Equal(t, tc.wantArtifacts, got.ExtraArtifacts)
+		})
+	}
+}
+
+func pointer[T any](value T) *T {
+	return &value
+}
@@ -0,0 +1,622 @@
+package linux
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/exec"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+const (
+	modeDir        = 0o755
+	modeExecutable = 0o755
+	modeFile       = 0o644
+)
+
+/* -------------------------------------------------------------------------- */
+/*                           Struct: PackageContents                          */
+/* -------------------------------------------------------------------------- */
+
+// PackageContents describes the exported artifacts which are packaged.
+type PackageContents struct {
+	// Arch is the CPU architecture of the exported executable.
+	Arch platform.Arch
+	// Executable is the name of the exported executable, relative to the
+	// output directory.
+	Executable string
+	// Files are the names of all exported artifacts (including the
+	// executable), relative to the output directory.
+	Files []string
+}
+
+/* -------------------------------------------------------------------------- */
+/*                           Function: NewTarAction                           */
+/* -------------------------------------------------------------------------- */
+
+// NewTarAction creates an 'action.Action' which writes the exported artifacts
+// into a gzip-compressed tarball named 'name' within the output directory.
+// All files are placed in a top-level directory named after the package.
+func NewTarAction(
+	rc *run.Context,
+	p *Package,
+	contents PackageContents,
+	name string,
+) action.WithDescription[action.Function] {
+	fn := func(_ context.Context) error {
+		entries := []archiveEntry{{Name: p.Name + "/", Mode: modeDir}}
+
+		for _, f := range contents.Files {
+			entries = append(entries, archiveEntry{
+				Name: p.Name + "/" + f,
+				Mode: contents.modeOf(f),
+				Path: rc.PathOut.Join(f).String(),
+			})
+		}
+
+		return writeFile(rc.PathOut.Join(name).String(), func(w io.Writer) error {
+			return writeTarGz(w, entries)
+		})
+	}
+
+	return action.WithDescription[action.Function]{
+		Action:      fn,
+		Description: fmt.Sprintf("create tarball (config: %s): %s", p.Checksum(), name),
+	}
+}
+
+/* -------------------------------------------------------------------------- */
+/*                           Function: NewDebAction                           */
+/* -------------------------------------------------------------------------- */
+
+// NewDebAction creates an 'action.Action' which writes the exported artifacts
+// into a Debian package named 'name' within the output directory. Artifacts
+// are installed into '/opt/<name>', with the executable linked into
+// '/usr/bin' and a desktop entry registered.
+func NewDebAction(
+	rc *run.Context,
+	p *Package,
+	contents PackageContents,
+	name string,
+) action.WithDescription[action.Function] {
+	fn := func(_ context.Context) error {
+		entries := []archiveEntry{
+			{Name: "./", Mode: modeDir},
+			{Name: "./opt/", Mode: modeDir},
+			{Name: "./opt/" + p.Name + "/", Mode: modeDir},
+		}
+
+		var size int64
+
+		for _, f := range contents.Files {
+			info, err := os.Stat(rc.PathOut.Join(f).String())
+			if err != nil {
+				return err
+			}
+
+			size += info.Size()
+
+			entries = append(entries, archiveEntry{
+				Name: "./opt/" + p.Name + "/" + f,
+				Mode: contents.modeOf(f),
+				Path: rc.PathOut.Join(f).String(),
+			})
+		}
+
+		entries = append(
+			entries,
+			archiveEntry{Name: "./usr/", Mode: modeDir},
+			archiveEntry{Name: "./usr/bin/", Mode: modeDir},
+			archiveEntry{
+				Name: "./usr/bin/" + p.Name,
+				Link: "/opt/" + p.Name + "/" + contents.Executable,
+			},
+			archiveEntry{Name: "./usr/share/", Mode: modeDir},
+			archiveEntry{Name: "./usr/share/applications/", Mode: modeDir},
+			archiveEntry{
+				Name: "./usr/share/applications/" + p.Name + ".desktop",
+				Mode: modeFile,
+				Data: []byte(desktopEntry(p, p.Name)),
+			},
+		)
+
+		if p.PathIcon != "" {
+			entries = append(
+				entries,
+				archiveEntry{Name: "./usr/share/pixmaps/", Mode: modeDir},
+				archiveEntry{
+					Name: "./usr/share/pixmaps/" + p.Name + ".png",
+					Mode: modeFile,
+					Path: p.PathIcon.String(),
+				},
+			)
+		}
+
+		tmp, err := rc.TempDir()
+		if err != nil {
+			return err
+		}
+
+		// NOTE: The data archive contains the entire game, so write it to disk
+		// rather than buffering it in memory.
+		pathData := filepath.Join(tmp, name+".data.tar.gz")
+
+		defer os.Remove(pathData)
+
+		if err := writeFile(pathData, func(w io.Writer) error {
+			return writeTarGz(w, entries)
+		}); err != nil {
+			return err
+		}
+
+		var control bytes.Buffer
+
+		if err := writeTarGz(&control, []archiveEntry{
+			{Name: "./", Mode: modeDir},
+			{
+				Name: "./control",
+				Mode: modeFile,
+				Data: []byte(debianControl(p, contents.Arch, size)),
+			},
+		}); err != nil {
+			return err
+		}
+
+		return writeFile(rc.PathOut.Join(name).String(), func(w io.Writer) error {
+			return writeAr(w, []arMember{
+				{Name: "debian-binary", Data: []byte("2.0\n")},
+				{Name: "control.tar.gz", Data: control.Bytes()},
+				{Name: "data.tar.gz", Path: pathData},
+			})
+		})
+	}
+
+	return action.WithDescription[action.Function]{
+		Action:      fn,
+		Description: fmt.Sprintf("create Debian package (config: %s): %s", p.Checksum(), name),
+	}
+}
+
+/* -------------------------------------------------------------------------- */
+/*                         Function: NewAppImageAction                        */
+/* -------------------------------------------------------------------------- */
+
+// NewAppImageAction creates an 'action.Action' which assembles an AppDir from
+// the exported artifacts and converts it into an AppImage named 'name' within
+// the output directory using 'appimagetool'.
+func NewAppImageAction(
+	rc *run.Context,
+	p *Package,
+	contents PackageContents,
+	name string,
+) action.WithDescription[action.Function] {
+	fn := func(ctx context.Context) error {
+		tmp, err := rc.TempDir()
+		if err != nil {
+			return err
+		}
+
+		pathAppDir := filepath.Join(tmp, p.Name+".AppDir")
+
+		if err := os.RemoveAll(pathAppDir); err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Join(pathAppDir, "usr/bin"), modeDir); err != nil {
+			return err
+		}
+
+		for _, f := range contents.Files {
+			if err := copyFileWithMode(
+				ctx,
+				rc.PathOut.Join(f).String(),
+				filepath.Join(pathAppDir, "usr/bin", f),
+				os.FileMode(contents.modeOf(f)),
+			); err != nil {
+				return err
+			}
+		}
+
+		if err := copyFileWithMode(
+			ctx,
+			p.PathIcon.String(),
+			filepath.Join(pathAppDir, p.Name+".png"),
+			modeFile,
+		); err != nil {
+			return err
+		}
+
+		appRun := "#!/bin/sh\n" +
+			"HERE=\"$(dirname \"$(readlink -f \"$0\")\")\"\n" +
+			"exec \"$HERE/usr/bin/" + contents.Executable + "\" \"$@\"\n"
+
+		for _, f := range []struct {
+			name, data string
+			mode       os.FileMode
+		}{
+			{"AppRun", appRun, modeExecutable},
+			{p.Name + ".desktop", desktopEntry(p, contents.Executable), modeFile},
+		} {
+			if err := os.WriteFile(filepath.Join(pathAppDir, f.name), []byte(f.data), f.mode); err != nil {
+				return err
+			}
+
+			if err := os.Chmod(filepath.Join(pathAppDir, f.name), f.mode); err != nil {
+				return err
+			}
+		}
+
+		cmd := slices.Clone(p.AppImage.Command)
+		if len(cmd) == 0 {
+			cmd = append(cmd, "appimagetool")
+		}
+
+		cmd = append(cmd, pathAppDir, rc.PathOut.Join(name).String())
+
+		process := &action.Process{
+			Directory:   rc.PathOut.String(),
+			Environment: append(os.Environ(), "ARCH="+appImageArch(contents.Arch)),
+
+			// NOTE: Run 'appimagetool' directly so that the AppDir and output
+			// paths are passed through unchanged.
+			Shell:   exec.ShellNone,
+			Verbose: rc.Verbose,
+
+			Args: cmd,
+		}
+
+		return process.Run(ctx)
+	}
+
+	return action.WithDescription[action.Function]{
+		Action:      fn,
+		Description: fmt.Sprintf("create AppImage (config: %s): %s", p.Checksum(), name),
+	}
+}
+
+/* ------------------------- Method: PackageContents ------------------------ */
+
+func (c PackageContents) modeOf(name string) int64 {
+	if name == c.Executable {
+		return modeExecutable
+	}
+
+	return modeFile
+}
+
+/* -------------------------------------------------------------------------- */
+/*                            Struct: archiveEntry                            */
+/* -------------------------------------------------------------------------- */
+
+// archiveEntry is a single tarball entry. Exactly one of 'Data', 'Link', or
+// 'Path' should be set for non-directory entries; directory entries have names
+// ending in '/'.
+type archiveEntry struct {
+	Name string
+	Mode int64
+
+	Data []byte
+	Link string
+	Path string
+}
+
+/* -------------------------- Function: writeTarGz -------------------------- */
+
+// writeTarGz writes a gzip-compressed tarball of the provided entries. File
+// metadata is normalized so that the output is reproducible.
+func writeTarGz(w io.Writer, entries []archiveEntry) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for _, e := range entries {
+		hdr := &tar.Header{ //nolint:exhaustruct
+			Name:    e.Name,
+			Mode:    e.Mode,
+			ModTime: time.Unix(0, 0),
+			Uname:   "root",
+			Gname:   "root",
+		}
+
+		switch {
+		case strings.HasSuffix(e.Name, "/"):
+			hdr.Typeflag = tar.TypeDir
+		case e.Link != "":
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = e.Link
+			hdr.Mode = 0o777
+		default:
+			hdr.Typeflag = tar.TypeReg
+		}
+
+		if err := writeTarEntry(tw, hdr, e); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+/* ------------------------- Function: writeTarEntry ------------------------ */
+
+func writeTarEntry(tw *tar.Writer, hdr *tar.Header, e archiveEntry) error {
+	if hdr.Typeflag != tar.TypeReg {
+		return tw.WriteHeader(hdr)
+	}
+
+	if e.Path == "" {
+		hdr.Size = int64(len(e.Data))
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		_, err := tw.Write(e.Data)
+
+		return err
+	}
+
+	f, err := os.Open(e.Path)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	hdr.Size = info.Size()
+
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, f)
+
+	return err
+}
+
+/* -------------------------------------------------------------------------- */
+/*                              Struct: arMember                              */
+/* -------------------------------------------------------------------------- */
+
+// arMember is a single member of an 'ar' archive. Exactly one of 'Data' or
+// 'Path' should be set.
+type arMember struct {
+	Name string
+
+	Data []byte
+	Path string
+}
+
+/* ---------------------------- Function: writeAr --------------------------- */
+
+// writeAr writes a common-format 'ar' archive (as used by Debian packages)
+// containing the provided members in order.
+func writeAr(w io.Writer, members []arMember) error {
+	if _, err := io.WriteString(w, "!<arch>\n"); err != nil {
+		return err
+	}
+
+	for _, m := range members {
+		if len(m.Name) > 16 { //nolint:gomnd
+			return fmt.Errorf("%w: ar member name too long: %s", ErrInvalidInput, m.Name)
+		}
+
+		if err := writeArMember(w, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/* ------------------------- Function: writeArMember ------------------------ */
+
+// writeArMember writes a single member, including its header, to an 'ar'
+// archive. A member's file is streamed into the archive rather than read into
+// memory.
+func writeArMember(w io.Writer, m arMember) error {
+	if m.Path == "" {
+		return writeArData(w, m.Name, bytes.NewReader(m.Data), int64(len(m.Data)))
+	}
+
+	f, err := os.Open(m.Path)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return errors.Join(err, f.Close())
+	}
+
+	if err := writeArData(w, m.Name, f, info.Size()); err != nil {
+		return errors.Join(err, f.Close())
+	}
+
+	return f.Close()
+}
+
+/* -------------------------- Function: writeArData ------------------------- */
+
+func writeArData(w io.Writer, name string, r io.Reader, size int64) error {
+	hdr := fmt.Sprintf(
+		"%-16s%-12d%-6d%-6d%-8s%-10d`\n",
+		name,
+		0,
+		0,
+		0,
+		"100644",
+		size,
+	)
+
+	if _, err := io.WriteString(w, hdr); err != nil {
+		return err
+	}
+
+	if _, err := io.CopyN(w, r, size); err != nil {
+		return err
+	}
+
+	// Members are aligned to an even byte boundary.
+	if size%2 != 0 {
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/* -------------------------- Function: writeFile --------------------------- */
+
+func writeFile(path string, fn func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(f); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+/* ----------------------- Function: copyFileWithMode ----------------------- */
+
+func copyFileWithMode(ctx context.Context, src, dst string, mode os.FileMode) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	if err := osutil.CopyReaderWithMode(ctx, f, mode, dst); err != nil {
+		return err
+	}
+
+	// NOTE: The file mode is subject to the umask on creation, so set it
+	// explicitly.
+	return os.Chmod(dst, mode)
+}
+
+/* ------------------------- Function: desktopEntry ------------------------- */
+
+// desktopEntry returns the contents of a freedesktop.org desktop entry which
+// launches the executable 'exec'.
+func desktopEntry(p *Package, exec string) string {
+	title := p.Title
+	if title == "" {
+		title = p.Name
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("[Desktop Entry]\n")
+	sb.WriteString("Type=Application\n")
+	sb.WriteString("Name=" + title + "\n")
+
+	if p.Description != "" {
+		sb.WriteString("Comment=" + p.Description + "\n")
+	}
+
+	sb.WriteString("Exec=" + exec + "\n")
+	sb.WriteString("Icon=" + p.Name + "\n")
+	sb.WriteString("Terminal=false\n")
+	sb.WriteString("Categories=" + strings.Join(p.Categories, ";") + ";\n")
+
+	return sb.String()
+}
+
+/* ------------------------- Function: debianControl ------------------------ */
+
+// debianControl returns the contents of the Debian package 'control' file.
+func debianControl(p *Package, arch platform.Arch, size int64) string {
+	description := p.Description
+	if description == "" {
+		description = p.Title
+	}
+
+	if description == "" {
+		description = p.Name
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("Package: " + p.Name + "\n")
+	sb.WriteString("Version: " + p.Version + "\n")
+	sb.WriteString("Architecture: " + debianArch(arch) + "\n")
+	sb.WriteString("Maintainer: " + p.Deb.Maintainer + "\n")
+	sb.WriteString(fmt.Sprintf("Installed-Size: %d\n", (size+1023)/1024)) //nolint:gomnd
+
+	if len(p.Deb.Depends) > 0 {
+		sb.WriteString("Depends: " + strings.Join(p.Deb.Depends, ", ") + "\n")
+	}
+
+	sb.WriteString("Section: " + p.Deb.Section + "\n")
+	sb.WriteString("Priority: optional\n")
+	sb.WriteString("Description: " + description + "\n")
+
+	return sb.String()
+}
+
+/* -------------------------- Function: debianArch ------------------------- */
+
+// debianArch returns the Debian architecture name for the specified 'Arch'.
+func debianArch(arch platform.Arch) string {
+	switch arch { //nolint:exhaustive
+	case platform.ArchAmd64:
+		return "amd64"
+	case platform.ArchArm32:
+		return "armhf"
+	case platform.ArchArm64:
+		return "arm64"
+	case platform.ArchI386:
+		return "i386"
+	default:
+		return ""
+	}
+}
+
+/* ------------------------- Function: appImageArch ------------------------- */
+
+// appImageArch returns the 'appimagetool' architecture name for the specified
+// 'Arch'.
+func appImageArch(arch platform.Arch) string {
+	switch arch { //nolint:exhaustive
+	case platform.ArchAmd64:
+		return "x86_64"
+	case platform.ArchArm32:
+		return "armhf"
+	case platform.ArchArm64:
+		return "aarch64"
+	case platform.ArchI386:
+		return "i686"
+	default:
+		return ""
+	}
+}
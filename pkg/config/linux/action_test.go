@@ -0,0 +1,191 @@
+package linux_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/config/linux"
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+func TestNewTarAction(t *testing.T) {
+	// Given: An output directory containing exported artifacts.
+	rc, contents := setupExport(t)
+
+	// Given: A package configuration.
+	p := linux.Package{Name: "game"}
+
+	// When: The tarball action is executed.
+	err := linux.NewTarAction(rc, &p, contents, "game.tar.gz").Run(context.Background())
+	require.NoError(t, err)
+
+	// Then: The tarball contains the artifacts with the correct modes.
+	got := readTarGz(t, mustReadFile(t, rc.PathOut.Join("game.tar.gz").String()))
+	assert.Equal(t, map[string]int64{
+		"game/":           0o755,
+		"game/game":       0o755,
+		"game/game.0.pck": 0o644,
+	}, modes(got))
+}
+
+func TestNewDebAction(t *testing.T) {
+	// Given: An output directory containing exported artifacts.
+	rc, contents := setupExport(t)
+
+	// Given: A package configuration.
+	p := linux.Package{ //nolint:exhaustruct
+		Categories: []string{"Game"},
+		Deb: linux.PackageDeb{ //nolint:exhaustruct
+			Depends:    []string{"libc6"},
+			Maintainer: "Jane Doe <jane@example.com>",
+			Section:    "games",
+		},
+		Name:    "game",
+		Version: "1.0.0",
+	}
+
+	// When: The Debian package action is executed.
+	err := linux.NewDebAction(rc, &p, contents, "game.deb").Run(context.Background())
+	require.NoError(t, err)
+
+	// Then: The package is a valid 'ar' archive with the expected members.
+	members := readAr(t, mustReadFile(t, rc.PathOut.Join("game.deb").String()))
+	require.Len(t, members, 3)
+	assert.Equal(t, "2.0\n", string(members["debian-binary"]))
+
+	// Then: The control file contains the package metadata.
+	control := readTarGz(t, members["control.tar.gz"])
+	assert.Equal(t, strings.Join([]string{
+		"Package: game",
+		"Version: 1.0.0",
+		"Architecture: amd64",
+		"Maintainer: Jane Doe <jane@example.com>",
+		"Installed-Size: 1",
+		"Depends: libc6",
+		"Section: games",
+		"Priority: optional",
+		"Description: game",
+		"",
+	}, "\n"), string(control["./control"].data))
+
+	// Then: The data archive installs the artifacts and a launcher.
+	data := readTarGz(t, members["data.tar.gz"])
+	assert.Equal(t, int64(0o755), data["./opt/game/game"].hdr.Mode)
+	assert.Equal(t, int64(0o644), data["./opt/game/game.0.pck"].hdr.Mode)
+	assert.Equal(t, "/opt/game/game", data["./usr/bin/game"].hdr.Linkname)
+	assert.Contains(t, string(data["./usr/share/applications/game.desktop"].data), "Exec=game\n")
+
+	// Then: The intermediate data archive is removed.
+	tmp, err := rc.TempDir()
+	require.NoError(t, err)
+
+	t.Cleanup(func() { os.RemoveAll(tmp) })
+
+	intermediates, err := os.ReadDir(tmp)
+	require.NoError(t, err)
+	assert.Empty(t, intermediates)
+}
+
+/* ---------------------------- Function: helpers --------------------------- */
+
+type tarFile struct {
+	hdr  *tar.Header
+	data []byte
+}
+
+func setupExport(t *testing.T) (*run.Context, linux.PackageContents) {
+	t.Helper()
+
+	out := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(out, "game"), []byte("binary"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(out, "game.0.pck"), []byte("pack"), 0o600))
+
+	rc := run.Context{PathOut: osutil.Path(out)} //nolint:exhaustruct
+
+	return &rc, linux.PackageContents{
+		Arch:       platform.ArchAmd64,
+		Executable: "game",
+		Files:      []string{"game", "game.0.pck"},
+	}
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	return data
+}
+
+func modes(files map[string]tarFile) map[string]int64 {
+	out := make(map[string]int64, len(files))
+	for name, f := range files {
+		out[name] = f.hdr.Mode
+	}
+
+	return out
+}
+
+func readTarGz(t *testing.T, data []byte) map[string]tarFile {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	tr := tar.NewReader(gz)
+	out := map[string]tarFile{}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+
+		contents, err := io.ReadAll(tr)
+		require.NoError(t, err)
+
+		out[hdr.Name] = tarFile{hdr: hdr, data: contents}
+	}
+
+	return out
+}
+
+func readAr(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+
+	require.True(t, bytes.HasPrefix(data, []byte("!<arch>\n")))
+	data = data[8:]
+
+	out := map[string][]byte{}
+
+	for len(data) > 0 {
+		require.GreaterOrEqual(t, len(data), 60)
+
+		name := strings.TrimSpace(string(data[:16]))
+		size, err := strconv.Atoi(strings.TrimSpace(string(data[48:58])))
+		require.NoError(t, err)
+		require.Equal(t, "`\n", string(data[58:60]))
+
+		out[name] = data[60 : 60+size]
+		data = data[60+size+size%2:]
+	}
+
+	return out
+}
@@ -1,40 +1,153 @@
 package linux
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/mitchellh/hashstructure/v2"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
 	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
 	"github.com/coffeebeats/gdbuild/pkg/config/common"
 	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
 	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
 	"github.com/coffeebeats/gdbuild/pkg/godot/template"
 	"github.com/coffeebeats/gdbuild/pkg/run"
 )
 
+var (
+	ErrInvalidInput = errors.New("invalid input")
+	ErrMissingInput = errors.New("missing input")
+)
+
+// debianPackageName matches valid Debian package names; see
+// https://www.debian.org/doc/debian-policy/ch-controlfields.html#source.
+var debianPackageName = regexp.MustCompile(`^[a-z0-9][a-z0-9+.-]+$`)
+
 /* -------------------------------------------------------------------------- */
 /*                                Struct: Target                               */
 /* -------------------------------------------------------------------------- */
 
 type Target struct {
 	*common.Target
+
+	// Package defines which distributable formats the exported target is
+	// packaged into.
+	Package Package `toml:"package"`
 }
 
 /* ----------------------------- Impl: Exporter ----------------------------- */
 
 func (t *Target) Collect(rc *run.Context, tl *template.Template, ev engine.Version) *export.Export {
-	return t.Target.Collect(rc, tl, ev)
+	out := t.Target.Collect(rc, tl, ev)
+
+	if !t.Package.IsEnabled() {
+		return out
+	}
+
+	files := make([]string, 0, len(out.PackFiles))
+	executable := ""
+
+	for i, pf := range out.PackFiles {
+		name := pf.Filename(rc.Platform, rc.Target, i)
+		if config.Dereference(pf.Embed) {
+			executable = name
+		}
+
+		files = append(files, name)
+	}
+
+	if executable == "" {
+		return out
+	}
+
+	if t.Package.PathIcon != "" {
+		out.RegisterDependencyPath(t.Package.PathIcon)
+	}
+
+	p := t.Package
+	if p.Name == "" {
+		p.Name = rc.Target
+	}
+
+	contents := PackageContents{
+		Arch:       out.Arch,
+		Executable: executable,
+		Files:      files,
+	}
+
+	if config.Dereference(p.Tar.Enabled) {
+		name := p.Basename(out.Arch) + ".tar.gz"
+
+		out.ExtraArtifacts = append(out.ExtraArtifacts, name)
+		out.Postexport = action.InOrder(
+			out.Postexport,
+			NewTarAction(rc, &p, contents, name),
+		)
+	}
+
+	if config.Dereference(p.Deb.Enabled) {
+		name := p.DebianFilename(out.Arch)
+
+		out.ExtraArtifacts = append(out.ExtraArtifacts, name)
+		out.Postexport = action.InOrder(
+			out.Postexport,
+			NewDebAction(rc, &p, contents, name),
+		)
+	}
+
+	if config.Dereference(p.AppImage.Enabled) {
+		name := p.Basename(out.Arch) + ".AppImage"
+
+		out.ExtraArtifacts = append(out.ExtraArtifacts, name)
+		out.Postexport = action.InOrder(
+			out.Postexport,
+			NewAppImageAction(rc, &p, contents, name),
+		)
+	}
+
+	return out
 }
 
 /* ------------------------- Impl: config.Configurer ------------------------ */
 
 func (t *Target) Configure(rc *run.Context) error {
-	return t.Target.Configure(rc)
+	if err := t.Target.Configure(rc); err != nil {
+		return err
+	}
+
+	if err := t.Package.Configure(rc); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 /* ------------------------- Impl: config.Validator ------------------------- */
 
 func (t *Target) Validate(rc *run.Context) error {
-	return t.Target.Validate(rc)
+	if err := t.Target.Validate(rc); err != nil {
+		return err
+	}
+
+	if err := t.Package.Validate(rc); err != nil {
+		return err
+	}
+
+	if t.Package.IsEnabled() && !config.Dereference(t.Runnable) {
+		return fmt.Errorf(
+			"%w: cannot package a non-runnable target",
+			ErrInvalidInput,
+		)
+	}
+
+	return nil
 }
 
 /* --------------------------- Impl: config.Merger -------------------------- */
@@ -57,6 +170,182 @@ func (t *Target) MergeInto(other any) error {
 	return config.Merge(dst, *t)
 }
 
+/* -------------------------------------------------------------------------- */
+/*                               Struct: Package                              */
+/* -------------------------------------------------------------------------- */
+
+// Package defines the distributable formats into which an exported, runnable
+// Linux target is packaged. Each enabled format is written to the output
+// directory alongside the exported artifacts.
+type Package struct {
+	// AppImage configures packaging as an AppImage.
+	AppImage PackageAppImage `toml:"appimage"`
+
+	// Categories is a list of freedesktop.org menu categories used in the
+	// generated desktop entry. Defaults to ["Game"].
+	Categories []string `toml:"categories"`
+
+	// Deb configures packaging as a Debian package.
+	Deb PackageDeb `toml:"deb"`
+
+	// Description is a short description of the application.
+	Description string `toml:"description"`
+
+	// Name is the name of the package, used for the installed executable and
+	// the package filenames. Defaults to the name of the target.
+	Name string `toml:"name"`
+
+	// PathIcon is a path to a PNG application icon.
+	PathIcon osutil.Path `toml:"icon"`
+
+	// Tar configures packaging as a gzip-compressed tarball.
+	Tar PackageTar `toml:"tar"`
+
+	// Title is the human-readable application name shown in desktop menus.
+	// Defaults to 'Name'.
+	Title string `toml:"title"`
+
+	// Version is the version of the packaged application. Required when
+	// building a Debian package.
+	Version string `toml:"version"`
+}
+
+// PackageAppImage configures packaging as an AppImage. Building an AppImage
+// requires 'appimagetool' to be installed.
+type PackageAppImage struct {
+	// Command contains arguments used to invoke 'appimagetool'. Defaults to
+	// ["appimagetool"].
+	Command []string `toml:"command"`
+
+	// Enabled determines whether an AppImage is built.
+	Enabled *bool `toml:"enabled"`
+}
+
+// PackageDeb configures packaging as a Debian package.
+type PackageDeb struct {
+	// Depends is a list of package dependencies (e.g. "libc6 (>= 2.28)").
+	Depends []string `toml:"depends"`
+
+	// Enabled determines whether a Debian package is built.
+	Enabled *bool `toml:"enabled"`
+
+	// Maintainer is the package maintainer (e.g. "Name <email>").
+	Maintainer string `toml:"maintainer"`
+
+	// Section is the archive section of the package. Defaults to "games".
+	Section string `toml:"section"`
+}
+
+// PackageTar configures packaging as a gzip-compressed tarball.
+type PackageTar struct {
+	// Enabled determines whether a tarball is built.
+	Enabled *bool `toml:"enabled"`
+}
+
+/* ---------------------------- Method: IsEnabled --------------------------- */
+
+// IsEnabled returns whether any package format is enabled.
+func (p *Package) IsEnabled() bool {
+	return config.Dereference(p.AppImage.Enabled) ||
+		config.Dereference(p.Deb.Enabled) ||
+		config.Dereference(p.Tar.Enabled)
+}
+
+/* ---------------------------- Method: Basename ---------------------------- */
+
+// Basename returns the extension-less filename used for package artifacts.
+func (p *Package) Basename(arch platform.Arch) string {
+	name := p.Name
+	if p.Version != "" {
+		name += "-" + p.Version
+	}
+
+	return name + "-" + arch.String()
+}
+
+/* ------------------------- Method: DebianFilename ------------------------- */
+
+// DebianFilename returns the conventional filename of the Debian package.
+func (p *Package) DebianFilename(arch platform.Arch) string {
+	return p.Name + "_" + p.Version + "_" + debianArch(arch) + ".deb"
+}
+
+/* ---------------------------- Method: Checksum ---------------------------- */
+
+// Checksum returns a hash of the package configuration. This is used so that
+// changes to the package metadata are reflected in the export checksum.
+func (p *Package) Checksum() string {
+	hash, err := hashstructure.Hash(p, hashstructure.FormatV2, nil)
+	if err != nil {
+		return ""
+	}
+
+	return strconv.FormatUint(hash, 16)
+}
+
+/* ------------------------- Impl: config.Configurer ------------------------ */
+
+func (p *Package) Configure(rc *run.Context) error {
+	if err := p.PathIcon.RelTo(rc.PathManifest); err != nil {
+		return err
+	}
+
+	if len(p.Categories) == 0 {
+		p.Categories = []string{"Game"}
+	}
+
+	if p.Deb.Section == "" {
+		p.Deb.Section = "games"
+	}
+
+	return nil
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (p *Package) Validate(rc *run.Context) error {
+	if !p.IsEnabled() {
+		return nil
+	}
+
+	if err := p.PathIcon.CheckIsFileOrEmpty(); err != nil {
+		return err
+	}
+
+	if p.Name != "" && (p.Name != filepath.Base(p.Name) || strings.ContainsAny(p.Name, " \t")) {
+		return fmt.Errorf("%w: invalid package name: %s", ErrInvalidInput, p.Name)
+	}
+
+	if config.Dereference(p.AppImage.Enabled) && p.PathIcon == "" {
+		return fmt.Errorf("%w: AppImage requires 'package.icon'", ErrMissingInput)
+	}
+
+	if config.Dereference(p.Deb.Enabled) {
+		name := p.Name
+		if name == "" {
+			name = rc.Target
+		}
+
+		if !debianPackageName.MatchString(name) {
+			return fmt.Errorf(
+				"%w: invalid Debian package name: %s",
+				ErrInvalidInput,
+				name,
+			)
+		}
+
+		if p.Version == "" {
+			return fmt.Errorf("%w: Debian package requires 'package.version'", ErrMissingInput)
+		}
+
+		if p.Deb.Maintainer == "" {
+			return fmt.Errorf("%w: Debian package requires 'package.deb.maintainer'", ErrMissingInput)
+		}
+	}
+
+	return nil
+}
+
 /* -------------------------------------------------------------------------- */
 /*                    Struct: TargetWithFeaturesAndProfile                    */
 /* -------------------------------------------------------------------------- */
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
 	"github.com/coffeebeats/gdbuild/pkg/config/common"
 	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
 	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
@@ -18,6 +19,9 @@ import (
 type Template struct {
 	*common.Template
 
+	// Toolchain defines settings for cross-compiling the export template.
+	Toolchain Toolchain `toml:"toolchain"`
+
 	// UseLLVM determines whether the LLVM compiler is used.
 	UseLLVM *bool `toml:"use_llvm"`
 }
@@ -41,6 +45,8 @@ func (t *Template) Collect(g engine.Source, rc *run.Context) *template.Template
 		scons.ExtraArgs = append(scons.ExtraArgs, "lto=full")
 	}
 
+	t.Toolchain.apply(scons, config.Dereference(t.UseLLVM))
+
 	return out
 }
 
@@ -51,6 +57,10 @@ func (t *Template) Configure(rc *run.Context) error {
 		return err
 	}
 
+	if err := t.Toolchain.Configure(rc); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -61,15 +71,18 @@ func (t *Template) Validate(rc *run.Context) error {
 		return err
 	}
 
-	if !t.Arch.IsOneOf(platform.ArchI386, platform.ArchAmd64, platform.ArchUnknown) {
+	if !t.Arch.IsOneOf(
+		platform.ArchAmd64,
+		platform.ArchArm32,
+		platform.ArchArm64,
+		platform.ArchI386,
+		platform.ArchUnknown,
+	) {
 		return fmt.Errorf("%w: unsupport architecture: %s", config.ErrInvalidInput, t.Arch)
 	}
 
-	switch t.Arch {
-	case platform.ArchI386, platform.ArchAmd64:
-	case platform.ArchUnknown:
-	default:
-		return fmt.Errorf("%w: unsupport architecture: %s", config.ErrInvalidInput, t.Arch)
+	if err := t.Toolchain.Validate(rc); err != nil {
+		return err
 	}
 
 	return nil
@@ -95,6 +108,68 @@ func (t *Template) MergeInto(other any) error {
 	return config.Merge(dst, *t)
 }
 
+/* -------------------------------------------------------------------------- */
+/*                              Struct: Toolchain                             */
+/* -------------------------------------------------------------------------- */
+
+// Toolchain defines the settings required to cross-compile a Linux export
+// template (e.g. for 'arm64' from an 'x86_64' host).
+type Toolchain struct {
+	// CompilerPrefix is a prefix prepended to the compiler commands (e.g.
+	// 'aarch64-linux-gnu-' results in 'aarch64-linux-gnu-gcc').
+	CompilerPrefix string `toml:"compiler_prefix"`
+
+	// PathSysroot is a path to the target system's root directory, which is
+	// used to locate headers and libraries.
+	PathSysroot osutil.Path `toml:"sysroot"`
+}
+
+/* ------------------------------ Method: apply ----------------------------- */
+
+func (c *Toolchain) apply(scons *template.SCons, useLLVM bool) {
+	if c.CompilerPrefix != "" {
+		cc, cxx := "gcc", "g++"
+		if useLLVM {
+			cc, cxx = "clang", "clang++"
+		}
+
+		scons.ExtraArgs = append(
+			scons.ExtraArgs,
+			"CC="+c.CompilerPrefix+cc,
+			"CXX="+c.CompilerPrefix+cxx,
+		)
+	}
+
+	if c.PathSysroot != "" {
+		flag := "--sysroot=" + c.PathSysroot.String()
+
+		scons.CCFlags = append(scons.CCFlags, flag)
+		scons.LinkFlags = append(scons.LinkFlags, flag)
+	}
+}
+
+/* ------------------------- Impl: config.Configurer ------------------------ */
+
+func (c *Toolchain) Configure(rc *run.Context) error {
+	if err := c.PathSysroot.RelTo(rc.PathManifest); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (c *Toolchain) Validate(_ *run.Context) error {
+	if err := c.PathSysroot.CheckIsDirOrEmpty(); err != nil {
+		return fmt.Errorf("%w: invalid sysroot path", err)
+	}
+
+	// NOTE: Don't check for the compiler, that should be a runtime check.
+
+	return nil
+}
+
 /* -------------------------------------------------------------------------- */
 /*                   Struct: TemplateWithFeaturesAndProfile                   */
 /* -------------------------------------------------------------------------- */
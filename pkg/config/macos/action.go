@@ -7,9 +7,12 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/internal/exec"
 	"github.com/coffeebeats/gdbuild/internal/osutil"
 	"github.com/coffeebeats/gdbuild/pkg/run"
 )
@@ -79,6 +82,70 @@ func NewAppBundleAction(
 	}
 }
 
+/* -------------------------------------------------------------------------- */
+/*                           Function: NewSignAction                          */
+/* -------------------------------------------------------------------------- */
+
+// NewSignAction creates an 'action.Action' which code signs the exported app
+// bundle 'name' (relative to the output directory) using 'rcodesign'. If no
+// certificate is configured, an ad-hoc signature is applied.
+//
+// NOTE: The signing command contains absolute paths which differ across
+// machines, so it's built when the action is run rather than being included in
+// the export checksum. Signing inputs are instead hashed via the target's
+// dependency paths and 'Sign.Fingerprint'.
+func NewSignAction(rc *run.Context, s *Sign, name string) action.WithDescription[action.Function] {
+	fn := func(ctx context.Context) error {
+		// NOTE: Run the command directly so that arguments (e.g. paths
+		// containing spaces) aren't interpreted by a shell.
+		process := &action.Process{
+			Directory:   rc.PathOut.String(),
+			Environment: nil,
+
+			Shell:   exec.ShellNone,
+			Verbose: rc.Verbose,
+
+			Args: signCommand(s, name),
+		}
+
+		return process.Run(ctx)
+	}
+
+	return action.WithDescription[action.Function]{
+		Action:      fn,
+		Description: "sign application bundle with 'rcodesign': " + name,
+	}
+}
+
+/* -------------------------- Function: signCommand ------------------------- */
+
+func signCommand(s *Sign, name string) []string {
+	cmd := slices.Clone(s.Command)
+	if len(cmd) == 0 {
+		cmd = append(cmd, "rcodesign")
+	}
+
+	cmd = append(cmd, "sign")
+
+	if s.PathCertificate != "" {
+		cmd = append(
+			cmd,
+			"--pem-file", s.PathCertificate.String(),
+			"--pem-file", s.PathKey.String(),
+		)
+	}
+
+	if s.PathEntitlements != "" {
+		cmd = append(cmd, "--entitlements-xml-file", s.PathEntitlements.String())
+	}
+
+	if config.Dereference(s.HardenedRuntime) {
+		cmd = append(cmd, "--code-signature-flags", "runtime")
+	}
+
+	return append(cmd, strings.TrimSuffix(name, "/"))
+}
+
 /* ------------------------- Function: zipAppBundle ------------------------- */
 
 func zipAppBundle(pathAppBundle, out osutil.Path) error {
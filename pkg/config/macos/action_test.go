@@ -0,0 +1,119 @@
+package macos_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/config/macos"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+func TestNewSignAction(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires a POSIX shell")
+	}
+
+	tests := []struct {
+		name string
+
+		sign macos.Sign
+
+		want []string
+	}{
+		{
+			name: "no certificate results in ad-hoc signature",
+
+			sign: macos.Sign{},
+
+			want: []string{"sign", "game.app"},
+		},
+		{
+			name: "certificate, entitlements, and hardened runtime are set",
+
+			sign: macos.Sign{
+				HardenedRuntime:  pointer(true),
+				PathCertificate:  "/my certs/cert.pem",
+				PathEntitlements: "/my certs/app.entitlements",
+				PathKey:          "/my certs/key.pem",
+			},
+
+			want: []string{
+				"sign",
+				"--pem-file", "/my certs/cert.pem",
+				"--pem-file", "/my certs/key.pem",
+				"--entitlements-xml-file", "/my certs/app.entitlements",
+				"--code-signature-flags", "runtime",
+				"game.app",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: An output directory, containing a space.
+			pathOut := filepath.Join(t.TempDir(), "out dir")
+			require.NoError(t, os.MkdirAll(pathOut, 0o750))
+
+			// Given: A fake signing tool which records its arguments and
+			// working directory.
+			pathArgs := filepath.Join(t.TempDir(), "args.txt")
+			pathTool := filepath.Join(t.TempDir(), "rcodesign")
+
+			script := "#!/bin/sh\n{ pwd; printf '%s\\n' \"$@\"; } > '" + pathArgs + "'\n"
+			require.NoError(t, os.WriteFile(pathTool, []byte(script), 0o700)) //nolint:gosec
+
+			tc.sign.Command = []string{pathTool}
+
+			rc := run.Context{PathOut: osutil.Path(pathOut)} //nolint:exhaustruct
+
+			// When: The app bundle is signed.
+			err := macos.NewSignAction(&rc, &tc.sign, "game.app/").Run(context.Background())
+
+			// Then: There's no error.
+			require.NoError(t, err)
+
+			// Then: The command is run in the output directory with arguments
+			// passed through unchanged.
+			got, err := os.ReadFile(pathArgs)
+			require.NoError(t, err)
+
+			lines := strings.Split(strings.TrimSpace(string(got)), "\n")
+
+			wd, err := filepath.EvalSymlinks(pathOut)
+			require.NoError(t, err)
+
+			assert.Equal(t, wd, lines[0])
+			assert.Equal(t, tc.want, lines[1:])
+		})
+	}
+}
+
+func TestSignFingerprint(t *testing.T) {
+	// Given: A signing configuration.
+	sign := macos.Sign{PathCertificate: "/a/cert.pem", PathKey: "/a/key.pem"} //nolint:exhaustruct
+
+	// Given: The same configuration with the files at different locations.
+	moved := macos.Sign{PathCertificate: "/b/cert.pem", PathKey: "/b/key.pem"} //nolint:exhaustruct
+
+	// Given: The same configuration with the hardened runtime enabled.
+	hardened := sign
+	hardened.HardenedRuntime = pointer(true)
+
+	// Then: The fingerprint doesn't depend on the files' locations.
+	assert.Equal(t, sign.Fingerprint(), moved.Fingerprint())
+
+	// Then: The fingerprint depends on the signature flags.
+	assert.NotEqual(t, sign.Fingerprint(), hardened.Fingerprint())
+}
+
+func pointer[T any](value T) *T {
+	return &value
+}
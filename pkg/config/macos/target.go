@@ -1,10 +1,14 @@
 package macos
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 
+	"github.com/coffeebeats/gdbuild/internal/action"
 	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
 	"github.com/coffeebeats/gdbuild/pkg/config/common"
 	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
 	"github.com/coffeebeats/gdbuild/pkg/godot/export"
@@ -23,6 +27,9 @@ type Target struct {
 
 	// PathIcon is a path to a Windows application icon.
 	BundleIdentifier string `toml:"bundle_identifier"`
+
+	// Sign defines how the exported application bundle is code signed.
+	Sign Sign `toml:"sign"`
 }
 
 /* ----------------------------- Impl: Exporter ----------------------------- */
@@ -36,13 +43,47 @@ func (t *Target) Collect(rc *run.Context, tl *template.Template, ev engine.Versi
 
 	out.Options["application/bundle_identifier"] = t.BundleIdentifier
 
+	if !config.Dereference(t.Sign.Enabled) {
+		return out
+	}
+
+	for i, pf := range out.PackFiles {
+		if !config.Dereference(pf.Embed) {
+			continue
+		}
+
+		if t.Sign.PathCertificate != "" {
+			out.RegisterDependencyPath(t.Sign.PathCertificate)
+		}
+
+		if t.Sign.PathEntitlements != "" {
+			out.RegisterDependencyPath(t.Sign.PathEntitlements)
+		}
+
+		out.SigningFingerprint = t.Sign.Fingerprint()
+		out.Postexport = action.InOrder(
+			out.Postexport,
+			NewSignAction(rc, &t.Sign, pf.Filename(rc.Platform, rc.Target, i)),
+		)
+
+		break
+	}
+
 	return out
 }
 
 /* ------------------------- Impl: config.Configurer ------------------------ */
 
 func (t *Target) Configure(rc *run.Context) error {
-	return t.Target.Configure(rc)
+	if err := t.Target.Configure(rc); err != nil {
+		return err
+	}
+
+	if err := t.Sign.Configure(rc); err != nil {
+		return err
+	}
+
+	return nil
 }
 
 /* ------------------------- Impl: config.Validator ------------------------- */
@@ -56,6 +97,25 @@ func (t *Target) Validate(rc *run.Context) error {
 		return fmt.Errorf("%w: 'bundle_identifier'", ErrMissingInput)
 	}
 
+	if err := t.Sign.Validate(rc); err != nil {
+		return err
+	}
+
+	if config.Dereference(t.Sign.Enabled) {
+		hasEmbed := false
+
+		for _, pf := range t.PackFiles {
+			hasEmbed = hasEmbed || config.Dereference(pf.Embed)
+		}
+
+		if !hasEmbed {
+			return fmt.Errorf(
+				"%w: code signing requires an embedded pack file",
+				config.ErrInvalidInput,
+			)
+		}
+	}
+
 	return nil
 }
 
@@ -79,6 +139,98 @@ func (t *Target) MergeInto(other any) error {
 	return config.Merge(dst, *t)
 }
 
+/* -------------------------------------------------------------------------- */
+/*                                Struct: Sign                                */
+/* -------------------------------------------------------------------------- */
+
+// Sign defines the code signing settings for an exported macOS application
+// bundle. Signing is performed by 'rcodesign', which runs on any host platform.
+// If no certificate is configured, an ad-hoc signature is applied instead.
+type Sign struct {
+	// Command contains arguments used to invoke 'rcodesign'. Defaults to
+	// ["rcodesign"].
+	Command []string `toml:"command"`
+
+	// Enabled determines whether the exported application bundle is signed.
+	Enabled *bool `toml:"enabled"`
+
+	// HardenedRuntime determines whether the hardened runtime is enabled. This
+	// is required for notarization.
+	HardenedRuntime *bool `toml:"hardened_runtime"`
+
+	// PathCertificate is a path to a PEM-encoded signing certificate (e.g. a
+	// 'Developer ID Application' certificate).
+	PathCertificate osutil.Path `toml:"certificate"`
+
+	// PathEntitlements is a path to an entitlements plist file.
+	PathEntitlements osutil.Path `toml:"entitlements"`
+
+	// PathKey is a path to the PEM-encoded private key for the certificate.
+	PathKey osutil.Path `toml:"key"`
+}
+
+/* --------------------------- Method: Fingerprint -------------------------- */
+
+// Fingerprint returns a SHA-256 fingerprint of the signing settings which
+// aren't otherwise included in the export checksum (i.e. the command and
+// signature flags). Note that the contents of the certificate and entitlements
+// files are hashed as dependencies of the export.
+func (s *Sign) Fingerprint() string {
+	h := sha256.New()
+
+	fmt.Fprintf(
+		h,
+		"%q,%t,%t,%t",
+		s.Command,
+		config.Dereference(s.HardenedRuntime),
+		s.PathCertificate != "",
+		s.PathEntitlements != "",
+	)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+/* ------------------------- Impl: config.Configurer ------------------------ */
+
+func (s *Sign) Configure(rc *run.Context) error {
+	for _, path := range []*osutil.Path{
+		&s.PathCertificate,
+		&s.PathEntitlements,
+		&s.PathKey,
+	} {
+		if err := path.RelTo(rc.PathManifest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (s *Sign) Validate(_ *run.Context) error {
+	if (s.PathCertificate == "") != (s.PathKey == "") {
+		return fmt.Errorf(
+			"%w: both 'sign.certificate' and 'sign.key' must be set",
+			ErrMissingInput,
+		)
+	}
+
+	for _, path := range []osutil.Path{
+		s.PathCertificate,
+		s.PathEntitlements,
+		s.PathKey,
+	} {
+		if err := path.CheckIsFileOrEmpty(); err != nil {
+			return err
+		}
+	}
+
+	// NOTE: Don't check for 'rcodesign', that should be a runtime check.
+
+	return nil
+}
+
 /* -------------------------------------------------------------------------- */
 /*                    Struct: TargetWithFeaturesAndProfile                    */
 /* -------------------------------------------------------------------------- */
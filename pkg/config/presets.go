@@ -0,0 +1,312 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/exp/maps"
+
+	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+)
+
+// invalidTargetNameChars matches characters which aren't used in generated
+// target names.
+var invalidTargetNameChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+/* -------------------------------------------------------------------------- */
+/*                            Function: FromPresets                           */
+/* -------------------------------------------------------------------------- */
+
+// FromPresets generates the '[target.*]' tables of a GDBuild manifest from the
+// contents of an 'export_presets.cfg' file. Each preset is translated into a
+// target named after it, with its platform-specific settings defined in the
+// target's 'platform.<os>' section.
+func FromPresets(cfg string) (string, error) {
+	presets, err := export.ParsePresets(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+
+	names := make(map[string]struct{}, len(presets))
+
+	for i, p := range presets {
+		name := targetNameFromPreset(p.Name, i)
+
+		// Ensure each target name is unique.
+		for j := 2; ; j++ {
+			if _, ok := names[name]; !ok {
+				break
+			}
+
+			name = targetNameFromPreset(p.Name, i) + "_" + strconv.Itoa(j)
+		}
+
+		names[name] = struct{}{}
+
+		writeTargetFromPreset(&out, name, &p)
+	}
+
+	return out.String(), nil
+}
+
+/* -------------------- Function: writeTargetFromPreset -------------------- */
+
+func writeTargetFromPreset(out *strings.Builder, name string, p *export.Preset) { //nolint:funlen
+	section := "target." + name
+
+	out.WriteString("\n[" + section + "]\n")
+
+	if p.Name != "" {
+		out.WriteString("  # Generated from the '" + p.Name + "' preset.\n")
+	}
+
+	writeTOMLValue(out, "default_features", p.Features)
+
+	// NOTE: Runnable targets must embed their pack file in 'gdbuild'.
+	isRunnable := p.Runnable || p.Embed
+
+	if isRunnable {
+		writeTOMLValue(out, "runnable", true)
+	}
+
+	if p.Server {
+		writeTOMLValue(out, "server", true)
+	}
+
+	if p.Encrypt {
+		writeTOMLValue(out, "encrypt", true)
+	}
+
+	var include, exclude []string
+
+	switch p.ExportMode {
+	case "resources", "scenes":
+		// NOTE: Godot exports the dependencies of the selected files, too, but
+		// these can't be determined without the editor.
+		log.Warnf(
+			"preset '%s': only the selected files are included; add any of their dependencies to 'include'",
+			p.Name,
+		)
+
+		include = append(include, trimResourcePaths(p.ExportedFiles)...)
+	case "exclude":
+		include = append(include, "*")
+		exclude = append(exclude, trimResourcePaths(p.ExportedFiles)...)
+	case export.ModeCustomized:
+		files := maps.Keys(p.CustomizedFiles)
+		slices.Sort(files)
+
+		include = append(include, trimResourcePaths(files)...)
+	default: // "all_resources"
+		include = append(include, "*")
+	}
+
+	include = append(include, globsFromFilter(p.Include)...)
+	exclude = append(exclude, globsFromFilter(p.Exclude)...)
+
+	if p.Encrypt && len(p.Encrypted) > 0 && !slices.Equal(p.Encrypted, []string{"*"}) {
+		log.Warnf(
+			"preset '%s': encryption filters aren't supported; all files in the pack file will be encrypted",
+			p.Name,
+		)
+	}
+
+	var packFile strings.Builder
+
+	packFile.WriteString("{include = " + tomlValue(include))
+
+	if len(exclude) > 0 {
+		packFile.WriteString(", exclude = " + tomlValue(exclude))
+	}
+
+	if isRunnable {
+		packFile.WriteString(", embed = true")
+	}
+
+	if p.Encrypt {
+		packFile.WriteString(", encrypt = true")
+	}
+
+	if p.ExportMode == export.ModeCustomized && slices.Contains(
+		maps.Values(p.CustomizedFiles),
+		export.FileVisualModeStrip,
+	) {
+		packFile.WriteString(", visuals = false")
+	}
+
+	packFile.WriteString("}")
+
+	section += ".platform." + platformSectionName(p.Platform)
+
+	out.WriteString("\n[" + section + "]\n")
+	out.WriteString("  pack_files = [" + packFile.String() + "]\n")
+
+	if len(p.Options) == 0 {
+		return
+	}
+
+	out.WriteString("\n[" + section + ".options]\n")
+
+	keys := maps.Keys(p.Options)
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		writeTOMLValue(out, tomlString(key), p.Options[key])
+	}
+}
+
+/* --------------------- Function: platformSectionName ---------------------- */
+
+// platformSectionName returns the name of the 'TargetPlatforms' section for the
+// specified platform.
+func platformSectionName(pl platform.OS) string {
+	if pl == platform.OSLinux {
+		return "linux"
+	}
+
+	return pl.String()
+}
+
+/* --------------------- Function: targetNameFromPreset --------------------- */
+
+func targetNameFromPreset(name string, index int) string {
+	name = invalidTargetNameChars.ReplaceAllString(strings.ToLower(name), "_")
+	name = strings.Trim(name, "_-")
+
+	if name == "" {
+		return "preset_" + strconv.Itoa(index)
+	}
+
+	return name
+}
+
+/* ---------------------- Function: trimResourcePaths ----------------------- */
+
+func trimResourcePaths(paths []string) []string {
+	out := make([]string, 0, len(paths))
+
+	for _, p := range paths {
+		out = append(out, strings.TrimPrefix(p, "res://"))
+	}
+
+	return out
+}
+
+/* ----------------------- Function: globsFromFilter ------------------------ */
+
+// globsFromFilter translates a comma-separated Godot export filter into glob
+// expressions. Godot matches filters without a directory against files in any
+// directory, so these are prefixed with '**/'.
+func globsFromFilter(filter string) []string {
+	var out []string
+
+	for _, f := range strings.Split(filter, ",") {
+		f = strings.TrimPrefix(strings.TrimSpace(f), "res://")
+		if f == "" {
+			continue
+		}
+
+		if !strings.Contains(f, "/") {
+			f = "**/" + f
+		}
+
+		out = append(out, f)
+	}
+
+	return out
+}
+
+/* ------------------------ Function: writeTOMLValue ------------------------ */
+
+func writeTOMLValue(out *strings.Builder, key string, value any) {
+	if ss, ok := value.([]string); ok && len(ss) == 0 {
+		return
+	}
+
+	out.WriteString("  " + key + " = " + tomlValue(value) + "\n")
+}
+
+/* --------------------------- Function: tomlValue -------------------------- */
+
+func tomlValue(value any) string {
+	switch value := value.(type) {
+	case string:
+		return tomlString(value)
+	case []string:
+		elements := make([]string, len(value))
+		for i, s := range value {
+			elements[i] = tomlString(s)
+		}
+
+		return "[" + strings.Join(elements, ", ") + "]"
+	case []any:
+		elements := make([]string, len(value))
+		for i, e := range value {
+			elements[i] = tomlValue(e)
+		}
+
+		return "[" + strings.Join(elements, ", ") + "]"
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+
+		slices.Sort(keys)
+
+		elements := make([]string, len(keys))
+		for i, k := range keys {
+			elements[i] = tomlString(k) + " = " + tomlValue(value[k])
+		}
+
+		return "{" + strings.Join(elements, ", ") + "}"
+	case float64:
+		// NOTE: Whole numbers need a decimal point to be parsed as floats.
+		s := strconv.FormatFloat(value, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eIN") {
+			s += ".0"
+		}
+
+		return s
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+/* -------------------------- Function: tomlString -------------------------- */
+
+// tomlString encodes 's' as a TOML basic string.
+func tomlString(s string) string {
+	var out strings.Builder
+
+	out.WriteByte('"')
+
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			out.WriteString(`\` + string(r))
+		case r == '\n':
+			out.WriteString(`\n`)
+		case r == '\t':
+			out.WriteString(`\t`)
+		case r == '\r':
+			out.WriteString(`\r`)
+		case unicode.IsControl(r):
+			fmt.Fprintf(&out, `\u%04X`, r)
+		default:
+			out.WriteRune(r)
+		}
+	}
+
+	out.WriteByte('"')
+
+	return out.String()
+}
@@ -0,0 +1,102 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/pkg/config"
+	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+)
+
+func TestFromPresets(t *testing.T) {
+	// Given: An 'export_presets.cfg' file written by the Godot editor.
+	cfg := `[preset.0]
+
+name="Windows Desktop"
+platform="Windows Desktop"
+runnable=true
+custom_features="demo,steam"
+export_filter="all_resources"
+include_filter="*.json"
+exclude_filter="tests/*"
+encrypt_pck=true
+encrypt_directory=true
+encryption_include_filters="*"
+
+[preset.0.options]
+
+custom_template/release="C:/templates/windows.exe"
+binary_format/embed_pck=true
+application/product_name="My \"Game\""
+codesign/timestamp=true
+application/icon_interpolation=4
+application/file_version={
+"major": 1,
+"labels": ["beta", "rc"]
+}
+
+[preset.1]
+
+name="DLC"
+platform="Linux"
+runnable=false
+export_filter="resources"
+export_files=PackedStringArray("res://dlc/level.tscn", "res://dlc/music.ogg")
+
+[preset.1.options]
+`
+
+	// When: A manifest is generated from the presets.
+	got, err := config.FromPresets(cfg)
+
+	// Then: There's no error.
+	require.NoError(t, err)
+
+	// Then: The manifest can be parsed.
+	m, err := config.Parse([]byte(got))
+	require.NoError(t, err)
+
+	// Then: The Windows preset is translated into a runnable target.
+	client := m.Target["windows_desktop"]
+	require.NotNil(t, client.TargetWithFeaturesAndProfile)
+	require.NotNil(t, client.Target)
+
+	assert.Equal(t, []string{"demo", "steam"}, client.DefaultFeatures)
+	assert.True(t, *client.Runnable)
+	assert.True(t, *client.Encrypt)
+
+	windows := client.Platform.Windows
+	require.NotNil(t, windows.Target)
+	require.NotNil(t, windows.Target.Target)
+	require.Len(t, windows.PackFiles, 1)
+
+	pf := windows.PackFiles[0]
+	assert.Equal(t, []string{"*", "**/*.json"}, pf.Include)
+	assert.Equal(t, []string{"tests/*"}, pf.Exclude)
+	assert.True(t, *pf.Embed)
+	assert.True(t, *pf.Encrypt)
+
+	assert.Equal(t, map[string]any{
+		"application/product_name":       `My "Game"`,
+		"application/icon_interpolation": int64(4),
+		"codesign/timestamp":             true,
+		"application/file_version": map[string]any{
+			"labels": []any{"beta", "rc"},
+			"major":  int64(1),
+		},
+	}, windows.Options)
+
+	// Then: The Linux preset is translated into a non-runnable target.
+	dlc := m.Target["dlc"]
+	assert.Nil(t, dlc.TargetWithFeaturesAndProfile)
+
+	linux := dlc.Platform.Linux
+	require.NotNil(t, linux.Target)
+	require.NotNil(t, linux.Target.Target)
+
+	assert.Equal(t, []export.PackFile{
+		{Include: []string{"dlc/level.tscn", "dlc/music.ogg"}}, //nolint:exhaustruct
+	}, linux.PackFiles)
+}
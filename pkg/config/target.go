@@ -1,14 +1,18 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
 	"github.com/coffeebeats/gdbuild/internal/config"
 	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/config/android"
 	"github.com/coffeebeats/gdbuild/pkg/config/common"
+	"github.com/coffeebeats/gdbuild/pkg/config/ios"
 	"github.com/coffeebeats/gdbuild/pkg/config/linux"
 	"github.com/coffeebeats/gdbuild/pkg/config/macos"
+	"github.com/coffeebeats/gdbuild/pkg/config/web"
 	"github.com/coffeebeats/gdbuild/pkg/config/windows"
 	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
 	"github.com/coffeebeats/gdbuild/pkg/godot/export"
@@ -24,6 +28,7 @@ import (
 // Export creates an `Export` instance which contains an action for exporting
 // the specified target.
 func Export( //nolint:cyclop,funlen,gocognit
+	ctx context.Context,
 	rc *run.Context,
 	m *Manifest,
 	tl *template.Template,
@@ -113,10 +118,23 @@ func Export( //nolint:cyclop,funlen,gocognit
 		return nil, fmt.Errorf("%w: no target found: %s", ErrInvalidInput, target)
 	}
 
+	// NOTE: The target's encryption key must be known prior to validation.
+	if err := mr.target.ResolveEncryptionKey(ctx, rc); err != nil {
+		return nil, err
+	}
+
 	if err := mr.Validate(rc); err != nil {
 		return nil, err
 	}
 
+	if err := mr.target.PatchPackFiles(rc); err != nil {
+		return nil, err
+	}
+
+	if err := mr.target.PartitionPackFiles(rc); err != nil {
+		return nil, err
+	}
+
 	ev, err := mr.godot.ParseVersion()
 	if err != nil {
 		if errors.Is(err, ErrConflictingValue) {
@@ -126,11 +144,19 @@ func Export( //nolint:cyclop,funlen,gocognit
 		return nil, err
 	}
 
+	if rc.Project != nil {
+		if err := rc.Project.CheckEditorVersion(ev); err != nil {
+			return nil, err
+		}
+	}
+
 	xp := mr.target.Collect(rc, tl, ev)
 
 	// Set the encryption key on the template builds in the event that the key
-	// was just set on the target. This is the only property that needs to be
-	// synchronized between the target/template builds, so do it here.
+	// was just set on the target (e.g. via a target-specific key source). This
+	// is the only property that needs to be synchronized between the target/
+	// template builds, so do it here. Because the key is part of the template's
+	// checksum, templates for each key are cached separately in the store.
 	for i, tb := range tl.Builds {
 		if tb.EncryptionKey != "" && xp.EncryptionKey == "" {
 			return nil, fmt.Errorf(
@@ -182,6 +208,9 @@ type Exporter interface {
 	config.Configurable[*run.Context]
 
 	Collect(rc *run.Context, tl *template.Template, ev engine.Version) *export.Export
+	PartitionPackFiles(rc *run.Context) error
+	PatchPackFiles(rc *run.Context) error
+	ResolveEncryptionKey(ctx context.Context, rc *run.Context) error
 }
 
 /* -------------------------------------------------------------------------- */
@@ -218,8 +247,11 @@ type BaseTargetWithoutFeature struct {
 /* ---------------------------- Struct: Platforms --------------------------- */
 
 type TargetPlatforms struct {
+	Android android.TargetWithFeaturesAndProfile `toml:"android"`
+	IOS     ios.TargetWithFeaturesAndProfile     `toml:"ios"`
 	Linux   linux.TargetWithFeaturesAndProfile   `toml:"linux"`
 	MacOS   macos.TargetWithFeaturesAndProfile   `toml:"macos"`
+	Web     web.TargetWithFeaturesAndProfile     `toml:"web"`
 	Windows windows.TargetWithFeaturesAndProfile `toml:"windows"`
 }
 
@@ -231,8 +263,11 @@ type TargetBuilder[T Exporter] interface {
 
 // Compile-time check that 'Builder' is implemented.
 var _ TargetBuilder[*common.Target] = (*common.TargetWithFeaturesAndProfile)(nil)
+var _ TargetBuilder[*android.Target] = (*android.TargetWithFeaturesAndProfile)(nil)
+var _ TargetBuilder[*ios.Target] = (*ios.TargetWithFeaturesAndProfile)(nil)
 var _ TargetBuilder[*linux.Target] = (*linux.TargetWithFeaturesAndProfile)(nil)
 var _ TargetBuilder[*macos.Target] = (*macos.TargetWithFeaturesAndProfile)(nil)
+var _ TargetBuilder[*web.Target] = (*web.TargetWithFeaturesAndProfile)(nil)
 var _ TargetBuilder[*windows.Target] = (*windows.TargetWithFeaturesAndProfile)(nil)
 
 /* ----------------------------- Method: Combine ---------------------------- */
@@ -246,6 +281,22 @@ func (t Targets) Combine(rc *run.Context) (Exporter, error) { //nolint:dupl,iret
 	}
 
 	switch p := rc.Platform; p {
+	case platform.OSAndroid:
+		out := &android.Target{Target: base} //nolint:exhaustruct
+
+		if err := t.Platform.Android.Build(rc, out); err != nil {
+			return nil, err
+		}
+
+		return out, nil
+	case platform.OSIOS:
+		out := &ios.Target{Target: base} //nolint:exhaustruct
+
+		if err := t.Platform.IOS.Build(rc, out); err != nil {
+			return nil, err
+		}
+
+		return out, nil
 	case platform.OSLinux:
 		out := &linux.Target{Target: base}
 
@@ -261,6 +312,14 @@ func (t Targets) Combine(rc *run.Context) (Exporter, error) { //nolint:dupl,iret
 			return nil, err
 		}
 
+		return out, nil
+	case platform.OSWeb:
+		out := &web.Target{Target: base} //nolint:exhaustruct
+
+		if err := t.Platform.Web.Build(rc, out); err != nil {
+			return nil, err
+		}
+
 		return out, nil
 	case platform.OSWindows:
 		out := &windows.Target{Target: base}
@@ -8,8 +8,12 @@ import (
 
 	"github.com/coffeebeats/gdbuild/internal/action"
 	"github.com/coffeebeats/gdbuild/pkg/config"
+	"github.com/coffeebeats/gdbuild/pkg/config/android"
 	"github.com/coffeebeats/gdbuild/pkg/config/common"
+	"github.com/coffeebeats/gdbuild/pkg/config/ios"
+	"github.com/coffeebeats/gdbuild/pkg/config/web"
 	"github.com/coffeebeats/gdbuild/pkg/config/windows"
+	"github.com/coffeebeats/gdbuild/pkg/godot/encryption"
 	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
 	"github.com/coffeebeats/gdbuild/pkg/godot/export"
 	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
@@ -124,6 +128,96 @@ func TestTargetCombine(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "encryption key sources are replaced rather than merged",
+
+			rc: run.Context{
+				Platform: platform.OSWindows,
+				Profile:  engine.ProfileRelease,
+			},
+			doc: `
+			[target.target]
+			encryption = { key_env = "GAME_KEY" }
+
+			[target.target.profile.release]
+			encryption = { key_file = "release.key" }
+			`,
+
+			want: &windows.Target{
+				Target: &common.Target{
+					Encryption: encryption.KeySource{KeyFile: "release.key"},
+				},
+			},
+		},
+		{
+			name: "android properties are correctly populated",
+
+			rc: run.Context{
+				Platform: platform.OSAndroid,
+				Profile:  engine.ProfileRelease,
+			},
+			doc: `
+			[target.target.platform.android]
+			package_name = "com.example.game"
+			permissions = ["internet"]
+			version_code = 1
+
+			[target.target.platform.android.profile.release]
+			keystore = { path = "release.keystore", user = "release" }
+			permissions = ["vibrate"]
+			`,
+
+			want: &android.Target{
+				Target:      &common.Target{},
+				Keystore:    android.Keystore{Path: "release.keystore", User: "release"},
+				PackageName: "com.example.game",
+				Permissions: []string{"internet", "vibrate"},
+				VersionCode: pointer(uint(1)),
+			},
+		},
+		{
+			name: "ios properties are correctly populated",
+
+			rc: run.Context{Platform: platform.OSIOS},
+			doc: `
+			[target.target.platform.ios]
+			bundle_identifier = "com.example.game"
+			capabilities = ["access_wifi"]
+			team_id = "ABCDE12345"
+			`,
+
+			want: &ios.Target{
+				Target:           &common.Target{},
+				BundleIdentifier: "com.example.game",
+				Capabilities:     []string{"access_wifi"},
+				TeamID:           "ABCDE12345",
+			},
+		},
+		{
+			name: "web properties are correctly populated",
+
+			rc: run.Context{
+				Features: []string{"test"},
+				Platform: platform.OSWeb,
+			},
+			doc: `
+			[target.target.platform.web]
+			canvas_resize_policy = "adaptive"
+			pwa = { enabled = true }
+
+			[target.target.platform.web.feature.test]
+			precompress = { brotli = true, gzip = true }
+			threads = false
+			`,
+
+			want: &web.Target{
+				Target:             &common.Target{},
+				CanvasResizePolicy: "adaptive",
+				PWA:                web.PWA{Enabled: pointer(true)},
+				Precompress:        web.Precompress{Brotli: pointer(true), Gzip: pointer(true)},
+				Threads:            pointer(false),
+			},
+		},
 	}
 
 	for _, tc := range tests {
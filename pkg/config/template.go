@@ -6,8 +6,10 @@ import (
 	"github.com/coffeebeats/gdbuild/internal/config"
 	"github.com/coffeebeats/gdbuild/internal/osutil"
 	"github.com/coffeebeats/gdbuild/pkg/config/common"
+	"github.com/coffeebeats/gdbuild/pkg/config/ios"
 	"github.com/coffeebeats/gdbuild/pkg/config/linux"
 	"github.com/coffeebeats/gdbuild/pkg/config/macos"
+	"github.com/coffeebeats/gdbuild/pkg/config/web"
 	"github.com/coffeebeats/gdbuild/pkg/config/windows"
 	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
 	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
@@ -113,6 +115,18 @@ func Template(rc *run.Context, m *Manifest) (*template.Template, error) { //noli
 		return nil, err
 	}
 
+	// NOTE: The Godot version is unknown when compiling from a source path.
+	if v, ok := merged.template.(versionValidator); ok && merged.godot.PathSource == "" {
+		ev, err := merged.godot.ParseVersion()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := v.ValidateVersion(ev); err != nil {
+			return nil, err
+		}
+	}
+
 	return merged.template.Collect(*merged.godot.Source, rc), nil
 }
 
@@ -126,6 +140,14 @@ type Templater interface {
 	Collect(src engine.Source, rc *run.Context) *template.Template
 }
 
+/* ---------------------- Interface: versionValidator ----------------------- */
+
+// versionValidator is implemented by templates whose build options depend on
+// the version of Godot being compiled.
+type versionValidator interface {
+	ValidateVersion(ev engine.Version) error
+}
+
 /* -------------------------------------------------------------------------- */
 /*                              Struct: Templates                             */
 /* -------------------------------------------------------------------------- */
@@ -160,8 +182,10 @@ type BaseTemplateWithoutFeature struct {
 /* ---------------------------- Struct: Platforms --------------------------- */
 
 type TemplatePlatforms struct {
+	IOS     ios.TemplateWithFeaturesAndProfile     `toml:"ios"`
 	Linux   linux.TemplateWithFeaturesAndProfile   `toml:"linux"`
 	MacOS   macos.TemplateWithFeaturesAndProfile   `toml:"macos"`
+	Web     web.TemplateWithFeaturesAndProfile     `toml:"web"`
 	Windows windows.TemplateWithFeaturesAndProfile `toml:"windows"`
 }
 
@@ -173,8 +197,10 @@ type TemplateBuilder[T Templater] interface {
 
 // Compile-time check that 'Builder' is implemented.
 var _ TemplateBuilder[*common.Template] = (*common.TemplateWithFeaturesAndProfile)(nil)
+var _ TemplateBuilder[*ios.Template] = (*ios.TemplateWithFeaturesAndProfile)(nil)
 var _ TemplateBuilder[*linux.Template] = (*linux.TemplateWithFeaturesAndProfile)(nil)
 var _ TemplateBuilder[*macos.Template] = (*macos.TemplateWithFeaturesAndProfile)(nil)
+var _ TemplateBuilder[*web.Template] = (*web.TemplateWithFeaturesAndProfile)(nil)
 var _ TemplateBuilder[*windows.Template] = (*windows.TemplateWithFeaturesAndProfile)(nil)
 
 /* ----------------------------- Method: Combine ---------------------------- */
@@ -188,6 +214,14 @@ func (t *Templates) Combine(rc *run.Context) (Templater, error) { //nolint:dupl,
 	}
 
 	switch p := rc.Platform; p {
+	case platform.OSIOS:
+		out := &ios.Template{Template: base} //nolint:exhaustruct
+
+		if err := t.Platform.IOS.Build(rc, out); err != nil {
+			return nil, err
+		}
+
+		return out, nil
 	case platform.OSLinux:
 		out := &linux.Template{Template: base} //nolint:exhaustruct
 
@@ -203,6 +237,14 @@ func (t *Templates) Combine(rc *run.Context) (Templater, error) { //nolint:dupl,
 			return nil, err
 		}
 
+		return out, nil
+	case platform.OSWeb:
+		out := &web.Template{Template: base} //nolint:exhaustruct
+
+		if err := t.Platform.Web.Build(rc, out); err != nil {
+			return nil, err
+		}
+
 		return out, nil
 	case platform.OSWindows:
 		out := &windows.Template{Template: base} //nolint:exhaustruct
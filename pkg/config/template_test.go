@@ -17,6 +17,7 @@ import (
 	"github.com/coffeebeats/gdbuild/pkg/config/common"
 	"github.com/coffeebeats/gdbuild/pkg/config/linux"
 	"github.com/coffeebeats/gdbuild/pkg/config/macos"
+	"github.com/coffeebeats/gdbuild/pkg/config/web"
 	"github.com/coffeebeats/gdbuild/pkg/config/windows"
 	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
 	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
@@ -88,6 +89,60 @@ func TestBuildTemplate(t *testing.T) {
 				)
 			},
 		},
+		{
+			name: "arm64 linux template is correctly cross-compiled",
+
+			rc: run.Context{
+				PathWorkspace: "$TEST_TMPDIR/build",
+				PathManifest:  "$TEST_TMPDIR/gdbuild.toml",
+				PathOut:       "$TEST_TMPDIR/dist",
+				Platform:      platform.OSLinux,
+				Profile:       engine.ProfileDebug,
+			},
+			files: map[string]string{
+				"sysroot/": "", // Create an empty directory.
+
+				"gdbuild.toml": `
+					godot.version = "4.0.0"
+
+					[template.platform.linux]
+					arch = "arm64"
+					toolchain = { compiler_prefix = "aarch64-linux-gnu-", sysroot = "sysroot" }`,
+			},
+
+			assert: func(t *testing.T, rc *run.Context, tmp string, got *template.Template, err error) {
+				// Then: There's no error.
+				assert.Nil(t, err)
+
+				// Given: The expected sysroot flag.
+				sysroot := "--sysroot=" + filepath.Join(tmp, "sysroot")
+
+				// Then: The template matches expectations.
+				assert.Equal(
+					t,
+					&template.Template{
+						Arch: platform.ArchArm64,
+						Builds: []template.Build{
+							{
+								Arch:     platform.ArchArm64,
+								Source:   engine.Source{Version: mustParseVersion(t, "4.0.0")},
+								Platform: platform.OSLinux,
+								Profile:  engine.ProfileDebug,
+								SCons: template.SCons{
+									CCFlags:   []string{sysroot},
+									ExtraArgs: []string{"CC=aarch64-linux-gnu-gcc", "CXX=aarch64-linux-gnu-g++"},
+									LinkFlags: []string{sysroot},
+								},
+							},
+						},
+					},
+					got,
+				)
+
+				// Then: The artifact name uses the correct architecture.
+				assert.Equal(t, "godot.linuxbsd.template_debug.arm64", got.Basename(rc))
+			},
+		},
 		{
 			name: "empty template is correctly converted into default for macos",
 
@@ -461,6 +516,42 @@ func TestTemplateCombine(t *testing.T) {
 				Vulkan:      macos.Vulkan{Dynamic: pointer(true)},
 			},
 		},
+		{
+			name: "web-specific properties with constraints are correctly populated",
+
+			rc: run.Context{
+				Platform: platform.OSWeb,
+				Profile:  engine.ProfileRelease,
+			},
+			doc: `[template.platform.web]
+			dlink_enabled = true
+
+			[template.platform.web.profile.release]
+			threads = false`,
+
+			want: &web.Template{
+				DLinkEnabled: pointer(true),
+				Threads:      pointer(false),
+				Template:     new(common.Template),
+			},
+		},
+		{
+			name: "windows-specific arm64 properties are correctly populated",
+
+			rc: run.Context{Platform: platform.OSWindows},
+			doc: `[template.platform.windows]
+			arch = "arm64"
+			toolchain = { mingw_prefix = "/opt/llvm-mingw" }
+			use_llvm = true
+			use_mingw = true`,
+
+			want: &windows.Template{
+				Template:  &common.Template{Arch: platform.ArchArm64},
+				Toolchain: windows.Toolchain{PathMinGWPrefix: "/opt/llvm-mingw"},
+				UseLLVM:   pointer(true),
+				UseMinGW:  pointer(true),
+			},
+		},
 		{
 			name: "windows-specific properties with constraints are correctly populated",
 
@@ -0,0 +1,128 @@
+package web
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/internal/exec"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                   Function: NewCopyTemplateArchiveAction                   */
+/* -------------------------------------------------------------------------- */
+
+// NewCopyTemplateArchiveAction creates an 'action.Action' which copies the
+// template archive compiled by SCons to the name expected by Godot. Both names
+// are relative to the 'bin' directory.
+func NewCopyTemplateArchiveAction(
+	rc *run.Context,
+	src, dst string,
+) action.WithDescription[action.Function] {
+	fn := func(ctx context.Context) error {
+		return osutil.CopyFile(
+			ctx,
+			rc.BinPath().Join(src).String(),
+			rc.BinPath().Join(dst).String(),
+		)
+	}
+
+	return action.WithDescription[action.Function]{
+		Action:      fn,
+		Description: "copy template archive: " + dst,
+	}
+}
+
+/* -------------------------------------------------------------------------- */
+/*                        Function: NewPrecompressAction                       */
+/* -------------------------------------------------------------------------- */
+
+// NewPrecompressAction creates an 'action.Action' which writes precompressed
+// copies of the specified exported files (relative to the output directory)
+// alongside the originals.
+func NewPrecompressAction(
+	rc *run.Context,
+	pc Precompress,
+	files []string,
+) action.WithDescription[action.Function] {
+	fn := func(ctx context.Context) error {
+		for _, f := range files {
+			path := rc.PathOut.Join(f).String()
+
+			if config.Dereference(pc.Gzip) {
+				if err := gzipFile(path, path+".gz"); err != nil {
+					return err
+				}
+			}
+
+			if config.Dereference(pc.Brotli) {
+				brotli := pc.BrotliCommand
+				if len(brotli) == 0 {
+					brotli = append(brotli, "brotli")
+				}
+
+				cmd := exec.Process{
+					Args: append(slices.Clip(brotli), "--force", "--keep", "--best", path),
+
+					// NOTE: Run the command directly so that the file's path is
+					// passed through unchanged.
+					Shell:   exec.ShellNone,
+					Verbose: rc.Verbose,
+				}
+
+				if err := cmd.Run(ctx); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	return action.WithDescription[action.Function]{
+		Action:      fn,
+		Description: "precompress exported files: " + strings.Join(files, ", "),
+	}
+}
+
+/* ---------------------------- Function: gzipFile --------------------------- */
+
+// gzipFile writes a gzip-compressed copy of the file at 'src' to 'dst'. The
+// gzip header is left empty so that the output is deterministic.
+func gzipFile(src, dst string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+
+	defer out.Close()
+
+	w, err := gzip.NewWriterLevel(out, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, f); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
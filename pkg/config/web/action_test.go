@@ -0,0 +1,57 @@
+package web_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/config/web"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+func TestNewPrecompressAction(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires a POSIX shell")
+	}
+
+	// Given: An output directory, containing a space, with an exported file.
+	pathOut := filepath.Join(t.TempDir(), "out dir")
+	require.NoError(t, os.MkdirAll(pathOut, 0o750))
+
+	pathFile := filepath.Join(pathOut, "game.wasm")
+	require.NoError(t, os.WriteFile(pathFile, []byte("wasm"), 0o600))
+
+	// Given: A fake 'brotli' tool which records its arguments.
+	pathArgs := filepath.Join(t.TempDir(), "args.txt")
+	pathTool := filepath.Join(t.TempDir(), "brotli")
+
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > '" + pathArgs + "'\n"
+	require.NoError(t, os.WriteFile(pathTool, []byte(script), 0o700)) //nolint:gosec
+
+	pc := web.Precompress{Brotli: pointer(true), BrotliCommand: []string{pathTool}} //nolint:exhaustruct
+
+	rc := run.Context{PathOut: osutil.Path(pathOut)} //nolint:exhaustruct
+
+	// When: The exported file is precompressed.
+	err := web.NewPrecompressAction(&rc, pc, []string{"game.wasm"}).Run(context.Background())
+
+	// Then: There's no error.
+	require.NoError(t, err)
+
+	// Then: The file's path is passed through unchanged.
+	got, err := os.ReadFile(pathArgs)
+	require.NoError(t, err)
+
+	want := []string{"--force", "--keep", "--best", pathFile}
+	assert.Equal(t, want, strings.Split(strings.TrimSpace(string(got)), "\n"))
+
+	// Then: The configured command isn't modified.
+	assert.Equal(t, []string{pathTool}, pc.BrotliCommand)
+}
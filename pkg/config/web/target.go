@@ -0,0 +1,392 @@
+package web
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/config/common"
+	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/godot/template"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                               Struct: Target                               */
+/* -------------------------------------------------------------------------- */
+
+type Target struct {
+	*common.Target
+
+	// CanvasResizePolicy determines how the canvas is resized within the page.
+	// Must be one of 'none', 'project', or 'adaptive'.
+	CanvasResizePolicy string `toml:"canvas_resize_policy"`
+	// HeadInclude is custom HTML to include within the page's '<head>' tag.
+	HeadInclude string `toml:"head_include"`
+	// PWA configures the exported project as a Progressive Web App.
+	PWA PWA `toml:"pwa"`
+	// Precompress configures generation of precompressed copies of the large
+	// exported files (i.e. '.js', '.wasm', and '.pck').
+	Precompress Precompress `toml:"precompress"`
+	// Threads determines whether the exported project uses thread support.
+	// Defaults to the thread support setting of the export template.
+	Threads *bool `toml:"threads"`
+}
+
+/* ----------------------------- Impl: Exporter ----------------------------- */
+
+func (t *Target) Collect(rc *run.Context, tl *template.Template, ev engine.Version) *export.Export {
+	out := t.Target.Collect(rc, tl, ev)
+
+	if out.Options == nil {
+		out.Options = map[string]any{}
+	}
+
+	// NOTE: Thread and GDExtension support must match how the export template
+	// was compiled, so infer them from the template's artifact name.
+	var suffix string
+	if tl != nil && len(tl.Builds) > 0 {
+		suffix = tl.Builds[0].ExtraSuffix
+	}
+
+	threads := !strings.Contains(suffix, ".nothreads")
+	if t.Threads != nil {
+		threads = *t.Threads
+	}
+
+	out.Options["variant/extensions_support"] = strings.Contains(suffix, ".dlink")
+	out.Options["variant/thread_support"] = threads
+
+	if t.CanvasResizePolicy != "" {
+		out.Options["html/canvas_resize_policy"] = canvasResizePolicies[t.CanvasResizePolicy]
+	}
+
+	if t.HeadInclude != "" {
+		out.Options["html/head_include"] = t.HeadInclude
+	}
+
+	t.PWA.apply(out)
+
+	// Register the additional files generated by the web export. These are
+	// named after the HTML file of the embedded pack file.
+	var name string
+
+	for i, pf := range out.PackFiles {
+		if config.Dereference(pf.Embed) {
+			name = strings.TrimSuffix(pf.Filename(platform.OSWeb, rc.Target, i), ".html")
+
+			break
+		}
+	}
+
+	if name == "" {
+		return out
+	}
+
+	files := []string{name + ".js", name + ".wasm", name + ".pck"}
+
+	out.ExtraArtifacts = append(out.ExtraArtifacts, files...)
+
+	if config.Dereference(t.PWA.Enabled) {
+		out.ExtraArtifacts = append(
+			out.ExtraArtifacts,
+			name+".manifest.json",
+			name+".service.worker.js",
+		)
+	}
+
+	if t.Precompress.IsEnabled() {
+		out.ExtraArtifacts = append(out.ExtraArtifacts, t.Precompress.Artifacts(files)...)
+		out.Postexport = action.InOrder(
+			out.Postexport,
+			NewPrecompressAction(rc, t.Precompress, files),
+		)
+	}
+
+	return out
+}
+
+/* ------------------------- Impl: config.Configurer ------------------------ */
+
+func (t *Target) Configure(rc *run.Context) error {
+	if err := t.Target.Configure(rc); err != nil {
+		return err
+	}
+
+	if err := t.PWA.Configure(rc); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (t *Target) Validate(rc *run.Context) error {
+	if err := t.Target.Validate(rc); err != nil {
+		return err
+	}
+
+	if _, ok := canvasResizePolicies[t.CanvasResizePolicy]; t.CanvasResizePolicy != "" && !ok {
+		return fmt.Errorf(
+			"%w: unsupported canvas resize policy: %s",
+			config.ErrInvalidInput,
+			t.CanvasResizePolicy,
+		)
+	}
+
+	if err := t.PWA.Validate(rc); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/* --------------------------- Impl: config.Merger -------------------------- */
+
+func (t *Target) MergeInto(other any) error {
+	if t == nil || other == nil {
+		return nil
+	}
+
+	dst, ok := other.(*Target)
+	if !ok {
+		return fmt.Errorf(
+			"%w: expected a '%T' but was '%T'",
+			config.ErrInvalidInput,
+			new(Target),
+			other,
+		)
+	}
+
+	return config.Merge(dst, *t)
+}
+
+/* -------------------------------------------------------------------------- */
+/*                                 Struct: PWA                                */
+/* -------------------------------------------------------------------------- */
+
+// PWA defines the Progressive Web App settings for a web export.
+type PWA struct {
+	// Enabled determines whether a PWA manifest and service worker are
+	// generated during export.
+	Enabled *bool `toml:"enabled"`
+	// CrossOriginIsolation determines whether the service worker injects the
+	// 'Cross-Origin-*' headers required for thread support. Useful for hosts
+	// which don't allow configuring response headers.
+	CrossOriginIsolation *bool `toml:"ensure_cross_origin_isolation_headers"`
+	// Display is the PWA display mode. Must be one of 'fullscreen',
+	// 'standalone', 'minimal-ui', or 'browser'.
+	Display string `toml:"display"`
+	// Orientation is the PWA screen orientation. Must be one of 'any',
+	// 'landscape', or 'portrait'.
+	Orientation string `toml:"orientation"`
+	// PathIcon144 is a path to a 144x144 application icon.
+	PathIcon144 osutil.Path `toml:"icon_144x144"`
+	// PathIcon180 is a path to a 180x180 application icon.
+	PathIcon180 osutil.Path `toml:"icon_180x180"`
+	// PathIcon512 is a path to a 512x512 application icon.
+	PathIcon512 osutil.Path `toml:"icon_512x512"`
+	// PathOfflinePage is a path to an HTML page shown when the PWA is offline.
+	PathOfflinePage osutil.Path `toml:"offline_page"`
+}
+
+/* ------------------------------ Method: apply ----------------------------- */
+
+// apply sets the export options for the PWA on the specified export. Paths are
+// set as 'PathOptions' so that their locations don't affect the export's
+// checksum; instead, their contents are included as dependencies.
+func (p *PWA) apply(out *export.Export) {
+	if !config.Dereference(p.Enabled) {
+		return
+	}
+
+	options := out.Options
+
+	options["progressive_web_app/enabled"] = true
+
+	if p.CrossOriginIsolation != nil {
+		options["progressive_web_app/ensure_cross_origin_isolation_headers"] = *p.CrossOriginIsolation
+	}
+
+	if p.Display != "" {
+		options["progressive_web_app/display"] = pwaDisplayModes[p.Display]
+	}
+
+	if p.Orientation != "" {
+		options["progressive_web_app/orientation"] = pwaOrientations[p.Orientation]
+	}
+
+	for key, path := range map[string]osutil.Path{
+		"progressive_web_app/icon_144x144": p.PathIcon144,
+		"progressive_web_app/icon_180x180": p.PathIcon180,
+		"progressive_web_app/icon_512x512": p.PathIcon512,
+		"progressive_web_app/offline_page": p.PathOfflinePage,
+	} {
+		if path == "" {
+			continue
+		}
+
+		if out.PathOptions == nil {
+			out.PathOptions = map[string]osutil.Path{}
+		}
+
+		out.PathOptions[key] = path
+		out.RegisterDependencyPath(path)
+	}
+}
+
+/* ------------------------- Impl: config.Configurer ------------------------ */
+
+func (p *PWA) Configure(rc *run.Context) error {
+	for _, path := range []*osutil.Path{
+		&p.PathIcon144,
+		&p.PathIcon180,
+		&p.PathIcon512,
+		&p.PathOfflinePage,
+	} {
+		if err := path.RelTo(rc.PathManifest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (p *PWA) Validate(_ *run.Context) error {
+	if _, ok := pwaDisplayModes[p.Display]; p.Display != "" && !ok {
+		return fmt.Errorf("%w: unsupported PWA display mode: %s", config.ErrInvalidInput, p.Display)
+	}
+
+	if _, ok := pwaOrientations[p.Orientation]; p.Orientation != "" && !ok {
+		return fmt.Errorf("%w: unsupported PWA orientation: %s", config.ErrInvalidInput, p.Orientation)
+	}
+
+	for _, path := range []osutil.Path{
+		p.PathIcon144,
+		p.PathIcon180,
+		p.PathIcon512,
+		p.PathOfflinePage,
+	} {
+		if err := path.CheckIsFileOrEmpty(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                             Struct: Precompress                            */
+/* -------------------------------------------------------------------------- */
+
+// Precompress defines which precompressed copies of the exported files are
+// generated. These allow web servers to serve compressed files directly.
+type Precompress struct {
+	// Brotli determines whether '.br' files are generated.
+	Brotli *bool `toml:"brotli"`
+	// BrotliCommand contains arguments used to invoke 'brotli'. Defaults to
+	// ["brotli"].
+	BrotliCommand []string `toml:"brotli_command"`
+	// Gzip determines whether '.gz' files are generated.
+	Gzip *bool `toml:"gzip"`
+}
+
+/* ---------------------------- Method: IsEnabled --------------------------- */
+
+// IsEnabled returns whether any precompressed files will be generated.
+func (p *Precompress) IsEnabled() bool {
+	return config.Dereference(p.Brotli) || config.Dereference(p.Gzip)
+}
+
+/* ---------------------------- Method: Artifacts --------------------------- */
+
+// Artifacts returns the names of the precompressed files generated for the
+// provided files.
+func (p *Precompress) Artifacts(files []string) []string {
+	out := make([]string, 0, 2*len(files)) //nolint:gomnd
+
+	for _, f := range files {
+		if config.Dereference(p.Brotli) {
+			out = append(out, f+".br")
+		}
+
+		if config.Dereference(p.Gzip) {
+			out = append(out, f+".gz")
+		}
+	}
+
+	return out
+}
+
+/* -------------------------------------------------------------------------- */
+/*                               Enum Mappings                                */
+/* -------------------------------------------------------------------------- */
+
+// NOTE: These map to the enum values used by Godot's web export options.
+var (
+	canvasResizePolicies = map[string]int{"none": 0, "project": 1, "adaptive": 2}
+	pwaDisplayModes      = map[string]int{"fullscreen": 0, "standalone": 1, "minimal-ui": 2, "browser": 3}
+	pwaOrientations      = map[string]int{"any": 0, "landscape": 1, "portrait": 2}
+)
+
+/* -------------------------------------------------------------------------- */
+/*                    Struct: TargetWithFeaturesAndProfile                    */
+/* -------------------------------------------------------------------------- */
+
+type TargetWithFeaturesAndProfile struct {
+	*Target
+
+	Feature map[string]TargetWithProfile `toml:"feature"`
+	Profile map[engine.Profile]Target    `toml:"profile"`
+}
+
+/* ------------------------ Struct: TargetWithProfile ----------------------- */
+
+type TargetWithProfile struct {
+	*Target
+
+	Profile map[engine.Profile]Target `toml:"profile"`
+}
+
+/* ---------------------- Impl: platform.targetBuilder ---------------------- */
+
+func (t *TargetWithFeaturesAndProfile) Build(rc *run.Context, dst *Target) error {
+	if t == nil {
+		return nil
+	}
+
+	// Root-level params
+	if err := t.Target.MergeInto(dst); err != nil {
+		return err
+	}
+
+	// Feature-constrained params
+	for _, f := range rc.Features {
+		if err := t.Feature[f].Target.MergeInto(dst); err != nil {
+			return err
+		}
+	}
+
+	// Profile-constrained params
+	l := t.Profile[rc.Profile]
+	if err := l.MergeInto(dst); err != nil {
+		return err
+	}
+
+	// Feature-and-profile-constrained params
+	for _, f := range rc.Features {
+		l := t.Feature[f].Profile[rc.Profile]
+		if err := l.MergeInto(dst); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
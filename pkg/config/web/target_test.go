@@ -0,0 +1,123 @@
+package web_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/config/common"
+	"github.com/coffeebeats/gdbuild/pkg/config/web"
+	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/godot/template"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+func TestTargetCollect(t *testing.T) {
+	tests := []struct {
+		name string
+
+		suffix string
+		target web.Target
+
+		wantOptions     map[string]any
+		wantPathOptions map[string]osutil.Path
+		wantArtifacts   []string
+	}{
+		{
+			name: "default target infers settings from template",
+
+			suffix: ".nothreads.dlink",
+			target: web.Target{
+				Target: &common.Target{
+					PackFiles: []export.PackFile{{Embed: pointer(true)}},
+				},
+			},
+
+			wantOptions: map[string]any{
+				"variant/extensions_support": true,
+				"variant/thread_support":     false,
+			},
+			wantArtifacts: []string{"game.js", "game.wasm", "game.pck"},
+		},
+		{
+			name: "typed options and precompressed files are populated",
+
+			target: web.Target{
+				Target: &common.Target{
+					PackFiles: []export.PackFile{{Embed: pointer(true)}},
+				},
+				CanvasResizePolicy: "project",
+				HeadInclude:        "<meta>",
+				PWA: web.PWA{
+					Enabled:         pointer(true),
+					Display:         "standalone",
+					PathIcon144:     "/icons/icon 144.png",
+					PathOfflinePage: "/web/offline.html",
+				},
+				Precompress: web.Precompress{Gzip: pointer(true)},
+			},
+
+			wantOptions: map[string]any{
+				"html/canvas_resize_policy":   1,
+				"html/head_include":           "<meta>",
+				"progressive_web_app/display": 1,
+				"progressive_web_app/enabled": true,
+				"variant/extensions_support":  false,
+				"variant/thread_support":      true,
+			},
+			wantPathOptions: map[string]osutil.Path{
+				"progressive_web_app/icon_144x144": "/icons/icon 144.png",
+				"progressive_web_app/offline_page": "/web/offline.html",
+			},
+			wantArtifacts: []string{
+				"game.js", "game.wasm", "game.pck",
+				"game.manifest.json", "game.service.worker.js",
+				"game.js.gz", "game.wasm.gz", "game.pck.gz",
+			},
+		},
+		{
+			name: "pack-only target has no extra artifacts",
+
+			target: web.Target{
+				Target: &common.Target{
+					PackFiles: []export.PackFile{{}},
+				},
+			},
+
+			wantOptions: map[string]any{
+				"variant/extensions_support": false,
+				"variant/thread_support":     true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A run context for the web platform.
+			rc := run.Context{Platform: platform.OSWeb, Profile: engine.ProfileDebug, Target: "game"}
+
+			// Given: An export template with the specified suffix.
+			tl := template.Template{Builds: []template.Build{{ExtraSuffix: tc.suffix}}}
+
+			// When: The target is collected.
+			got := tc.target.Collect(&rc, &tl, engine.Version{})
+
+			// Then: The export options match expectations.
+			assert.Equal(t, tc.wantOptions, got.Options)
+
+			// Then: The path options match expectations and are dependencies of
+			// the export.
+			assert.Equal(t, tc.wantPathOptions, got.PathOptions)
+
+			for _, path := range tc.wantPathOptions {
+				assert.Contains(t, got.Paths, path)
+			}
+
+			// Then: The extra artifacts match expectations.
+			assert.Equal(t, tc.wantArtifacts, got.ExtraArtifacts)
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package web
+
+import (
+	"fmt"
+
+	"github.com/coffeebeats/gdenv/pkg/godot/version"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/pkg/config/common"
+	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/godot/template"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                              Struct: Template                              */
+/* -------------------------------------------------------------------------- */
+
+// Template defines the parameters for building a web export template. Note that
+// web templates are compiled with Emscripten, so 'emcc' must be available on
+// the 'PATH' (e.g. by activating the Emscripten SDK prior to building).
+type Template struct {
+	*common.Template
+
+	// DLinkEnabled determines whether GDExtension support (i.e. dynamic
+	// linking) is enabled in the export template.
+	DLinkEnabled *bool `toml:"dlink_enabled"`
+
+	// JavaScriptEval determines whether the 'JavaScriptBridge.eval' method is
+	// available in the export template. Defaults to 'true'.
+	JavaScriptEval *bool `toml:"javascript_eval"`
+
+	// Threads determines whether the export template is compiled with thread
+	// support. Note that disabling threads requires Godot 4.3 or later.
+	// Defaults to 'true'.
+	Threads *bool `toml:"threads"`
+}
+
+/* ----------------------------- Impl: Template ----------------------------- */
+
+func (t *Template) Collect(g engine.Source, rc *run.Context) *template.Template {
+	out := t.Template.Collect(g, rc)
+
+	out.Arch = platform.ArchWasm32
+	out.Builds[0].Arch = platform.ArchWasm32
+	out.Builds[0].Platform = platform.OSWeb
+
+	scons := &out.Builds[0].SCons
+
+	// NOTE: SCons appends suffixes in this order; see
+	// https://github.com/godotengine/godot/blob/master/SConstruct.
+	var suffix string
+
+	if t.Threads != nil && !*t.Threads {
+		scons.ExtraArgs = append(scons.ExtraArgs, "threads=no")
+		suffix += ".nothreads"
+	}
+
+	if config.Dereference(t.DLinkEnabled) {
+		scons.ExtraArgs = append(scons.ExtraArgs, "dlink_enabled=yes")
+		suffix += ".dlink"
+	}
+
+	if t.JavaScriptEval != nil && !*t.JavaScriptEval {
+		scons.ExtraArgs = append(scons.ExtraArgs, "javascript_eval=no")
+	}
+
+	out.Builds[0].ExtraSuffix = suffix
+
+	// Godot expects the export template archive to be named according to the
+	// enabled features (e.g. 'web_dlink_nothreads_release.zip').
+	name := TemplateArchiveName(
+		config.Dereference(t.DLinkEnabled),
+		t.Threads == nil || *t.Threads,
+		rc.Profile,
+	)
+
+	out.NameOverride = name
+	out.ExtraArtifacts = append(out.ExtraArtifacts, name)
+	out.Postbuild = action.InOrder(
+		NewCopyTemplateArchiveAction(rc, out.Builds[0].Basename(rc), name),
+		out.Postbuild,
+	)
+
+	return out
+}
+
+/* ------------------------- Impl: config.Configurer ------------------------ */
+
+func (t *Template) Configure(rc *run.Context) error {
+	if err := t.Template.Configure(rc); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (t *Template) Validate(rc *run.Context) error {
+	if err := t.Template.Validate(rc); err != nil {
+		return err
+	}
+
+	if !t.Arch.IsOneOf(platform.ArchWasm32, platform.ArchUnknown) {
+		return fmt.Errorf("%w: unsupport architecture: %s", config.ErrInvalidInput, t.Arch)
+	}
+
+	return nil
+}
+
+/* ------------------------- Method: ValidateVersion ------------------------ */
+
+// ValidateVersion validates that the template's build options are supported by
+// the specified version of Godot.
+func (t *Template) ValidateVersion(ev engine.Version) error {
+	v := version.Version(ev)
+
+	// NOTE: Godot only supports compiling web templates without threads since
+	// version 4.3.
+	if t.Threads != nil && !*t.Threads && (v.Major() < 4 || (v.Major() == 4 && v.Minor() < 3)) { //nolint:gomnd
+		return fmt.Errorf(
+			"%w: disabling threads requires Godot 4.3 or later: %s",
+			config.ErrInvalidInput,
+			ev,
+		)
+	}
+
+	return nil
+}
+
+/* --------------------------- Impl: config.Merger -------------------------- */
+
+func (t *Template) MergeInto(other any) error {
+	if t == nil || other == nil {
+		return nil
+	}
+
+	dst, ok := other.(*Template)
+	if !ok {
+		return fmt.Errorf(
+			"%w: expected a '%T' but was '%T'",
+			config.ErrInvalidInput,
+			new(Template),
+			other,
+		)
+	}
+
+	return config.Merge(dst, *t)
+}
+
+/* -------------------------------------------------------------------------- */
+/*                        Function: TemplateArchiveName                       */
+/* -------------------------------------------------------------------------- */
+
+// TemplateArchiveName returns the name of the web export template archive that
+// Godot expects for the specified build options.
+func TemplateArchiveName(dlink, threads bool, pr engine.Profile) string {
+	name := "web"
+
+	if dlink {
+		name += "_dlink"
+	}
+
+	if !threads {
+		name += "_nothreads"
+	}
+
+	if pr == engine.ProfileRelease {
+		name += "_release"
+	} else {
+		name += "_debug"
+	}
+
+	return name + ".zip"
+}
+
+/* -------------------------------------------------------------------------- */
+/*                   Struct: TemplateWithFeaturesAndProfile                   */
+/* -------------------------------------------------------------------------- */
+
+type TemplateWithFeaturesAndProfile struct {
+	*Template
+
+	Feature map[string]TemplateWithProfile `toml:"feature"`
+	Profile map[engine.Profile]Template    `toml:"profile"`
+}
+
+/* ----------------------- Struct: TemplateWithProfile ---------------------- */
+
+type TemplateWithProfile struct {
+	*Template
+
+	Profile map[engine.Profile]Template `toml:"profile"`
+}
+
+/* --------------------- Impl: platform.templateBuilder --------------------- */
+
+func (t *TemplateWithFeaturesAndProfile) Build(rc *run.Context, dst *Template) error {
+	if t == nil {
+		return nil
+	}
+
+	// Root-level params
+	if err := t.Template.MergeInto(dst); err != nil {
+		return err
+	}
+
+	// Feature-constrained params
+	for _, f := range rc.Features {
+		if err := t.Feature[f].Template.MergeInto(dst); err != nil {
+			return err
+		}
+	}
+
+	// Profile-constrained params
+	l := t.Profile[rc.Profile]
+	if err := l.MergeInto(dst); err != nil {
+		return err
+	}
+
+	// Feature-and-profile-constrained params
+	for _, f := range rc.Features {
+		l := t.Feature[f].Profile[rc.Profile]
+		if err := l.MergeInto(dst); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package web_test
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/pkg/config/common"
+	"github.com/coffeebeats/gdbuild/pkg/config/web"
+	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+func TestTemplateSConsCommand(t *testing.T) {
+	jobs := "-j" + strconv.Itoa(runtime.NumCPU())
+
+	tests := []struct {
+		name string
+
+		profile  engine.Profile
+		template web.Template
+
+		wantArgs     []string
+		wantBasename string
+		wantName     string
+	}{
+		{
+			name: "default template builds with threads",
+
+			profile:  engine.ProfileDebug,
+			template: web.Template{Template: &common.Template{}},
+
+			wantArgs: []string{
+				"scons", jobs, "platform=web", "arch=wasm32", "target=template_debug",
+				"warnings=extra", "werror=yes", "debug_symbols=yes", "dev_mode=yes", "optimize=debug",
+			},
+			wantBasename: "godot.web.template_debug.wasm32.zip",
+			wantName:     "web_debug.zip",
+		},
+		{
+			name: "release template without threads and with dlink",
+
+			profile: engine.ProfileRelease,
+			template: web.Template{
+				Template:       &common.Template{},
+				DLinkEnabled:   pointer(true),
+				JavaScriptEval: pointer(false),
+				Threads:        pointer(false),
+			},
+
+			wantArgs: []string{
+				"scons", jobs, "platform=web", "arch=wasm32", "target=template_release",
+				"warnings=extra", "werror=yes", "production=yes", "optimize=speed",
+				"threads=no", "dlink_enabled=yes", "javascript_eval=no",
+			},
+			wantBasename: "godot.web.template_release.wasm32.nothreads.dlink.zip",
+			wantName:     "web_dlink_nothreads_release.zip",
+		},
+		{
+			name: "release_debug template uses debug archive name",
+
+			profile: engine.ProfileReleaseDebug,
+			template: web.Template{
+				Template: &common.Template{},
+				Threads:  pointer(true),
+			},
+
+			wantArgs: []string{
+				"scons", jobs, "platform=web", "arch=wasm32", "target=template_debug",
+				"warnings=extra", "werror=yes", "debug_symbols=yes", "dev_mode=yes", "optimize=speed_trace",
+			},
+			wantBasename: "godot.web.template_debug.wasm32.zip",
+			wantName:     "web_debug.zip",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A run context for the web platform.
+			rc := run.Context{Platform: platform.OSWeb, Profile: tc.profile}
+
+			// When: The template is collected.
+			got := tc.template.Collect(engine.Source{}, &rc)
+
+			// Then: There's exactly one build for the correct platform.
+			assert.Len(t, got.Builds, 1)
+			assert.Equal(t, platform.ArchWasm32, got.Arch)
+			assert.Equal(t, platform.OSWeb, got.Builds[0].Platform)
+
+			// Then: The SCons command matches expectations.
+			assert.Equal(t, tc.wantArgs, got.Builds[0].SConsCommand(&rc).Args)
+
+			// Then: The compiled artifact name matches expectations.
+			assert.Equal(t, tc.wantBasename, got.Builds[0].Basename(&rc))
+
+			// Then: The export template name matches expectations.
+			assert.Equal(t, tc.wantName, got.Basename(&rc))
+			assert.Contains(t, got.ExtraArtifacts, tc.wantName)
+		})
+	}
+}
+
+func TestTemplateValidateVersion(t *testing.T) {
+	tests := []struct {
+		name string
+
+		version string
+		threads *bool
+
+		err error
+	}{
+		{name: "threads enabled before Godot 4.3 succeeds", version: "4.2.2-stable", threads: pointer(true)},
+		{name: "threads unset before Godot 4.3 succeeds", version: "4.2.2-stable"},
+		{name: "threads disabled in Godot 4.3 succeeds", version: "4.3-stable", threads: pointer(false)},
+		{name: "threads disabled after Godot 4.3 succeeds", version: "4.4.1-stable", threads: pointer(false)},
+		{
+			name:    "threads disabled before Godot 4.3 fails",
+			version: "4.2.2-stable",
+			threads: pointer(false),
+			err:     config.ErrInvalidInput,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A Godot version.
+			var ev engine.Version
+			require.NoError(t, ev.UnmarshalText([]byte(tc.version)))
+
+			// Given: A template with the specified thread support.
+			template := web.Template{Template: &common.Template{}, Threads: tc.threads}
+
+			// When: The template is validated against the Godot version.
+			err := template.ValidateVersion(ev)
+
+			// Then: The resulting error matches expectations.
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func pointer[T any](value T) *T {
+	return &value
+}
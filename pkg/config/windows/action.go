@@ -2,11 +2,16 @@ package windows
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/exec"
 	"github.com/coffeebeats/gdbuild/internal/osutil"
 	"github.com/coffeebeats/gdbuild/pkg/run"
 )
@@ -48,3 +53,120 @@ func NewCopyImageFileAction(
 		Description: "copy icon into build directory: " + pathDst,
 	}
 }
+
+/* -------------------------------------------------------------------------- */
+/*                           Function: NewSignAction                          */
+/* -------------------------------------------------------------------------- */
+
+// NewSignAction creates an 'action.Action' which code signs the exported
+// executables using 'osslsigncode'. The first executable name (relative to the
+// output directory) is required to exist; the remaining ones (e.g. the console
+// wrapper) are signed only if present.
+func NewSignAction(
+	rc *run.Context,
+	s *Sign,
+	name string,
+	optional ...string,
+) action.WithDescription[action.Function] {
+	fn := func(ctx context.Context) error {
+		tmp, err := rc.TempDir()
+		if err != nil {
+			return err
+		}
+
+		args := []string{"sign", "-h", "sha256"}
+
+		for _, input := range []struct {
+			env, flag, name string
+		}{
+			{envSignCertificate, "-certs", "sign.crt"},
+			{envSignKey, "-key", "sign.key"},
+			{envSignPassword, "-readpass", "sign.pass"},
+		} {
+			value := os.Getenv(input.env)
+			if value == "" {
+				continue
+			}
+
+			path := filepath.Join(tmp, input.name)
+
+			if err := os.WriteFile(path, []byte(value), osutil.ModeUserRW); err != nil {
+				return err
+			}
+
+			args = append(args, input.flag, path)
+		}
+
+		if s.Description != "" {
+			args = append(args, "-n", s.Description)
+		}
+
+		if s.URL != "" {
+			args = append(args, "-i", s.URL)
+		}
+
+		if s.TimestampURL != "" {
+			args = append(args, "-ts", s.TimestampURL)
+		}
+
+		for i, exe := range append([]string{name}, optional...) {
+			path := rc.PathOut.Join(exe)
+
+			if err := path.CheckIsFile(); err != nil {
+				if i > 0 && errors.Is(err, os.ErrNotExist) {
+					continue
+				}
+
+				return fmt.Errorf("cannot sign executable: %w", err)
+			}
+
+			if err := signExecutable(ctx, rc, s, args, path.String()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return action.WithDescription[action.Function]{
+		Action: fn,
+		Description: "sign executables with Authenticode: " +
+			strings.Join(append([]string{name}, optional...), ","),
+	}
+}
+
+/* ------------------------ Function: signExecutable ------------------------ */
+
+func signExecutable(
+	ctx context.Context,
+	rc *run.Context,
+	s *Sign,
+	args []string,
+	path string,
+) error {
+	cmd := slices.Clone(s.Command)
+	if len(cmd) == 0 {
+		cmd = append(cmd, "osslsigncode")
+	}
+
+	cmd = append(cmd, args...)
+	cmd = append(cmd, "-in", path, "-out", path+".signed")
+
+	// NOTE: Run the command directly so that arguments (e.g. a description
+	// containing spaces) aren't interpreted by a shell.
+	process := &action.Process{
+		Directory:   rc.PathOut.String(),
+		Environment: nil,
+
+		Shell:   exec.ShellNone,
+		Verbose: rc.Verbose,
+
+		Args: cmd,
+	}
+
+	if err := process.Run(ctx); err != nil {
+		return err
+	}
+
+	return os.Rename(path+".signed", path)
+}
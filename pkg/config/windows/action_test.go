@@ -0,0 +1,63 @@
+package windows_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/config/windows"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+func TestNewSignAction(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires a POSIX shell")
+	}
+
+	// Given: An output directory, containing a space, with an executable.
+	pathOut := filepath.Join(t.TempDir(), "out dir")
+	require.NoError(t, os.MkdirAll(pathOut, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(pathOut, "game.exe"), []byte("exe"), 0o600))
+
+	// Given: A fake signing tool which records its arguments.
+	pathArgs := filepath.Join(t.TempDir(), "args.txt")
+	pathTool := filepath.Join(t.TempDir(), "osslsigncode")
+
+	script := "#!/bin/sh\nfor a; do out=$a; done\nprintf '%s\\n' \"$@\" > '" + pathArgs + "'\ntouch \"$out\"\n"
+	require.NoError(t, os.WriteFile(pathTool, []byte(script), 0o700)) //nolint:gosec
+
+	// Given: A signing configuration with values containing spaces and shell
+	// metacharacters.
+	sign := windows.Sign{ //nolint:exhaustruct
+		Command:     []string{pathTool},
+		Description: "My Game; echo 'pwned'",
+		URL:         "https://example.com/?a=1&b=2",
+	}
+
+	rc := run.Context{PathOut: osutil.Path(pathOut)} //nolint:exhaustruct
+
+	// When: The executables are signed.
+	err := windows.NewSignAction(&rc, &sign, "game.exe", "game.console.exe").Run(context.Background())
+
+	// Then: There's no error.
+	require.NoError(t, err)
+
+	// Then: The arguments were passed through unchanged.
+	got, err := os.ReadFile(pathArgs)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"sign", "-h", "sha256",
+		"-n", "My Game; echo 'pwned'",
+		"-i", "https://example.com/?a=1&b=2",
+		"-in", filepath.Join(pathOut, "game.exe"),
+		"-out", filepath.Join(pathOut, "game.exe") + ".signed",
+	}, strings.Split(strings.TrimSpace(string(got)), "\n"))
+}
@@ -1,8 +1,15 @@
 package windows
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/coffeebeats/gdbuild/internal/action"
 	"github.com/coffeebeats/gdbuild/internal/config"
 	"github.com/coffeebeats/gdbuild/pkg/config/common"
 	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
@@ -11,18 +18,56 @@ import (
 	"github.com/coffeebeats/gdbuild/pkg/run"
 )
 
+const (
+	envSignCertificate = "GDBUILD_WINDOWS_SIGN_CERTIFICATE"
+	envSignKey         = "GDBUILD_WINDOWS_SIGN_KEY"
+	envSignPassword    = "GDBUILD_WINDOWS_SIGN_PASSWORD" //nolint:gosec
+)
+
+var ErrMissingInput = errors.New("missing input")
+
 /* -------------------------------------------------------------------------- */
 /*                               Struct: Target                               */
 /* -------------------------------------------------------------------------- */
 
 type Target struct {
 	*common.Target
+
+	// Sign defines how the exported executables are code signed.
+	Sign Sign `toml:"sign"`
 }
 
 /* ----------------------------- Impl: Exporter ----------------------------- */
 
 func (t *Target) Collect(rc *run.Context, tl *template.Template, ev engine.Version) *export.Export {
-	return t.Target.Collect(rc, tl, ev)
+	out := t.Target.Collect(rc, tl, ev)
+
+	if !config.Dereference(t.Sign.Enabled) {
+		return out
+	}
+
+	for i, pf := range out.PackFiles {
+		if !config.Dereference(pf.Embed) {
+			continue
+		}
+
+		name := pf.Filename(rc.Platform, rc.Target, i)
+
+		out.SigningFingerprint = t.Sign.Fingerprint()
+		out.Postexport = action.InOrder(
+			out.Postexport,
+			NewSignAction(
+				rc,
+				&t.Sign,
+				name,
+				strings.TrimSuffix(name, ".exe")+".console.exe",
+			),
+		)
+
+		break
+	}
+
+	return out
 }
 
 /* ------------------------- Impl: config.Configurer ------------------------ */
@@ -34,7 +79,30 @@ func (t *Target) Configure(rc *run.Context) error {
 /* ------------------------- Impl: config.Validator ------------------------- */
 
 func (t *Target) Validate(rc *run.Context) error {
-	return t.Target.Validate(rc)
+	if err := t.Target.Validate(rc); err != nil {
+		return err
+	}
+
+	if err := t.Sign.Validate(rc); err != nil {
+		return err
+	}
+
+	if config.Dereference(t.Sign.Enabled) {
+		hasEmbed := false
+
+		for _, pf := range t.PackFiles {
+			hasEmbed = hasEmbed || config.Dereference(pf.Embed)
+		}
+
+		if !hasEmbed {
+			return fmt.Errorf(
+				"%w: code signing requires an embedded pack file",
+				config.ErrInvalidInput,
+			)
+		}
+	}
+
+	return nil
 }
 
 /* --------------------------- Impl: config.Merger -------------------------- */
@@ -57,6 +125,83 @@ func (t *Target) MergeInto(other any) error {
 	return config.Merge(dst, *t)
 }
 
+/* -------------------------------------------------------------------------- */
+/*                                Struct: Sign                                */
+/* -------------------------------------------------------------------------- */
+
+// Sign defines the Authenticode signing settings for exported Windows
+// executables. Signing is performed by 'osslsigncode', which runs on any host
+// platform.
+//
+// The PEM-encoded certificate and private key are read from the environment
+// variables 'GDBUILD_WINDOWS_SIGN_CERTIFICATE' and 'GDBUILD_WINDOWS_SIGN_KEY',
+// respectively. If the key is encrypted, its password is read from the
+// 'GDBUILD_WINDOWS_SIGN_PASSWORD' environment variable.
+type Sign struct {
+	// Command contains arguments used to invoke 'osslsigncode'. Defaults to
+	// ["osslsigncode"].
+	Command []string `toml:"command"`
+
+	// Description is the description of the signed content (e.g. the name of
+	// the application), shown in the Windows UAC prompt.
+	Description string `toml:"description"`
+
+	// Enabled determines whether the exported executables are signed.
+	Enabled *bool `toml:"enabled"`
+
+	// TimestampURL is the URL of an RFC 3161 timestamp server used to
+	// countersign the signature.
+	TimestampURL string `toml:"timestamp_url"`
+
+	// URL is a URL with more information about the signed content.
+	URL string `toml:"url"`
+}
+
+/* --------------------------- Method: Fingerprint -------------------------- */
+
+// Fingerprint returns the SHA-256 fingerprint of the signing certificate set
+// in the environment. If no valid certificate is set, an empty string is
+// returned.
+func (s *Sign) Fingerprint() string {
+	block, _ := pem.Decode([]byte(os.Getenv(envSignCertificate)))
+	if block == nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(block.Bytes)
+
+	return hex.EncodeToString(sum[:])
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (s *Sign) Validate(_ *run.Context) error {
+	if !config.Dereference(s.Enabled) {
+		return nil
+	}
+
+	block, _ := pem.Decode([]byte(os.Getenv(envSignCertificate)))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return fmt.Errorf(
+			"%w: PEM-encoded certificate must be set via environment variable: %s",
+			ErrMissingInput,
+			envSignCertificate,
+		)
+	}
+
+	if block, _ := pem.Decode([]byte(os.Getenv(envSignKey))); block == nil {
+		return fmt.Errorf(
+			"%w: PEM-encoded private key must be set via environment variable: %s",
+			ErrMissingInput,
+			envSignKey,
+		)
+	}
+
+	// NOTE: Don't check for 'osslsigncode', that should be a runtime check.
+
+	return nil
+}
+
 /* -------------------------------------------------------------------------- */
 /*                    Struct: TargetWithFeaturesAndProfile                    */
 /* -------------------------------------------------------------------------- */
@@ -0,0 +1,101 @@
+package windows_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coffeebeats/gdbuild/pkg/config/windows"
+)
+
+func TestSignValidate(t *testing.T) {
+	cert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("cert")}))
+	key := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")}))
+
+	tests := []struct {
+		name string
+
+		sign windows.Sign
+		cert string
+		key  string
+
+		err error
+	}{
+		{
+			name: "disabled signing requires no inputs",
+
+			sign: windows.Sign{},
+		},
+		{
+			name: "enabled signing with certificate and key succeeds",
+
+			sign: windows.Sign{Enabled: pointer(true)},
+			cert: cert,
+			key:  key,
+		},
+		{
+			name: "enabled signing without certificate fails",
+
+			sign: windows.Sign{Enabled: pointer(true)},
+			key:  key,
+
+			err: windows.ErrMissingInput,
+		},
+		{
+			name: "enabled signing with a key in place of a certificate fails",
+
+			sign: windows.Sign{Enabled: pointer(true)},
+			cert: key,
+			key:  key,
+
+			err: windows.ErrMissingInput,
+		},
+		{
+			name: "enabled signing without key fails",
+
+			sign: windows.Sign{Enabled: pointer(true)},
+			cert: cert,
+
+			err: windows.ErrMissingInput,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: The signing inputs are set in the environment.
+			t.Setenv("GDBUILD_WINDOWS_SIGN_CERTIFICATE", tc.cert)
+			t.Setenv("GDBUILD_WINDOWS_SIGN_KEY", tc.key)
+
+			// When: The signing configuration is validated.
+			err := tc.sign.Validate(nil)
+
+			// Then: The resulting error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestSignFingerprint(t *testing.T) {
+	// Given: A certificate set in the environment.
+	t.Setenv(
+		"GDBUILD_WINDOWS_SIGN_CERTIFICATE",
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("cert")})),
+	)
+
+	// When: The fingerprint is computed.
+	got := (&windows.Sign{}).Fingerprint()
+
+	// Then: The fingerprint is the SHA-256 digest of the DER bytes.
+	want := sha256.Sum256([]byte("cert"))
+	assert.Equal(t, hex.EncodeToString(want[:]), got)
+}
+
+func pointer[T any](value T) *T {
+	return &value
+}
@@ -21,6 +21,13 @@ import (
 type Template struct {
 	*common.Template
 
+	// Toolchain defines settings for cross-compiling the export template.
+	Toolchain Toolchain `toml:"toolchain"`
+
+	// UseLLVM determines whether the LLVM compiler is used. This is required
+	// when building for 'arm64' with MinGW (i.e. via 'llvm-mingw').
+	UseLLVM *bool `toml:"use_llvm"`
+
 	// UseMinGW determines whether the MinGW compiler is used.
 	UseMinGW *bool `toml:"use_mingw"`
 
@@ -49,6 +56,14 @@ func (t *Template) Collect(g engine.Source, rc *run.Context) *template.Template
 		scons.ExtraArgs = append(scons.ExtraArgs, "use_mingw=yes")
 	}
 
+	if config.Dereference(t.UseLLVM) {
+		scons.ExtraArgs = append(scons.ExtraArgs, "use_llvm=yes")
+	}
+
+	if t.Toolchain.PathMinGWPrefix != "" {
+		scons.ExtraArgs = append(scons.ExtraArgs, "mingw_prefix="+t.Toolchain.PathMinGWPrefix.String())
+	}
+
 	if t.PathIcon != "" {
 		out.RegisterDependencyPath(t.PathIcon)
 
@@ -76,6 +91,10 @@ func (t *Template) Configure(rc *run.Context) error {
 		return err
 	}
 
+	if err := t.Toolchain.PathMinGWPrefix.RelTo(rc.PathManifest); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -86,10 +105,28 @@ func (t *Template) Validate(rc *run.Context) error {
 		return err
 	}
 
-	if !t.Arch.IsOneOf(platform.ArchAmd64, platform.ArchI386, platform.ArchUnknown) {
+	if !t.Arch.IsOneOf(
+		platform.ArchAmd64,
+		platform.ArchArm64,
+		platform.ArchI386,
+		platform.ArchUnknown,
+	) {
 		return fmt.Errorf("%w: unsupport architecture: %s", config.ErrInvalidInput, t.Arch)
 	}
 
+	if t.Arch == platform.ArchArm64 &&
+		config.Dereference(t.UseMinGW) &&
+		!config.Dereference(t.UseLLVM) {
+		return fmt.Errorf(
+			"%w: building for 'arm64' with MinGW requires 'use_llvm'",
+			config.ErrInvalidInput,
+		)
+	}
+
+	if err := t.Toolchain.PathMinGWPrefix.CheckIsDirOrEmpty(); err != nil {
+		return fmt.Errorf("%w: invalid MinGW prefix path", err)
+	}
+
 	// NOTE: Don't check if icon exists since it might be generated by a hook.
 
 	return nil
@@ -115,6 +152,18 @@ func (t *Template) MergeInto(other any) error {
 	return config.Merge(dst, *t)
 }
 
+/* -------------------------------------------------------------------------- */
+/*                              Struct: Toolchain                             */
+/* -------------------------------------------------------------------------- */
+
+// Toolchain defines the settings required to cross-compile a Windows export
+// template (e.g. using MinGW from a Linux host).
+type Toolchain struct {
+	// PathMinGWPrefix is a path to the MinGW toolchain's installation prefix
+	// (e.g. the root of an 'llvm-mingw' release for 'arm64' builds).
+	PathMinGWPrefix osutil.Path `toml:"mingw_prefix"`
+}
+
 /* -------------------------------------------------------------------------- */
 /*                   Struct: TemplateWithFeaturesAndProfile                   */
 /* -------------------------------------------------------------------------- */
@@ -0,0 +1,494 @@
+package delta
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	// magic identifies a binary patch produced by 'Diff'.
+	magic = "GDBDIFF1"
+
+	// maxCompressionRatio is the maximum ratio by which 'flate' can compress
+	// data. Every byte of the new contents is encoded in a patch's compressed
+	// instructions, so a patch can't describe contents larger than this
+	// multiple of its size.
+	maxCompressionRatio = 1032
+	// maxOldSize is the maximum size of the old contents which can be diffed;
+	// the suffix array uses 32-bit indices, including one for the empty suffix.
+	maxOldSize = math.MaxInt32 - 1
+)
+
+var ErrInvalidPatch = errors.New("invalid patch")
+
+/* -------------------------------------------------------------------------- */
+/*                               Function: Diff                               */
+/* -------------------------------------------------------------------------- */
+
+// Diff computes a binary patch which transforms 'old' into 'updated' using the
+// bsdiff algorithm (see https://www.daemonology.net/bsdiff). The patch format
+// is specific to 'gdbuild':
+//
+//	| magic (8 bytes) | new size (u64) | flate-compressed instructions |
+//
+// where each instruction is a sequence of three signed varints '(x, y, z)'
+// followed by 'x' bytes to add to the old contents, then 'y' bytes to insert,
+// after which the position in the old contents is moved by 'z' bytes. The
+// output is deterministic for the same inputs.
+//
+// NOTE: Diffing requires memory proportional to the size of 'old' (roughly 9
+// times its size), so large files should be replaced instead (see
+// 'MaxDiffSize').
+func Diff(old, updated []byte) ([]byte, error) {
+	if len(old) > maxOldSize {
+		return nil, fmt.Errorf("%w: old contents are too large to diff: %d bytes", ErrInvalidInput, len(old))
+	}
+
+	var out bytes.Buffer
+
+	out.WriteString(magic)
+
+	if err := binary.Write(&out, binary.LittleEndian, uint64(len(updated))); err != nil {
+		return nil, err
+	}
+
+	w, err := flate.NewWriter(&out, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := diff(old, updated, w); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                               Function: Apply                              */
+/* -------------------------------------------------------------------------- */
+
+// Apply applies a patch produced by 'Diff' to 'old', returning the updated
+// contents.
+func Apply(old, patch []byte) ([]byte, error) { //nolint:cyclop
+	if len(patch) < len(magic)+8 || string(patch[:len(magic)]) != magic {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalidPatch)
+	}
+
+	size := binary.LittleEndian.Uint64(patch[len(magic):])
+	body := patch[len(magic)+8:]
+
+	// NOTE: The new size is untrusted, so reject sizes the patch can't possibly
+	// describe rather than allocating them up front.
+	if size > math.MaxInt64 || size/maxCompressionRatio > uint64(len(body)) {
+		return nil, fmt.Errorf("%w: new size exceeds patch contents: %d bytes", ErrInvalidPatch, size)
+	}
+
+	r := bufio.NewReader(flate.NewReader(bytes.NewReader(body)))
+
+	// NOTE: Only preallocate up to the size of the old contents; the buffer is
+	// otherwise grown as the patch's contents are read.
+	var updated bytes.Buffer
+
+	updated.Grow(int(min(size, uint64(len(old)))))
+
+	var posOld, posNew int64
+
+	for posNew < int64(size) { //nolint:gosec
+		var ctrl [3]int64
+
+		for i := range ctrl {
+			v, err := binary.ReadVarint(r)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+			}
+
+			ctrl[i] = v
+		}
+
+		x, y, z := ctrl[0], ctrl[1], ctrl[2]
+
+		if x < 0 || y < 0 || x > int64(size)-posNew || y > int64(size)-posNew-x { //nolint:gosec
+			return nil, fmt.Errorf("%w: invalid instruction", ErrInvalidPatch)
+		}
+
+		// Add the difference bytes to the old contents.
+		if _, err := io.CopyN(&updated, r, x); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+		}
+
+		added := updated.Bytes()[posNew:]
+
+		for i := range x {
+			if p := posOld + i; p >= 0 && p < int64(len(old)) {
+				added[i] += old[p]
+			}
+		}
+
+		posNew += x
+		posOld += x
+
+		// Copy the extra bytes.
+		if _, err := io.CopyN(&updated, r, y); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidPatch, err)
+		}
+
+		posNew += y
+		posOld += z
+	}
+
+	return updated.Bytes(), nil
+}
+
+/* ------------------------------ Function: diff ----------------------------- */
+
+func diff(old, updated []byte, w io.Writer) error { //nolint:cyclop,funlen,gocognit
+	sa := suffixArray(old)
+
+	bw := bufio.NewWriter(w)
+
+	var buf [binary.MaxVarintLen64]byte
+
+	writeVarint := func(v int) error {
+		n := binary.PutVarint(buf[:], int64(v))
+		_, err := bw.Write(buf[:n])
+
+		return err
+	}
+
+	var scan, pos, length, lastScan, lastPos, lastOffset int
+
+	for scan < len(updated) {
+		var oldScore int
+
+		scan += length
+
+		for scsc := scan; scan < len(updated); scan++ {
+			pos, length = search(sa, old, updated[scan:], 0, len(old))
+
+			for ; scsc < scan+length; scsc++ {
+				if scsc+lastOffset < len(old) && old[scsc+lastOffset] == updated[scsc] {
+					oldScore++
+				}
+			}
+
+			if (length == oldScore && length != 0) || length > oldScore+8 {
+				break
+			}
+
+			if scan+lastOffset < len(old) && old[scan+lastOffset] == updated[scan] {
+				oldScore--
+			}
+		}
+
+		if length == oldScore && scan != len(updated) {
+			continue
+		}
+
+		// Extend the match forwards from the last match.
+		var lenf int
+
+		for i, s, sf := 0, 0, 0; lastScan+i < scan && lastPos+i < len(old); {
+			if old[lastPos+i] == updated[lastScan+i] {
+				s++
+			}
+
+			i++
+
+			if s*2-i > sf*2-lenf {
+				sf, lenf = s, i
+			}
+		}
+
+		// Extend the match backwards from the current match.
+		var lenb int
+
+		if scan < len(updated) {
+			for i, s, sb := 1, 0, 0; scan >= lastScan+i && pos >= i; i++ {
+				if old[pos-i] == updated[scan-i] {
+					s++
+				}
+
+				if s*2-i > sb*2-lenb {
+					sb, lenb = s, i
+				}
+			}
+		}
+
+		// Resolve any overlap between the two extensions.
+		if lastScan+lenf > scan-lenb {
+			overlap := (lastScan + lenf) - (scan - lenb)
+
+			var s, ss, lens int
+
+			for i := range overlap {
+				if updated[lastScan+lenf-overlap+i] == old[lastPos+lenf-overlap+i] {
+					s++
+				}
+
+				if updated[scan-lenb+i] == old[pos-lenb+i] {
+					s--
+				}
+
+				if s > ss {
+					ss, lens = s, i+1
+				}
+			}
+
+			lenf += lens - overlap
+			lenb -= lens
+		}
+
+		lenExtra := (scan - lenb) - (lastScan + lenf)
+
+		for _, v := range []int{lenf, lenExtra, (pos - lenb) - (lastPos + lenf)} {
+			if err := writeVarint(v); err != nil {
+				return err
+			}
+		}
+
+		for i := range lenf {
+			if err := bw.WriteByte(updated[lastScan+i] - old[lastPos+i]); err != nil {
+				return err
+			}
+		}
+
+		if _, err := bw.Write(updated[lastScan+lenf : lastScan+lenf+lenExtra]); err != nil {
+			return err
+		}
+
+		lastScan = scan - lenb
+		lastPos = pos - lenb
+		lastOffset = pos - scan
+	}
+
+	return bw.Flush()
+}
+
+/* ----------------------------- Function: search ---------------------------- */
+
+// search finds the longest match of 'target' in 'old' using the suffix array
+// 'sa', returning the position and length of the match.
+func search(sa []int32, old, target []byte, start, end int) (int, int) {
+	for end-start >= 2 { //nolint:gomnd
+		mid := start + (end-start)/2
+
+		pos := int(sa[mid])
+
+		n := min(len(old)-pos, len(target))
+		if bytes.Compare(old[pos:pos+n], target[:n]) < 0 {
+			start = mid
+		} else {
+			end = mid
+		}
+	}
+
+	x := matchLen(old[sa[start]:], target)
+	y := matchLen(old[sa[end]:], target)
+
+	if x > y {
+		return int(sa[start]), x
+	}
+
+	return int(sa[end]), y
+}
+
+/* ---------------------------- Function: matchLen -------------------------- */
+
+func matchLen(a, b []byte) int {
+	n := min(len(a), len(b))
+
+	for i := range n {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+
+	return n
+}
+
+/* -------------------------- Function: suffixArray -------------------------- */
+
+// suffixArray constructs the suffix array of 'data' (including the empty
+// suffix) using the Larsson-Sadakane algorithm, as used by bsdiff. Indices are
+// stored as 32-bit integers to halve memory usage, so 'data' must be smaller
+// than 'maxOldSize'.
+func suffixArray(data []byte) []int32 { //nolint:cyclop,funlen
+	n := int32(len(data)) //nolint:gosec
+
+	sa := make([]int32, n+1)
+	rank := make([]int32, n+1)
+
+	var buckets [256]int32
+
+	for _, b := range data {
+		buckets[b]++
+	}
+
+	for i := 1; i < 256; i++ {
+		buckets[i] += buckets[i-1]
+	}
+
+	for i := 255; i > 0; i-- {
+		buckets[i] = buckets[i-1]
+	}
+
+	buckets[0] = 0
+
+	for i, b := range data {
+		buckets[b]++
+		sa[buckets[b]] = int32(i) //nolint:gosec
+	}
+
+	sa[0] = n
+
+	for i, b := range data {
+		rank[i] = buckets[b]
+	}
+
+	rank[n] = 0
+
+	for i := 1; i < 256; i++ {
+		if buckets[i] == buckets[i-1]+1 {
+			sa[buckets[i]] = -1
+		}
+	}
+
+	sa[0] = -1
+
+	for h := int32(1); sa[0] != -(n + 1); h += h {
+		var i, length int32
+
+		for i < n+1 {
+			if sa[i] < 0 {
+				length -= sa[i]
+				i -= sa[i]
+
+				continue
+			}
+
+			if length > 0 {
+				sa[i-length] = -length
+			}
+
+			length = rank[sa[i]] + 1 - i
+			split(sa, rank, i, length, h)
+			i += length
+			length = 0
+		}
+
+		if length > 0 {
+			sa[i-length] = -length
+		}
+	}
+
+	for i := range n + 1 {
+		sa[rank[i]] = i
+	}
+
+	return sa
+}
+
+/* ----------------------------- Function: split ----------------------------- */
+
+func split(sa, rank []int32, start, length, h int32) { //nolint:cyclop,funlen
+	if length < 16 { //nolint:gomnd
+		for k := start; k < start+length; {
+			j := int32(1)
+			x := rank[sa[k]+h]
+
+			for i := int32(1); k+i < start+length; i++ {
+				if rank[sa[k+i]+h] < x {
+					x = rank[sa[k+i]+h]
+					j = 0
+				}
+
+				if rank[sa[k+i]+h] == x {
+					sa[k+j], sa[k+i] = sa[k+i], sa[k+j]
+					j++
+				}
+			}
+
+			for i := range j {
+				rank[sa[k+i]] = k + j - 1
+			}
+
+			if j == 1 {
+				sa[k] = -1
+			}
+
+			k += j
+		}
+
+		return
+	}
+
+	x := rank[sa[start+length/2]+h]
+
+	var jj, kk int32
+
+	for i := start; i < start+length; i++ {
+		if rank[sa[i]+h] < x {
+			jj++
+		}
+
+		if rank[sa[i]+h] == x {
+			kk++
+		}
+	}
+
+	jj += start
+	kk += jj
+
+	var j, k int32
+
+	i := start
+
+	for i < jj {
+		switch v := rank[sa[i]+h]; {
+		case v < x:
+			i++
+		case v == x:
+			sa[i], sa[jj+j] = sa[jj+j], sa[i]
+			j++
+		default:
+			sa[i], sa[kk+k] = sa[kk+k], sa[i]
+			k++
+		}
+	}
+
+	for jj+j < kk {
+		if rank[sa[jj+j]+h] == x {
+			j++
+		} else {
+			sa[jj+j], sa[kk+k] = sa[kk+k], sa[jj+j]
+			k++
+		}
+	}
+
+	if jj > start {
+		split(sa, rank, start, jj-start, h)
+	}
+
+	for i := range kk - jj {
+		rank[sa[jj+i]] = kk - 1
+	}
+
+	if jj == kk-1 {
+		sa[jj] = -1
+	}
+
+	if start+length > kk {
+		split(sa, rank, kk, start+length-kk, h)
+	}
+}
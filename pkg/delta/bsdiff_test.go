@@ -0,0 +1,127 @@
+package delta_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/pkg/delta"
+)
+
+func TestDiffApply(t *testing.T) {
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec
+
+	random := func(n int) []byte {
+		out := make([]byte, n)
+		_, _ = rng.Read(out)
+
+		return out
+	}
+
+	base := random(64 * 1024)
+
+	// modified inserts, removes, and changes sections of 'base'.
+	modified := bytes.Clone(base)
+	modified = append(modified[:1000], append(random(500), modified[1000:]...)...)
+	modified = append(modified[:20000], modified[24000:]...)
+	copy(modified[40000:], bytes.Repeat([]byte{0xff}, 100))
+
+	tests := []struct {
+		name string
+
+		old, updated []byte
+	}{
+		{name: "empty inputs", old: nil, updated: nil},
+		{name: "empty old contents", old: nil, updated: random(1000)},
+		{name: "empty new contents", old: random(1000), updated: nil},
+		{name: "identical contents", old: base, updated: base},
+		{name: "modified contents", old: base, updated: modified},
+		{name: "repetitive contents", old: bytes.Repeat([]byte("abc"), 1000), updated: bytes.Repeat([]byte("abcd"), 1000)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: A patch is computed.
+			patch, err := delta.Diff(tc.old, tc.updated)
+			require.NoError(t, err)
+
+			// Then: The patch is deterministic.
+			again, err := delta.Diff(tc.old, tc.updated)
+			require.NoError(t, err)
+			assert.Equal(t, patch, again)
+
+			// Then: Applying the patch reproduces the new contents.
+			got, err := delta.Apply(tc.old, patch)
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.updated), len(got))
+			assert.True(t, bytes.Equal(tc.updated, got))
+		})
+	}
+
+	// Then: Small changes produce small patches.
+	patch, err := delta.Diff(base, modified)
+	require.NoError(t, err)
+	assert.Less(t, len(patch), 4*1024)
+}
+
+func TestApplyInvalid(t *testing.T) {
+	valid, err := delta.Diff([]byte("old"), []byte("new"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+
+		patch func([]byte) []byte
+	}{
+		{
+			name: "corrupted header",
+			patch: func(p []byte) []byte {
+				p[0] = 'X'
+
+				return p
+			},
+		},
+		{
+			name: "truncated header",
+			patch: func(p []byte) []byte {
+				return p[:10]
+			},
+		},
+		{
+			name: "new size exceeds patch contents",
+			patch: func(p []byte) []byte {
+				binary.LittleEndian.PutUint64(p[8:], 1<<40)
+
+				return p
+			},
+		},
+		{
+			name: "new size exceeds instructions",
+			patch: func(p []byte) []byte {
+				binary.LittleEndian.PutUint64(p[8:], 4)
+
+				return p
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: An invalid patch.
+			patch := tc.patch(bytes.Clone(valid))
+
+			// When: The patch is applied.
+			_, err := delta.Apply([]byte("old"), patch)
+
+			// Then: The patch is rejected.
+			if !errors.Is(err, delta.ErrInvalidPatch) {
+				t.Fatalf("output: got %v, want %v", err, delta.ErrInvalidPatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,477 @@
+package delta
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+)
+
+const (
+	// FilenameIndex is the name of the patch index within the output directory.
+	FilenameIndex = "index.json"
+
+	// dirFiles is the directory within the output directory containing the
+	// patches and new files.
+	dirFiles = "files"
+	// extPatch is the file extension of a binary patch.
+	extPatch = ".gdbdiff"
+
+	// indexVersion is the current version of the patch index format.
+	indexVersion = 1
+
+	// MaxDiffSize is the maximum size of a file which is diffed. Computing a
+	// patch requires several times a file's size in memory, so changed files
+	// larger than this are replaced instead (see 'ActionReplace').
+	MaxDiffSize = 256 << 20
+)
+
+// Actions which an updater must take for each file; see 'Entry.Action'.
+const (
+	// ActionAdd means the file is new and should be copied from 'Data'.
+	ActionAdd = "add"
+	// ActionKeep means the file is unchanged.
+	ActionKeep = "keep"
+	// ActionPatch means the patch at 'Data' should be applied to the file.
+	ActionPatch = "patch"
+	// ActionRemove means the file should be deleted.
+	ActionRemove = "remove"
+	// ActionReplace means the file changed but a patch would be larger than
+	// the new file (or the file is too large to diff; see 'MaxDiffSize'), so
+	// it should be replaced with the file at 'Data'.
+	ActionReplace = "replace"
+)
+
+var (
+	ErrInvalidInput     = errors.New("invalid input")
+	ErrDigestMismatch   = errors.New("digest mismatch")
+	ErrUnsupportedIndex = errors.New("unsupported patch index")
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                Struct: Index                               */
+/* -------------------------------------------------------------------------- */
+
+// Index describes how to update each file of an old build to a new build.
+type Index struct {
+	// Version is the version of the patch index format.
+	Version int `json:"version"`
+	// Files contains an entry for each file in either build, sorted by path.
+	Files []Entry `json:"files"`
+}
+
+/* ------------------------------ Struct: Entry ------------------------------ */
+
+// Entry describes how to update a single file. Paths are relative to the
+// build's root directory and use forward slashes.
+type Entry struct {
+	// Path is the path of the file within the build.
+	Path string `json:"path"`
+	// Action is the action to take to update the file.
+	Action string `json:"action"`
+	// Mode contains the permission bits of the new file.
+	Mode fs.FileMode `json:"mode,omitempty"`
+
+	// SourceSHA256 is the hex-encoded SHA-256 digest of the old file.
+	SourceSHA256 string `json:"source_sha256,omitempty"`
+	// SourceSize is the size of the old file in bytes.
+	SourceSize int64 `json:"source_size,omitempty"`
+	// TargetSHA256 is the hex-encoded SHA-256 digest of the new file.
+	TargetSHA256 string `json:"target_sha256,omitempty"`
+	// TargetSize is the size of the new file in bytes.
+	TargetSize int64 `json:"target_size,omitempty"`
+
+	// Data is the path, relative to the index, of the patch or new file.
+	Data string `json:"data,omitempty"`
+	// DataSHA256 is the hex-encoded SHA-256 digest of the file at 'Data'.
+	DataSHA256 string `json:"data_sha256,omitempty"`
+}
+
+/* -------------------------------------------------------------------------- */
+/*                              Function: Create                              */
+/* -------------------------------------------------------------------------- */
+
+// Create writes binary patches which update the build in 'pathOld' to the
+// build in 'pathNew' into the directory 'out', along with an index describing
+// them. The output is deterministic for the same inputs.
+func Create(ctx context.Context, pathOld, pathNew, out string) (Index, error) {
+	filesOld, err := listFiles(pathOld)
+	if err != nil {
+		return Index{}, err
+	}
+
+	filesNew, err := listFiles(pathNew)
+	if err != nil {
+		return Index{}, err
+	}
+
+	paths := make([]string, 0, len(filesOld)+len(filesNew))
+	for p := range filesOld {
+		paths = append(paths, p)
+	}
+
+	for p := range filesNew {
+		if _, ok := filesOld[p]; !ok {
+			paths = append(paths, p)
+		}
+	}
+
+	slices.Sort(paths)
+
+	idx := Index{Version: indexVersion, Files: make([]Entry, 0, len(paths))}
+
+	for _, p := range paths {
+		if err := ctx.Err(); err != nil {
+			return Index{}, err
+		}
+
+		e, err := createEntry(
+			p,
+			filepath.Join(pathOld, filepath.FromSlash(p)),
+			filepath.Join(pathNew, filepath.FromSlash(p)),
+			out,
+			filesOld,
+			filesNew,
+		)
+		if err != nil {
+			return Index{}, err
+		}
+
+		idx.Files = append(idx.Files, e)
+	}
+
+	contents, err := json.MarshalIndent(idx, "", "  ")
+	if err != nil {
+		return Index{}, err
+	}
+
+	if err := writeFile(filepath.Join(out, FilenameIndex), append(contents, '\n')); err != nil {
+		return Index{}, err
+	}
+
+	return idx, nil
+}
+
+/* --------------------------- Function: createEntry ------------------------ */
+
+// createEntry determines how to update the file at the slash-separated path
+// 'p', located at 'pathOld' in the old build and 'pathNew' in the new build,
+// and writes any required patch or new file into 'out'.
+func createEntry( //nolint:cyclop,funlen
+	p, pathOld, pathNew, out string,
+	filesOld, filesNew map[string]fs.FileMode,
+) (Entry, error) {
+	var e Entry
+
+	e.Path = p
+
+	var err error
+
+	if _, ok := filesOld[p]; ok {
+		e.SourceSHA256, e.SourceSize, err = digestFile(pathOld)
+		if err != nil {
+			return Entry{}, err
+		}
+	}
+
+	mode, ok := filesNew[p]
+	if !ok {
+		e.Action = ActionRemove
+
+		return e, nil
+	}
+
+	e.Mode = mode.Perm()
+
+	e.TargetSHA256, e.TargetSize, err = digestFile(pathNew)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	switch {
+	case e.SourceSHA256 == "":
+		e.Action = ActionAdd
+	case e.SourceSHA256 == e.TargetSHA256:
+		e.Action = ActionKeep
+
+		return e, nil
+	case e.SourceSize > MaxDiffSize || e.TargetSize > MaxDiffSize:
+		log.Debugf("file too large to diff; replacing: %s", p)
+
+		e.Action = ActionReplace
+	default:
+		log.Debugf("computing binary patch: %s", p)
+
+		contentsOld, err := os.ReadFile(pathOld)
+		if err != nil {
+			return Entry{}, err
+		}
+
+		contentsNew, err := os.ReadFile(pathNew)
+		if err != nil {
+			return Entry{}, err
+		}
+
+		patch, err := Diff(contentsOld, contentsNew)
+		if err != nil {
+			return Entry{}, err
+		}
+
+		if len(patch) < len(contentsNew) {
+			e.Action = ActionPatch
+			e.Data = dirFiles + "/" + p + extPatch
+			e.DataSHA256 = digest(patch)
+
+			return e, writeFile(filepath.Join(out, filepath.FromSlash(e.Data)), patch)
+		}
+
+		e.Action = ActionReplace
+	}
+
+	e.Data = dirFiles + "/" + p
+	e.DataSHA256 = e.TargetSHA256
+
+	return e, copyFile(pathNew, filepath.Join(out, filepath.FromSlash(e.Data)), e.TargetSHA256)
+}
+
+/* -------------------------------------------------------------------------- */
+/*                             Function: ReadIndex                            */
+/* -------------------------------------------------------------------------- */
+
+// ReadIndex parses the patch index in the directory 'path'.
+func ReadIndex(path string) (Index, error) {
+	data, err := os.ReadFile(filepath.Join(path, FilenameIndex))
+	if err != nil {
+		return Index{}, err
+	}
+
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return Index{}, fmt.Errorf("%w: %w", ErrUnsupportedIndex, err)
+	}
+
+	if idx.Version != indexVersion {
+		return Index{}, fmt.Errorf("%w: version %d", ErrUnsupportedIndex, idx.Version)
+	}
+
+	return idx, nil
+}
+
+/* ------------------------------ Method: Apply ------------------------------ */
+
+// Apply updates the build in 'pathOld' using the patches in 'pathPatch' and
+// writes the new build to 'out'. The digests of each old file, patch, and new
+// file are verified.
+func (idx *Index) Apply(ctx context.Context, pathOld, pathPatch, out string) error { //nolint:cyclop
+	for _, e := range idx.Files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if !filepath.IsLocal(filepath.FromSlash(e.Path)) || (e.Data != "" && !filepath.IsLocal(filepath.FromSlash(e.Data))) {
+			return fmt.Errorf("%w: invalid path in index: %s", ErrInvalidInput, e.Path)
+		}
+
+		path := filepath.Join(out, filepath.FromSlash(e.Path))
+
+		switch e.Action {
+		case ActionRemove:
+			continue
+		case ActionKeep:
+			// NOTE: An unchanged file's source and target digests are the same.
+			if e.SourceSHA256 != e.TargetSHA256 {
+				return fmt.Errorf("%w: unchanged file has different digests: %s", ErrUnsupportedIndex, e.Path)
+			}
+
+			if err := copyFile(filepath.Join(pathOld, filepath.FromSlash(e.Path)), path, e.TargetSHA256); err != nil {
+				return err
+			}
+		case ActionAdd, ActionReplace:
+			// NOTE: A new file's data is the target file itself.
+			if e.DataSHA256 != e.TargetSHA256 {
+				return fmt.Errorf("%w: new file has different digests: %s", ErrUnsupportedIndex, e.Path)
+			}
+
+			if err := copyFile(filepath.Join(pathPatch, filepath.FromSlash(e.Data)), path, e.TargetSHA256); err != nil {
+				return err
+			}
+		case ActionPatch:
+			old, err := readVerified(filepath.Join(pathOld, filepath.FromSlash(e.Path)), e.SourceSHA256)
+			if err != nil {
+				return err
+			}
+
+			patch, err := readVerified(filepath.Join(pathPatch, filepath.FromSlash(e.Data)), e.DataSHA256)
+			if err != nil {
+				return err
+			}
+
+			updated, err := Apply(old, patch)
+			if err != nil {
+				return fmt.Errorf("cannot apply patch: %s: %w", e.Data, err)
+			}
+
+			if got := digest(updated); got != e.TargetSHA256 {
+				return fmt.Errorf("%w: %s: got %s, want %s", ErrDigestMismatch, e.Path, got, e.TargetSHA256)
+			}
+
+			if err := writeFile(path, updated); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: unknown action: %s", ErrUnsupportedIndex, e.Action)
+		}
+
+		if err := os.Chmod(path, e.Mode.Perm()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/* ---------------------------- Function: listFiles -------------------------- */
+
+// listFiles returns the slash-separated relative paths and modes of all regular
+// files within 'root'.
+func listFiles(root string) (map[string]fs.FileMode, error) {
+	out := make(map[string]fs.FileMode)
+
+	if err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			log.Warnf("skipping non-regular file: %s", path)
+
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		out[filepath.ToSlash(rel)] = info.Mode()
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+/* --------------------------- Function: readVerified ------------------------ */
+
+func readVerified(path, want string) ([]byte, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if got := digest(contents); got != want {
+		return nil, fmt.Errorf("%w: %s: got %s, want %s", ErrDigestMismatch, path, got, want)
+	}
+
+	return contents, nil
+}
+
+/* ---------------------------- Function: copyFile --------------------------- */
+
+// copyFile copies the file at 'src' to 'dst' without reading it into memory,
+// verifying that the copied contents have the SHA-256 digest 'want'. The copy
+// is removed if the digest doesn't match.
+func copyFile(src, dst, want string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), osutil.ModeUserRWXGroupRX); err != nil {
+		return err
+	}
+
+	r, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+
+	defer r.Close()
+
+	w, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, osutil.ModeUserRW)
+	if err != nil {
+		return err
+	}
+
+	h := sha256.New()
+
+	if _, err := io.Copy(io.MultiWriter(w, h), r); err != nil {
+		return errors.Join(err, w.Close())
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return errors.Join(
+			fmt.Errorf("%w: %s: got %s, want %s", ErrDigestMismatch, src, got, want),
+			os.Remove(dst),
+		)
+	}
+
+	return nil
+}
+
+/* ---------------------------- Function: writeFile -------------------------- */
+
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), osutil.ModeUserRWXGroupRX); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, osutil.ModeUserRW)
+}
+
+/* --------------------------- Function: digestFile -------------------------- */
+
+// digestFile returns the hex-encoded SHA-256 digest and the size of the file at
+// 'path' without reading it into memory.
+func digestFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+
+	defer f.Close()
+
+	h := sha256.New()
+
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+/* ----------------------------- Function: digest ---------------------------- */
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
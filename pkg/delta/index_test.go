@@ -0,0 +1,114 @@
+package delta_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/pkg/delta"
+)
+
+func TestCreateApply(t *testing.T) {
+	rng := rand.New(rand.NewSource(1)) //nolint:gosec
+
+	binary := make([]byte, 64*1024)
+	_, _ = rng.Read(binary)
+
+	write := func(root, name string, contents []byte) {
+		path := filepath.Join(root, name)
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, contents, 0o600))
+	}
+
+	// Given: An old build.
+	pathOld := t.TempDir()
+	write(pathOld, "game.x86_64", binary)
+	write(pathOld, "game.pck", []byte("pack"))
+	write(pathOld, "data/old.pck", []byte("removed"))
+	write(pathOld, "data/same.pck", []byte("same"))
+
+	// Given: A new build with a small change to the executable.
+	updated := append([]byte{}, binary...)
+	copy(updated[1000:], "updated")
+
+	pathNew := t.TempDir()
+	write(pathNew, "game.x86_64", updated)
+	write(pathNew, "game.pck", []byte("a different pack"))
+	write(pathNew, "data/new.pck", []byte("added"))
+	write(pathNew, "data/same.pck", []byte("same"))
+
+	// When: Patches are created.
+	out := t.TempDir()
+
+	idx, err := delta.Create(context.Background(), pathOld, pathNew, out)
+	require.NoError(t, err)
+
+	// Then: Each file has the expected action, sorted by path.
+	var got []string
+	for _, e := range idx.Files {
+		got = append(got, e.Path+":"+e.Action)
+	}
+
+	assert.Equal(t, []string{
+		"data/new.pck:" + delta.ActionAdd,
+		"data/old.pck:" + delta.ActionRemove,
+		"data/same.pck:" + delta.ActionKeep,
+		"game.pck:" + delta.ActionReplace,
+		"game.x86_64:" + delta.ActionPatch,
+	}, got)
+
+	// Then: The written index matches the returned index.
+	read, err := delta.ReadIndex(out)
+	require.NoError(t, err)
+	assert.Equal(t, idx, read)
+
+	// Then: Creating patches again produces identical output.
+	out2 := t.TempDir()
+
+	_, err = delta.Create(context.Background(), pathOld, pathNew, out2)
+	require.NoError(t, err)
+
+	for _, name := range []string{delta.FilenameIndex, "files/game.x86_64.gdbdiff"} {
+		a, err := os.ReadFile(filepath.Join(out, name))
+		require.NoError(t, err)
+
+		b, err := os.ReadFile(filepath.Join(out2, name))
+		require.NoError(t, err)
+
+		assert.Equal(t, a, b)
+	}
+
+	// When: The patches are applied to the old build.
+	result := t.TempDir()
+	require.NoError(t, idx.Apply(context.Background(), pathOld, out, result))
+
+	// Then: The result matches the new build.
+	for _, name := range []string{"game.x86_64", "game.pck", "data/new.pck", "data/same.pck"} {
+		want, err := os.ReadFile(filepath.Join(pathNew, name))
+		require.NoError(t, err)
+
+		got, err := os.ReadFile(filepath.Join(result, name))
+		require.NoError(t, err)
+
+		assert.Equal(t, want, got, name)
+	}
+
+	assert.NoFileExists(t, filepath.Join(result, "data/old.pck"))
+
+	// When: The patches are applied to a modified old build.
+	write(pathOld, "game.x86_64", []byte("tampered"))
+
+	err = idx.Apply(context.Background(), pathOld, out, t.TempDir())
+
+	// Then: The source digest mismatch is reported.
+	if !errors.Is(err, delta.ErrDigestMismatch) {
+		t.Fatalf("output: got %v, want %v", err, delta.ErrDigestMismatch)
+	}
+}
@@ -0,0 +1,356 @@
+package steam
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+const (
+	// DirContent is the name of the directory, relative to the output
+	// directory, in which depot contents are assembled.
+	DirContent = "content"
+	// DirOutput is the name of the directory, relative to the output
+	// directory, in which 'steamcmd' writes build logs and cache files.
+	DirOutput = "output"
+	// DirScripts is the name of the directory, relative to the output
+	// directory, in which build scripts are written.
+	DirScripts = "scripts"
+)
+
+var (
+	ErrInvalidInput = errors.New("invalid input")
+	ErrMissingInput = errors.New("missing input")
+)
+
+/* -------------------------------------------------------------------------- */
+/*                               Struct: Config                               */
+/* -------------------------------------------------------------------------- */
+
+// Config defines how exported targets are distributed on Steam. Each depot
+// contains the exported artifacts of a single target for a single platform.
+type Config struct {
+	// AppID is the Steam application ID.
+	AppID uint32 `toml:"app_id"`
+	// Branch is the name of the beta branch to set the build live on. If
+	// omitted, the build is uploaded without being set live.
+	Branch string `toml:"branch"`
+	// Depots is the list of depots which make up the application build.
+	Depots []Depot `toml:"depots"`
+	// Description is a description of the build, shown in the Steamworks
+	// partner site.
+	Description string `toml:"description"`
+	// Exclude is a list of file patterns excluded from *all* depots.
+	Exclude []string `toml:"exclude"`
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (c *Config) Validate(_ *run.Context) error {
+	if c.AppID == 0 {
+		return fmt.Errorf("%w: 'distribution.steam.app_id'", ErrMissingInput)
+	}
+
+	// NOTE: Steam does not allow build scripts to set the default branch live.
+	if c.Branch == "default" {
+		return fmt.Errorf(
+			"%w: cannot set a build live on the 'default' branch",
+			ErrInvalidInput,
+		)
+	}
+
+	if len(c.Depots) == 0 {
+		return fmt.Errorf("%w: 'distribution.steam.depots'", ErrMissingInput)
+	}
+
+	ids := make(map[uint32]struct{}, len(c.Depots))
+
+	for _, d := range c.Depots {
+		if err := d.Validate(); err != nil {
+			return err
+		}
+
+		if _, ok := ids[d.DepotID]; ok {
+			return fmt.Errorf("%w: duplicate depot ID: %d", ErrInvalidInput, d.DepotID)
+		}
+
+		ids[d.DepotID] = struct{}{}
+	}
+
+	return nil
+}
+
+/* --------------------------- Impl: config.Merger -------------------------- */
+
+func (c *Config) MergeInto(other any) error {
+	if c == nil || other == nil {
+		return nil
+	}
+
+	dst, ok := other.(*Config)
+	if !ok {
+		return fmt.Errorf(
+			"%w: expected a '%T' but was '%T'",
+			config.ErrInvalidInput,
+			new(Config),
+			other,
+		)
+	}
+
+	return config.Merge(dst, *c)
+}
+
+/* ----------------------------- Method: Scripts ---------------------------- */
+
+// Scripts generates the 'steamcmd' build scripts for the application. The
+// scripts reference depot contents relative to the scripts directory, so the
+// output is identical regardless of where it's written.
+func (c *Config) Scripts() ([]Script, error) {
+	depots := slices.Clone(c.Depots)
+	slices.SortFunc(depots, func(a, b Depot) int {
+		return cmp.Compare(a.DepotID, b.DepotID)
+	})
+
+	scripts := make([]Script, 0, len(depots)+1)
+	entries := make([]KeyValue, 0, len(depots))
+
+	for _, d := range depots {
+		s, err := newScript(d.Filename(), d.build(c.Exclude))
+		if err != nil {
+			return nil, err
+		}
+
+		scripts = append(scripts, s)
+		entries = append(entries, KeyValue{Key: d.ID(), Value: d.Filename()})
+	}
+
+	app := KeyValue{
+		Key: "AppBuild",
+		Children: []KeyValue{
+			{Key: "AppID", Value: strconv.FormatUint(uint64(c.AppID), 10)},
+			{Key: "Desc", Value: c.Description},
+			{Key: "ContentRoot", Value: "../" + DirContent + "/"},
+			{Key: "BuildOutput", Value: "../" + DirOutput + "/"},
+		},
+	}
+
+	if c.Branch != "" {
+		app.Children = append(app.Children, KeyValue{Key: "SetLive", Value: c.Branch})
+	}
+
+	app.Children = append(app.Children, KeyValue{Key: "Depots", Children: entries})
+
+	s, err := newScript(c.Filename(), app)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]Script{s}, scripts...), nil
+}
+
+/* ---------------------------- Method: Filename ---------------------------- */
+
+// Filename returns the name of the application build script.
+func (c *Config) Filename() string {
+	return "app_build_" + strconv.FormatUint(uint64(c.AppID), 10) + ".vdf"
+}
+
+/* -------------------------- Method: WriteScripts -------------------------- */
+
+// WriteScripts generates the 'steamcmd' build scripts and writes them into the
+// scripts directory within 'pathOut'.
+func (c *Config) WriteScripts(pathOut string) error {
+	scripts, err := c.Scripts()
+	if err != nil {
+		return err
+	}
+
+	pathScripts := filepath.Join(pathOut, DirScripts)
+
+	if err := os.MkdirAll(pathScripts, osutil.ModeUserRWXGroupRX); err != nil {
+		return err
+	}
+
+	for _, s := range scripts {
+		if err := os.WriteFile(
+			filepath.Join(pathScripts, s.Name),
+			s.Contents,
+			osutil.ModeUserRW,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                                Struct: Depot                               */
+/* -------------------------------------------------------------------------- */
+
+// Depot defines a Steam depot containing the exported artifacts of a target.
+type Depot struct {
+	// DepotID is the Steam depot ID.
+	DepotID uint32 `toml:"depot_id"`
+	// Exclude is a list of file patterns excluded from the depot.
+	Exclude []string `toml:"exclude"`
+	// Mappings define how exported files are mapped into the depot. Defaults
+	// to recursively including all exported files at the depot root.
+	Mappings []Mapping `toml:"mappings"`
+	// Platform is the platform for which the target is exported.
+	Platform string `toml:"platform"`
+	// Target is the name of the target whose artifacts populate the depot.
+	Target string `toml:"target"`
+}
+
+/* ------------------------------- Method: ID ------------------------------- */
+
+// ID returns the depot ID as a string.
+func (d *Depot) ID() string {
+	return strconv.FormatUint(uint64(d.DepotID), 10)
+}
+
+/* ---------------------------- Method: Filename ---------------------------- */
+
+// Filename returns the name of the depot build script.
+func (d *Depot) Filename() string {
+	return "depot_build_" + d.ID() + ".vdf"
+}
+
+/* --------------------------- Method: ContentRoot -------------------------- */
+
+// ContentRoot returns the path, relative to the output directory, in which the
+// depot's contents are assembled.
+func (d *Depot) ContentRoot() string {
+	return filepath.Join(DirContent, d.ID())
+}
+
+/* ------------------------------ Method: build ----------------------------- */
+
+func (d *Depot) build(exclude []string) KeyValue {
+	out := KeyValue{
+		Key: "DepotBuild",
+		Children: []KeyValue{
+			{Key: "DepotID", Value: d.ID()},
+			{Key: "ContentRoot", Value: "../" + DirContent + "/" + d.ID() + "/"},
+		},
+	}
+
+	mappings := d.Mappings
+	if len(mappings) == 0 {
+		mappings = []Mapping{{Depot: "", Local: "", Recursive: nil}}
+	}
+
+	for _, m := range mappings {
+		out.Children = append(out.Children, m.build())
+	}
+
+	var seen []string
+
+	for _, pattern := range append(slices.Clone(exclude), d.Exclude...) {
+		if slices.Contains(seen, pattern) {
+			continue
+		}
+
+		seen = append(seen, pattern)
+		out.Children = append(out.Children, KeyValue{Key: "FileExclusion", Value: pattern})
+	}
+
+	return out
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (d *Depot) Validate() error {
+	if d.DepotID == 0 {
+		return fmt.Errorf("%w: 'depot_id'", ErrMissingInput)
+	}
+
+	if d.Target == "" {
+		return fmt.Errorf("%w: 'target' for depot: %d", ErrMissingInput, d.DepotID)
+	}
+
+	if _, err := platform.ParseOS(d.Platform); err != nil {
+		return fmt.Errorf("%w: 'platform' for depot: %d: %w", ErrInvalidInput, d.DepotID, err)
+	}
+
+	return nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                               Struct: Mapping                              */
+/* -------------------------------------------------------------------------- */
+
+// Mapping maps exported files into a location within a depot.
+type Mapping struct {
+	// Depot is the destination path within the depot. Defaults to ".".
+	Depot string `toml:"depot"`
+	// Local is a path or wildcard pattern, relative to the exported artifacts,
+	// of the files to include. Defaults to "*".
+	Local string `toml:"local"`
+	// Recursive determines whether subdirectories are included. Defaults to
+	// true.
+	Recursive *bool `toml:"recursive"`
+}
+
+/* ------------------------------ Method: build ----------------------------- */
+
+func (m *Mapping) build() KeyValue {
+	local := m.Local
+	if local == "" {
+		local = "*"
+	}
+
+	depot := m.Depot
+	if depot == "" {
+		depot = "."
+	}
+
+	recursive := "1"
+	if m.Recursive != nil && !*m.Recursive {
+		recursive = "0"
+	}
+
+	return KeyValue{
+		Key: "FileMapping",
+		Children: []KeyValue{
+			{Key: "LocalPath", Value: local},
+			{Key: "DepotPath", Value: depot},
+			{Key: "Recursive", Value: recursive},
+		},
+	}
+}
+
+/* -------------------------------------------------------------------------- */
+/*                               Struct: Script                               */
+/* -------------------------------------------------------------------------- */
+
+// Script is a generated 'steamcmd' build script.
+type Script struct {
+	// Name is the filename of the script.
+	Name string
+	// Contents is the encoded script.
+	Contents []byte
+}
+
+/* --------------------------- Function: newScript -------------------------- */
+
+func newScript(name string, kv KeyValue) (Script, error) {
+	var buf bytes.Buffer
+
+	if err := kv.Encode(&buf); err != nil {
+		return Script{}, err
+	}
+
+	return Script{Name: name, Contents: buf.Bytes()}, nil
+}
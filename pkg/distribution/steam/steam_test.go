@@ -0,0 +1,192 @@
+package steam_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/pkg/distribution/steam"
+)
+
+func TestConfigScripts(t *testing.T) {
+	// Given: A Steam configuration with depots defined out of order.
+	cfg := steam.Config{
+		AppID:       480,
+		Branch:      "beta",
+		Description: `Nightly "build"`,
+		Exclude:     []string{"*.pdb"},
+		Depots: []steam.Depot{
+			{
+				DepotID:  482,
+				Exclude:  []string{"*.pdb", "*.debug"},
+				Mappings: []steam.Mapping{{Local: "game*", Depot: "bin", Recursive: pointer(false)}, {}},
+				Platform: "linux",
+				Target:   "client",
+			},
+			{DepotID: 481, Platform: "windows", Target: "client"},
+		},
+	}
+
+	// When: The build scripts are generated.
+	got, err := cfg.Scripts()
+	require.NoError(t, err)
+
+	// Then: The scripts match expectations.
+	require.Len(t, got, 3)
+
+	assert.Equal(t, "app_build_480.vdf", got[0].Name)
+	assert.Equal(t, `"AppBuild"
+{
+	"AppID"	"480"
+	"Desc"	"Nightly \"build\""
+	"ContentRoot"	"../content/"
+	"BuildOutput"	"../output/"
+	"SetLive"	"beta"
+	"Depots"
+	{
+		"481"	"depot_build_481.vdf"
+		"482"	"depot_build_482.vdf"
+	}
+}
+`, string(got[0].Contents))
+
+	assert.Equal(t, "depot_build_481.vdf", got[1].Name)
+	assert.Equal(t, `"DepotBuild"
+{
+	"DepotID"	"481"
+	"ContentRoot"	"../content/481/"
+	"FileMapping"
+	{
+		"LocalPath"	"*"
+		"DepotPath"	"."
+		"Recursive"	"1"
+	}
+	"FileExclusion"	"*.pdb"
+}
+`, string(got[1].Contents))
+
+	assert.Equal(t, "depot_build_482.vdf", got[2].Name)
+	assert.Equal(t, `"DepotBuild"
+{
+	"DepotID"	"482"
+	"ContentRoot"	"../content/482/"
+	"FileMapping"
+	{
+		"LocalPath"	"game*"
+		"DepotPath"	"bin"
+		"Recursive"	"0"
+	}
+	"FileMapping"
+	{
+		"LocalPath"	"*"
+		"DepotPath"	"."
+		"Recursive"	"1"
+	}
+	"FileExclusion"	"*.pdb"
+	"FileExclusion"	"*.debug"
+}
+`, string(got[2].Contents))
+
+	// Then: Generation is deterministic.
+	again, err := cfg.Scripts()
+	require.NoError(t, err)
+	assert.Equal(t, got, again)
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name string
+
+		cfg steam.Config
+
+		err error
+	}{
+		{
+			name: "valid configuration succeeds",
+
+			cfg: steam.Config{AppID: 1, Depots: []steam.Depot{{DepotID: 2, Platform: "linux", Target: "client"}}},
+		},
+		{
+			name: "missing app ID fails",
+
+			cfg: steam.Config{Depots: []steam.Depot{{DepotID: 2, Platform: "linux", Target: "client"}}},
+
+			err: steam.ErrMissingInput,
+		},
+		{
+			name: "missing depots fails",
+
+			cfg: steam.Config{AppID: 1},
+
+			err: steam.ErrMissingInput,
+		},
+		{
+			name: "default branch fails",
+
+			cfg: steam.Config{AppID: 1, Branch: "default", Depots: []steam.Depot{{DepotID: 2, Platform: "linux", Target: "client"}}},
+
+			err: steam.ErrInvalidInput,
+		},
+		{
+			name: "duplicate depot IDs fail",
+
+			cfg: steam.Config{AppID: 1, Depots: []steam.Depot{
+				{DepotID: 2, Platform: "linux", Target: "client"},
+				{DepotID: 2, Platform: "windows", Target: "client"},
+			}},
+
+			err: steam.ErrInvalidInput,
+		},
+		{
+			name: "invalid platform fails",
+
+			cfg: steam.Config{AppID: 1, Depots: []steam.Depot{{DepotID: 2, Platform: "dos", Target: "client"}}},
+
+			err: steam.ErrInvalidInput,
+		},
+		{
+			name: "missing target fails",
+
+			cfg: steam.Config{AppID: 1, Depots: []steam.Depot{{DepotID: 2, Platform: "linux"}}},
+
+			err: steam.ErrMissingInput,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: The configuration is validated.
+			err := tc.cfg.Validate(nil)
+
+			// Then: The resulting error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestKeyValueEncode(t *testing.T) {
+	// Given: A nested key-value document with characters requiring escapes.
+	kv := steam.KeyValue{
+		Key: "root",
+		Children: []steam.KeyValue{
+			{Key: "path", Value: `C:\content`},
+			{Key: "empty", Children: []steam.KeyValue{}},
+		},
+	}
+
+	// When: The document is encoded.
+	var buf bytes.Buffer
+	require.NoError(t, kv.Encode(&buf))
+
+	// Then: The output matches expectations.
+	assert.Equal(t, "\"root\"\n{\n\t\"path\"\t\"C:\\\\content\"\n\t\"empty\"\n\t{\n\t}\n}\n", buf.String())
+}
+
+func pointer[T any](value T) *T {
+	return &value
+}
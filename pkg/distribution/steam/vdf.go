@@ -0,0 +1,70 @@
+package steam
+
+import (
+	"io"
+	"strings"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                              Struct: KeyValue                              */
+/* -------------------------------------------------------------------------- */
+
+// KeyValue is a single entry in a Valve Data Format (VDF) document. An entry
+// is either a string value or a nested section of entries. Entries are kept in
+// order and keys may repeat (e.g. multiple 'FileMapping' sections), so a slice
+// is used instead of a map.
+type KeyValue struct {
+	// Key is the name of the entry.
+	Key string
+	// Value is the string value of the entry. Ignored if 'Children' is set.
+	Value string
+	// Children are the entries of a nested section.
+	Children []KeyValue
+}
+
+/* ------------------------------ Method: Encode ----------------------------- */
+
+// Encode writes the 'KeyValue' to 'w' in the text VDF format expected by
+// 'steamcmd'. Indentation uses tabs.
+func (kv KeyValue) Encode(w io.Writer) error {
+	var sb strings.Builder
+
+	kv.encode(&sb, 0)
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+/* ------------------------------ Method: encode ----------------------------- */
+
+func (kv KeyValue) encode(sb *strings.Builder, depth int) {
+	indent := strings.Repeat("\t", depth)
+
+	sb.WriteString(indent + quote(kv.Key))
+
+	if kv.Children == nil {
+		sb.WriteString("\t" + quote(kv.Value) + "\n")
+
+		return
+	}
+
+	sb.WriteString("\n" + indent + "{\n")
+
+	for _, child := range kv.Children {
+		child.encode(sb, depth+1)
+	}
+
+	sb.WriteString(indent + "}\n")
+}
+
+/* ----------------------------- Function: quote ---------------------------- */
+
+// quote wraps the input in double quotes, escaping characters as required by
+// the VDF format.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+
+	return `"` + s + `"`
+}
@@ -0,0 +1,150 @@
+package encryption
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+)
+
+const (
+	// EnvKey is the environment variable from which the encryption key is read
+	// by default.
+	EnvKey = "SCRIPT_AES256_ENCRYPTION_KEY"
+
+	// FilenameFingerprint is the name of the file, stored alongside a compiled
+	// export template, which records the fingerprint of its encryption key.
+	FilenameFingerprint = "gdbuild.key.sha"
+
+	// KeySize is the size of an AES-256 encryption key in bytes.
+	KeySize = 32
+)
+
+var (
+	ErrInvalidKey        = errors.New("invalid encryption key")
+	ErrKeyMismatch       = errors.New("encryption key mismatch")
+	ErrConflictingSource = errors.New("conflicting encryption key sources")
+)
+
+/* -------------------------------------------------------------------------- */
+/*                             Function: Generate                             */
+/* -------------------------------------------------------------------------- */
+
+// Generate creates a new random, hex-encoded 256-bit encryption key.
+func Generate() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(key), nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                              Function: Decode                              */
+/* -------------------------------------------------------------------------- */
+
+// Decode validates the hex-encoded encryption key and returns its bytes. An
+// empty key is valid and decodes to 'nil'.
+func Decode(key string) ([]byte, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	if len(key) != hex.EncodedLen(KeySize) {
+		return nil, fmt.Errorf(
+			"%w: expected %d hexadecimal characters but found %d",
+			ErrInvalidKey,
+			hex.EncodedLen(KeySize),
+			len(key),
+		)
+	}
+
+	out, err := hex.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+
+	return out, nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                            Function: Fingerprint                           */
+/* -------------------------------------------------------------------------- */
+
+// Fingerprint returns a SHA-512/224 digest of the encryption key, which can be
+// used to identify the key without revealing it. The key must be valid.
+func Fingerprint(key string) string {
+	if key == "" {
+		return ""
+	}
+
+	sum := sha512.Sum512_224([]byte(strings.ToLower(key)))
+
+	return hex.EncodeToString(sum[:])
+}
+
+/* ---------------------- Function: ReadFingerprintFile --------------------- */
+
+// ReadFingerprintFile returns the encryption key fingerprint recorded in the
+// directory 'path', if any.
+func ReadFingerprintFile(path string) (string, bool, error) {
+	data, err := os.ReadFile(filepath.Join(path, FilenameFingerprint))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", false, nil
+		}
+
+		return "", false, err
+	}
+
+	return strings.TrimSpace(string(data)), true, nil
+}
+
+/* ---------------------- Function: WriteFingerprintFile -------------------- */
+
+// WriteFingerprintFile records the fingerprint of 'key' in the directory
+// 'path'.
+func WriteFingerprintFile(path, key string) error {
+	return os.WriteFile(
+		filepath.Join(path, FilenameFingerprint),
+		[]byte(Fingerprint(key)+"\n"),
+		osutil.ModeUserRW,
+	)
+}
+
+/* --------------------- Function: VerifyFingerprintFile -------------------- */
+
+// VerifyFingerprintFile checks that the encryption key fingerprint recorded in
+// the directory 'path' matches 'key'. The second return value reports whether
+// a fingerprint was recorded at all.
+func VerifyFingerprintFile(path, key string) (bool, error) {
+	want, ok, err := ReadFingerprintFile(path)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	if got := Fingerprint(key); got != want {
+		if got == "" {
+			return true, fmt.Errorf(
+				"%w: export template was compiled with an encryption key but none is set",
+				ErrKeyMismatch,
+			)
+		}
+
+		return true, fmt.Errorf(
+			"%w: export template was compiled with key '%s' but export uses '%s' (SHA-512/224 sums)",
+			ErrKeyMismatch,
+			want,
+			got,
+		)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,92 @@
+package encryption_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/pkg/godot/encryption"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		key  string
+		want int
+		err  error
+	}{
+		{key: "", want: 0},
+		{key: strings.Repeat("ab", 32), want: encryption.KeySize},
+		{key: strings.Repeat("AB", 32), want: encryption.KeySize},
+		{key: strings.Repeat("ab", 31), err: encryption.ErrInvalidKey},
+		{key: strings.Repeat("ab", 33), err: encryption.ErrInvalidKey},
+		{key: strings.Repeat("zz", 32), err: encryption.ErrInvalidKey},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.key, func(t *testing.T) {
+			// When: The key is decoded.
+			got, err := encryption.Decode(tc.key)
+
+			// Then: The resulting error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+
+			// Then: The decoded key has the expected length.
+			assert.Len(t, got, tc.want)
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	// When: Two keys are generated.
+	a, err := encryption.Generate()
+	require.NoError(t, err)
+
+	b, err := encryption.Generate()
+	require.NoError(t, err)
+
+	// Then: The keys are valid.
+	_, err = encryption.Decode(a)
+	require.NoError(t, err)
+
+	// Then: The keys are random.
+	assert.NotEqual(t, a, b)
+}
+
+func TestVerifyFingerprintFile(t *testing.T) {
+	key := strings.Repeat("ab", 32)
+
+	// Given: A directory without a recorded fingerprint.
+	dir := t.TempDir()
+
+	// When: The fingerprint is verified.
+	ok, err := encryption.VerifyFingerprintFile(dir, key)
+
+	// Then: No fingerprint is found.
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Given: A recorded fingerprint.
+	require.NoError(t, encryption.WriteFingerprintFile(dir, key))
+
+	// When: The fingerprint is verified with the same key (in any case).
+	ok, err = encryption.VerifyFingerprintFile(dir, strings.ToUpper(key))
+
+	// Then: The fingerprint matches.
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	for _, other := range []string{"", strings.Repeat("cd", 32)} {
+		// When: The fingerprint is verified with a different key.
+		_, err = encryption.VerifyFingerprintFile(dir, other)
+
+		// Then: A mismatch is reported.
+		if !errors.Is(err, encryption.ErrKeyMismatch) {
+			t.Fatalf("output: got %v, want %v", err, encryption.ErrKeyMismatch)
+		}
+	}
+}
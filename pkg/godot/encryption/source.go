@@ -0,0 +1,195 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/internal/exec"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+// keys caches the encryption keys read from a 'KeySource' command.
+//
+//nolint:gochecknoglobals
+var (
+	keys   = map[KeySource]string{}
+	keysMu sync.Mutex
+)
+
+/* -------------------------------------------------------------------------- */
+/*                              Struct: KeySource                             */
+/* -------------------------------------------------------------------------- */
+
+// KeySource defines where to read the script encryption key from. At most one
+// source may be set; if none are, the key is read from the environment
+// variable 'SCRIPT_AES256_ENCRYPTION_KEY'.
+type KeySource struct {
+	// KeyCommand is a command whose standard output is the encryption key (e.g.
+	// a password manager CLI). It's run within the directory containing the
+	// manifest.
+	KeyCommand string `toml:"key_command"`
+	// KeyEnv is the name of an environment variable containing the key.
+	KeyEnv string `toml:"key_env"`
+	// KeyFile is a path to a file containing the key.
+	KeyFile osutil.Path `toml:"key_file"`
+	// Shell defines which shell process to run 'KeyCommand' in.
+	Shell exec.Shell `toml:"shell"`
+
+	// dir is the directory in which 'KeyCommand' is run.
+	dir string
+}
+
+/* ------------------------------ Method: IsSet ----------------------------- */
+
+// IsSet returns whether any key source has been configured.
+func (s KeySource) IsSet() bool {
+	return s.KeyCommand != "" || s.KeyEnv != "" || s.KeyFile != ""
+}
+
+/* ------------------------------- Method: Key ------------------------------ */
+
+// Key reads the hex-encoded encryption key from the configured source and
+// validates it. An empty string is returned if no key is set. Keys read from a
+// command are cached so that the command (e.g. a password manager prompt) is
+// only run once per process.
+func (s *KeySource) Key(ctx context.Context) (string, error) {
+	var src KeySource
+	if s != nil {
+		src = *s
+	}
+
+	if src.KeyCommand == "" {
+		return src.read(ctx)
+	}
+
+	keysMu.Lock()
+	defer keysMu.Unlock()
+
+	if key, ok := keys[src]; ok {
+		return key, nil
+	}
+
+	key, err := src.read(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	keys[src] = key
+
+	return key, nil
+}
+
+/* ------------------------------ Method: read ------------------------------ */
+
+func (s KeySource) read(ctx context.Context) (string, error) {
+	var key, from string
+
+	switch {
+	case !s.IsSet():
+		key, from = os.Getenv(EnvKey), "environment variable '"+EnvKey+"'"
+
+	case s.KeyEnv != "":
+		key, from = os.Getenv(s.KeyEnv), "environment variable '"+s.KeyEnv+"'"
+
+	case s.KeyFile != "":
+		data, err := os.ReadFile(s.KeyFile.String())
+		if err != nil {
+			return "", fmt.Errorf("cannot read encryption key file: %w", err)
+		}
+
+		key, from = string(data), "file '"+s.KeyFile.String()+"'"
+
+	case s.KeyCommand != "":
+		p := exec.Process{ //nolint:exhaustruct
+			Args:      []string{s.KeyCommand},
+			Directory: s.dir,
+			Shell:     s.Shell,
+		}
+
+		out, err := p.Output(ctx)
+		if err != nil {
+			return "", fmt.Errorf("cannot read encryption key from command: %w", err)
+		}
+
+		key, from = string(out), "command '"+s.KeyCommand+"'"
+	}
+
+	key = strings.TrimSpace(key)
+
+	if _, err := Decode(key); err != nil {
+		return "", fmt.Errorf("%w (from %s)", err, from)
+	}
+
+	return key, nil
+}
+
+/* ------------------------- Impl: config.Configurer ------------------------ */
+
+func (s *KeySource) Configure(rc *run.Context) error {
+	if err := s.KeyFile.RelTo(rc.PathManifest); err != nil {
+		return err
+	}
+
+	s.dir = filepath.Dir(rc.PathManifest.String())
+
+	return nil
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (s *KeySource) Validate(_ *run.Context) error {
+	var count int
+
+	for _, isSet := range []bool{s.KeyCommand != "", s.KeyEnv != "", s.KeyFile != ""} {
+		if isSet {
+			count++
+		}
+	}
+
+	if count > 1 {
+		return fmt.Errorf(
+			"%w: at most one of 'key_command', 'key_env', and 'key_file' may be set",
+			ErrConflictingSource,
+		)
+	}
+
+	if s.KeyFile != "" {
+		if err := s.KeyFile.CheckIsFile(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/* --------------------------- Impl: config.Merger -------------------------- */
+
+// MergeInto overrides the key source in 'other' if one is set. Unlike most
+// settings, key sources replace (rather than combine with) inherited ones.
+func (s *KeySource) MergeInto(other any) error {
+	if s == nil || other == nil {
+		return nil
+	}
+
+	dst, ok := other.(*KeySource)
+	if !ok {
+		return fmt.Errorf(
+			"%w: expected a '%T' but was '%T'",
+			config.ErrInvalidInput,
+			new(KeySource),
+			other,
+		)
+	}
+
+	if s.IsSet() {
+		*dst = *s
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package encryption_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/godot/encryption"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+func TestKeySourceKey(t *testing.T) {
+	key := strings.Repeat("ab", 32)
+
+	dir := t.TempDir()
+
+	pathKey := filepath.Join(dir, "key.txt")
+	require.NoError(t, os.WriteFile(pathKey, []byte(key+"\n"), 0o600))
+
+	pathInvalid := filepath.Join(dir, "invalid.txt")
+	require.NoError(t, os.WriteFile(pathInvalid, []byte("abc"), 0o600))
+
+	t.Setenv(encryption.EnvKey, "")
+	t.Setenv("GDBUILD_TEST_KEY", key)
+
+	tests := []struct {
+		name   string
+		source encryption.KeySource
+		want   string
+		err    error
+	}{
+		{
+			name:   "unset source with unset variable returns no key",
+			source: encryption.KeySource{},
+			want:   "",
+		},
+		{
+			name:   "environment variable source returns key",
+			source: encryption.KeySource{KeyEnv: "GDBUILD_TEST_KEY"}, //nolint:exhaustruct
+			want:   key,
+		},
+		{
+			name:   "file source returns trimmed key",
+			source: encryption.KeySource{KeyFile: osutil.Path(pathKey)}, //nolint:exhaustruct
+			want:   key,
+		},
+		{
+			name:   "file source with invalid key returns error",
+			source: encryption.KeySource{KeyFile: osutil.Path(pathInvalid)}, //nolint:exhaustruct
+			err:    encryption.ErrInvalidKey,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: The key is read from the source.
+			got, err := tc.source.Key(context.Background())
+
+			// Then: The resulting error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+
+			// Then: The key matches expectations.
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestKeySourceValidate(t *testing.T) {
+	pathKey := filepath.Join(t.TempDir(), "key.txt")
+	require.NoError(t, os.WriteFile(pathKey, nil, 0o600))
+
+	tests := []struct {
+		name   string
+		source encryption.KeySource
+		err    error
+	}{
+		{
+			name:   "unset source is valid",
+			source: encryption.KeySource{},
+		},
+		{
+			name:   "single source is valid",
+			source: encryption.KeySource{KeyFile: osutil.Path(pathKey)}, //nolint:exhaustruct
+		},
+		{
+			name:   "multiple sources are invalid",
+			source: encryption.KeySource{KeyEnv: "KEY", KeyCommand: "echo"}, //nolint:exhaustruct
+			err:    encryption.ErrConflictingSource,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: The source is validated.
+			err := tc.source.Validate(&run.Context{}) //nolint:exhaustruct
+
+			// Then: The resulting error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package export
 
 import (
 	"fmt"
+	"hash"
 	"hash/crc64"
 	"io"
 	"path/filepath"
@@ -65,9 +66,17 @@ func Checksum(rc *run.Context, x *Export) (string, error) { //nolint:funlen
 			return "", err
 		}
 
+		// NOTE: Files can move between pack files (e.g. partitions) without the
+		// set of hashed files changing, so include each pack file's contents.
+		if err := hashPackFileContents(cs, pathRoot, pck.Name, ff); err != nil {
+			return "", err
+		}
+
 		files = append(files, ff...)
 	}
 
+	files = append(files, xp.Paths...)
+
 	// Make the path list unique and sorted.
 	slices.Sort(files)
 	files = slices.Compact(files)
@@ -95,3 +104,40 @@ func Checksum(rc *run.Context, x *Export) (string, error) { //nolint:funlen
 
 	return strconv.FormatUint(cs.Sum64(), 16), nil
 }
+
+/* ---------------------- Function: hashPackFileContents -------------------- */
+
+// hashPackFileContents updates the provided 'hash.Hash' with the name of a pack
+// file and the paths, relative to 'root', of the files it contains.
+func hashPackFileContents(h hash.Hash, root, name osutil.Path, files []osutil.Path) error {
+	if _, err := io.WriteString(h, "pack:"+name.String()+"\n"); err != nil {
+		return err
+	}
+
+	pathRoot, err := filepath.Abs(root.String())
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(files))
+
+	for _, f := range files {
+		rel, err := filepath.Rel(pathRoot, f.String())
+		if err != nil {
+			return err
+		}
+
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+
+	// NOTE: A pack file's files aren't listed in a consistent order.
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		if _, err := io.WriteString(h, path+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,18 +2,48 @@ package export
 
 import (
 	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/coffeebeats/gdenv/pkg/godot/version"
 
 	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/exec"
 	"github.com/coffeebeats/gdbuild/internal/osutil"
 	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+	"github.com/coffeebeats/gdbuild/pkg/godot/project"
 	"github.com/coffeebeats/gdbuild/pkg/run"
 )
 
+const (
+	// DefaultImportTimeout is the default maximum duration to wait for the
+	// Godot editor to import the project's assets.
+	DefaultImportTimeout = time.Hour
+
+	// importPollInterval is how often an import's progress is checked when the
+	// editor doesn't support the '--import' flag.
+	importPollInterval = 500 * time.Millisecond
+	// importSettleDuration is how long the project's editor data directory
+	// must remain unchanged before an import is considered complete.
+	importSettleDuration = 3 * time.Second
+	// importStopGracePeriod is how long the editor is given to exit after being
+	// interrupted before it's killed.
+	importStopGracePeriod = 10 * time.Second
+
+	extMD5 = ".md5"
+)
+
+var ErrImportFailed = errors.New("import failed")
+
 /* -------------------------------------------------------------------------- */
 /*                    Function: NewInstallEditorGodotAction                   */
 /* -------------------------------------------------------------------------- */
@@ -67,25 +97,429 @@ func NewInstallEditorGodotAction(
 /* -------------------------------------------------------------------------- */
 
 // NewLoadProjectAction creates an 'action.Action' which opens the Godot project
-// is the editor for the purpose of generating import files.
+// in the editor for the purpose of importing the project's assets. Editors
+// which support the '--import' flag (i.e. Godot 4.3+) import the assets and
+// then exit. Otherwise, the editor is stopped once every asset has been
+// imported and the project's editor data directory stops changing. Either
+// way, an error is returned if importing takes longer than 'timeout' (or
+// 'DefaultImportTimeout' if unset).
 func NewLoadProjectAction(
 	rc *run.Context,
+	ev engine.Version,
 	pathGodotEditor osutil.Path,
-) *action.Process {
+	timeout time.Duration,
+) action.WithDescription[action.Function] {
+	if timeout <= 0 {
+		timeout = DefaultImportTimeout
+	}
+
 	var cmd action.Process
 
 	cmd.Directory = rc.PathWorkspace.String()
 	cmd.Verbose = rc.Verbose
 
-	cmd.Args = []string{
-		pathGodotEditor.String(),
-		"--editor",
-		"--headless",
-		"--quit-after",
-		"2",
+	// NOTE: Run the editor directly, rather than through a shell, so that it
+	// receives the interrupt used to stop it gracefully.
+	cmd.Shell = exec.ShellNone
+	cmd.GracePeriod = importStopGracePeriod
+
+	cmd.Args = []string{pathGodotEditor.String(), "--headless"}
+
+	fn := func(ctx context.Context) error {
+		return waitForImport(ctx, rc, &cmd, timeout)
+	}
+
+	if supportsImportFlag(ev) {
+		cmd.Args = append(cmd.Args, "--import")
+
+		fn = func(ctx context.Context) error {
+			return runImport(ctx, rc, &cmd, timeout)
+		}
+	} else {
+		cmd.Args = append(cmd.Args, "--editor")
+	}
+
+	return action.WithDescription[action.Function]{
+		Action:      fn,
+		Description: fmt.Sprintf("import project assets (timeout=%s): %s", timeout, cmd.String()),
+	}
+}
+
+/* ------------------------ Function: supportsImportFlag ---------------------- */
+
+// supportsImportFlag reports whether the Godot editor version 'ev' supports the
+// '--import' command-line flag, which was added in Godot 4.3.
+func supportsImportFlag(ev engine.Version) bool {
+	v := version.Version(ev)
+
+	return v.Major() > 4 || (v.Major() == 4 && v.Minor() >= 3) //nolint:gomnd
+}
+
+/* --------------------------- Function: runImport -------------------------- */
+
+// runImport runs an editor process which imports the project's assets and then
+// exits on its own.
+func runImport(ctx context.Context, rc *run.Context, cmd *action.Process, timeout time.Duration) error {
+	ctxImport, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := cmd.Run(ctxImport); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if errors.Is(ctxImport.Err(), context.DeadlineExceeded) {
+			return newImportTimeoutError(rc, timeout)
+		}
+
+		return fmt.Errorf("%w: editor failed to import assets: %w", ErrImportFailed, err)
+	}
+
+	return checkImported(rc)
+}
+
+/* ------------------------- Function: waitForImport ------------------------ */
+
+// waitForImport runs an editor process which doesn't exit after importing the
+// project's assets, stopping it once the import is complete. Because the editor
+// doesn't report when it's done, the import is considered complete once every
+// asset's import record (i.e. its '.md5' file within '.godot/imported') matches
+// the current source file and the project's editor data directory hasn't
+// changed for 'importSettleDuration'.
+func waitForImport(ctx context.Context, rc *run.Context, cmd *action.Process, timeout time.Duration) error {
+	ctxEditor, cancel := context.WithCancel(ctx)
+
+	exited := make(chan error, 1)
+
+	go func() {
+		exited <- cmd.Run(ctxEditor)
+	}()
+
+	// stop interrupts the editor, if still running, and waits for it to exit.
+	stop := func() {
+		cancel()
+		<-exited
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	ticker := time.NewTicker(importPollInterval)
+	defer ticker.Stop()
+
+	digests := sourceDigests{}
+
+	var (
+		last      importState
+		changedAt time.Time
+	)
+
+	for {
+		select {
+		case <-ctx.Done():
+			stop()
+
+			return ctx.Err()
+
+		case <-timer.C:
+			stop()
+
+			return newImportTimeoutError(rc, timeout)
+
+		case err := <-exited:
+			cancel()
+
+			if err != nil {
+				return fmt.Errorf("%w: editor exited before importing assets: %w", ErrImportFailed, err)
+			}
+
+			return checkImported(rc)
+
+		case now := <-ticker.C:
+			state, err := readImportState(rc.PathWorkspace, digests)
+			if err != nil {
+				stop()
+
+				return err
+			}
+
+			if changedAt.IsZero() || state != last {
+				last, changedAt = state, now
+
+				continue
+			}
+
+			if state.Unimported > 0 || now.Sub(changedAt) < importSettleDuration {
+				continue
+			}
+
+			log.Debug("finished importing assets")
+
+			stop()
+
+			return nil
+		}
+	}
+}
+
+/* --------------------------- Struct: importState -------------------------- */
+
+// importState is a snapshot of the progress of an import.
+type importState struct {
+	// Unimported is the number of assets whose imported files are missing or
+	// out of date.
+	Unimported int
+
+	// Files is the number of files in the project's editor data directory.
+	Files int
+	// Size is the total size of the files in the editor data directory.
+	Size int64
+	// ModTime is the latest modification time (in nanoseconds since the Unix
+	// epoch) of the files in the editor data directory.
+	ModTime int64
+}
+
+/* ------------------------- Function: readImportState ---------------------- */
+
+func readImportState(root osutil.Path, digests sourceDigests) (importState, error) {
+	var state importState
+
+	unimported, err := findUnimportedAssets(root, digests)
+	if err != nil {
+		return importState{}, err
+	}
+
+	state.Unimported = len(unimported)
+
+	err = filepath.WalkDir(root.Join(dirnameImported).String(), func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// NOTE: Files may be removed by the editor while walking.
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		state.Files++
+		state.Size += info.Size()
+		state.ModTime = max(state.ModTime, info.ModTime().UnixNano())
+
+		return nil
+	})
+	if err != nil {
+		return importState{}, err
+	}
+
+	return state, nil
+}
+
+/* ----------------------- Function: findUnimportedAssets ------------------- */
+
+// findUnimportedAssets returns the resource paths of the project's assets which
+// haven't been imported or whose import is out of date. Note that imported files
+// can exist but be stale (e.g. after restoring a cached import), so an asset is
+// only considered imported once the editor has recorded an import of the
+// current source file (see 'isAssetImported').
+func findUnimportedAssets(root osutil.Path, digests sourceDigests) ([]string, error) {
+	var unimported []string
+
+	err := walkImportFiles(root, func(path string) error {
+		source := strings.TrimSuffix(path, extImport)
+
+		ok, err := isAssetImported(root, source, digests)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			unimported = append(unimported, resourcePathPrefix+filepath.ToSlash(source))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return unimported, nil
+}
+
+/* ------------------------ Function: isAssetImported ----------------------- */
+
+// isAssetImported reports whether the asset at 'source' (relative to 'root') has
+// been imported. This requires that each of its '.import' file's 'dest_files'
+// exist and that the source file's MD5 digest recorded by the editor upon
+// import (i.e. 'source_md5' in '.godot/imported/<file>-<hash>.md5') matches the
+// current source file.
+func isAssetImported(root osutil.Path, source string, digests sourceDigests) (bool, error) {
+	digest, err := digests.get(root.Join(source).String())
+	if err != nil {
+		// NOTE: The editor ignores '.import' files whose source file was removed.
+		if errors.Is(err, fs.ErrNotExist) {
+			return true, nil
+		}
+
+		return false, err
+	}
+
+	cfg, err := readConfigFile(root.Join(source + extImport).String())
+	if err != nil || cfg == nil {
+		return false, err
+	}
+
+	// NOTE: Assets which are kept as-is or skipped aren't actually imported.
+	if importer := cfg.String("remap", "importer"); importer == "keep" || importer == "skip" {
+		return true, nil
+	}
+
+	for _, d := range cfg.Strings("deps", "dest_files") {
+		if _, err := os.Stat(root.Join(toLocalPath(d)).String()); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return false, nil
+			}
+
+			return false, err
+		}
+	}
+
+	record, err := readConfigFile(root.Join(importBasePath(source) + extMD5).String())
+	if err != nil || record == nil {
+		return false, err
+	}
+
+	return record.String("", "source_md5") == digest, nil
+}
+
+/* ------------------------- Function: importBasePath ----------------------- */
+
+// importBasePath returns the path, relative to the project root, at which the
+// editor stores the imported files of the asset at 'source'. This matches the
+// editor's 'ResourceFormatImporter::get_import_base_path'.
+func importBasePath(source string) string {
+	res := resourcePathPrefix + filepath.ToSlash(source)
+	sum := md5.Sum([]byte(res)) //nolint:gosec
+
+	return filepath.Join(
+		dirnameImported,
+		dirnameImportedFiles,
+		path.Base(res)+"-"+hex.EncodeToString(sum[:]),
+	)
+}
+
+/* ------------------------- Function: readConfigFile ----------------------- */
+
+// readConfigFile parses the Godot 'ConfigFile' document at 'path'. A nil
+// document is returned, without an error, if the file doesn't exist.
+func readConfigFile(path string) (*project.ConfigFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil //nolint:nilnil
+		}
+
+		return nil, err
+	}
+
+	cfg, err := project.ParseConfigFile(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, path)
+	}
+
+	return cfg, nil
+}
+
+/* --------------------------- Type: sourceDigests -------------------------- */
+
+// sourceDigests caches the MD5 digests of source files, keyed by path, so that
+// unchanged files aren't rehashed each time an import's progress is checked.
+type sourceDigests map[string]sourceDigest
+
+// sourceDigest is the MD5 digest of a file along with the file's size and
+// modification time at the time it was hashed.
+type sourceDigest struct {
+	size    int64
+	modTime time.Time
+	digest  string
+}
+
+/* ------------------------------- Method: get ------------------------------ */
+
+// get returns the hex-encoded MD5 digest of the file at 'path', matching the
+// editor's 'FileAccess::get_md5'.
+func (d sourceDigests) get(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	if cached, ok := d[path]; ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.digest, nil
+	}
+
+	h := md5.New() //nolint:gosec
+	if err := osutil.HashFile(h, path); err != nil {
+		return "", err
+	}
+
+	digest := hex.EncodeToString(h.Sum(nil))
+
+	d[path] = sourceDigest{size: info.Size(), modTime: info.ModTime(), digest: digest}
+
+	return digest, nil
+}
+
+/* ------------------------- Function: checkImported ------------------------ */
+
+// checkImported returns an error if any of the project's assets weren't
+// imported.
+func checkImported(rc *run.Context) error {
+	missing, err := findUnimportedAssets(rc.PathWorkspace, sourceDigests{})
+	if err != nil {
+		return err
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf(
+			"%w: editor exited without importing %d asset(s): %s",
+			ErrImportFailed,
+			len(missing),
+			summarizeFiles(missing),
+		)
+	}
+
+	return nil
+}
+
+/* --------------------- Function: newImportTimeoutError -------------------- */
+
+func newImportTimeoutError(rc *run.Context, timeout time.Duration) error {
+	err := fmt.Errorf(
+		"%w: timed out after %s waiting for the editor to import assets; "+
+			"increase the target's 'import_timeout' to wait longer",
+		ErrImportFailed,
+		timeout,
+	)
+
+	missing, errFind := findUnimportedAssets(rc.PathWorkspace, sourceDigests{})
+	if errFind != nil || len(missing) == 0 {
+		return err
 	}
 
-	return &cmd
+	return fmt.Errorf("%w (%d asset(s) not imported: %s)", err, len(missing), summarizeFiles(missing))
 }
 
 /* -------------------------------------------------------------------------- */
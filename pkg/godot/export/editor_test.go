@@ -0,0 +1,169 @@
+package export_test
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+func TestNewLoadProjectAction(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test requires a POSIX shell")
+	}
+
+	tests := []struct {
+		name string
+
+		version string
+		cached  bool   // Whether an out of date import of the asset exists.
+		editor  string // Contents of a fake editor script; '$IMPORT' imports.
+		timeout time.Duration
+
+		stopped bool // Whether the editor must be stopped gracefully.
+		err     error
+	}{
+		{
+			name:    "editor with '--import' imports assets",
+			version: "4.3-stable",
+			editor:  "$IMPORT",
+		},
+		{
+			name:    "editor with '--import' which doesn't import assets returns an error",
+			version: "4.3-stable",
+			editor:  "exit 0",
+			err:     export.ErrImportFailed,
+		},
+		{
+			name:    "editor with '--import' which fails returns an error",
+			version: "4.3-stable",
+			editor:  "exit 1",
+			err:     export.ErrImportFailed,
+		},
+		{
+			name:    "editor with '--import' which doesn't reimport a changed asset returns an error",
+			version: "4.3-stable",
+			cached:  true,
+			editor:  "exit 0",
+			err:     export.ErrImportFailed,
+		},
+		{
+			name:    "editor without '--import' is stopped once assets are imported",
+			version: "4.2.1-stable",
+			editor:  "$IMPORT\nsleep 60 & wait",
+			stopped: true,
+		},
+		{
+			name:    "editor without '--import' is stopped once a changed asset is reimported",
+			version: "4.2.1-stable",
+			cached:  true,
+			editor:  "sleep 1\n$IMPORT\nsleep 60 & wait",
+			stopped: true,
+		},
+		{
+			name:    "editor without '--import' which doesn't import assets times out",
+			version: "4.2.1-stable",
+			editor:  "sleep 60 & wait",
+			timeout: time.Second,
+			stopped: true,
+			err:     export.ErrImportFailed,
+		},
+		{
+			name:    "editor without '--import' which doesn't reimport a changed asset times out",
+			version: "4.2.1-stable",
+			cached:  true,
+			editor:  "sleep 60 & wait",
+			timeout: 5 * time.Second,
+			stopped: true,
+			err:     export.ErrImportFailed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A project with an asset.
+			root := t.TempDir()
+
+			require.NoError(t, os.WriteFile(filepath.Join(root, "icon.png"), []byte("image"), 0o600))
+			require.NoError(t, os.WriteFile(
+				filepath.Join(root, "icon.png.import"),
+				[]byte("[remap]\n\nimporter=\"texture\"\n\n[deps]\n\nsource_file=\"res://icon.png\"\ndest_files=[\"res://.godot/imported/icon.ctex\"]\n"),
+				0o600,
+			))
+
+			// Given: The location of the editor's record of the asset's import.
+			pathImported := filepath.Join(root, ".godot", "imported")
+			pathRecord := filepath.Join(pathImported, "icon.png-"+digest("res://icon.png")+".md5")
+
+			// Given: An import of a previous version of the asset (e.g. one
+			// restored from the import cache).
+			if tc.cached {
+				require.NoError(t, os.MkdirAll(pathImported, 0o750))
+				require.NoError(t, os.WriteFile(filepath.Join(pathImported, "icon.ctex"), []byte("old"), 0o600))
+				require.NoError(t, os.WriteFile(pathRecord, []byte(record("old image")), 0o600))
+			}
+
+			// Given: A fake Godot editor which records being interrupted.
+			pathEditor := filepath.Join(t.TempDir(), "godot")
+			pathStopped := filepath.Join(t.TempDir(), "stopped")
+
+			script := strings.ReplaceAll(tc.editor, "$IMPORT", fmt.Sprintf(
+				"mkdir -p '%s' && touch '%s' && printf '%%s' '%s' > '%s'",
+				pathImported,
+				filepath.Join(pathImported, "icon.ctex"),
+				record("image"),
+				pathRecord,
+			))
+
+			require.NoError(t, os.WriteFile(
+				pathEditor,
+				[]byte("#!/bin/sh\ntrap \"touch '"+pathStopped+"'; exit 0\" INT\n"+script+"\n"),
+				0o700, //nolint:gosec
+			))
+
+			var ev engine.Version
+			require.NoError(t, ev.UnmarshalText([]byte(tc.version)))
+
+			rc := run.Context{PathWorkspace: osutil.Path(root)} //nolint:exhaustruct
+
+			// When: The project is loaded.
+			err := export.NewLoadProjectAction(&rc, ev, osutil.Path(pathEditor), tc.timeout).Run(context.Background())
+
+			// Then: The returned error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+
+			// Then: The editor was interrupted, rather than killed, if stopped.
+			_, err = os.Stat(pathStopped)
+			assert.Equal(t, tc.stopped, err == nil)
+		})
+	}
+}
+
+// digest returns the hex-encoded MD5 digest of 's'.
+func digest(s string) string {
+	sum := md5.Sum([]byte(s)) //nolint:gosec
+
+	return hex.EncodeToString(sum[:])
+}
+
+// record returns the contents of the editor's record of an asset's import.
+func record(contents string) string {
+	return "source_md5=\"" + digest(contents) + "\"\n"
+}
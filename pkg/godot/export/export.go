@@ -5,6 +5,8 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
 	"golang.org/x/exp/maps"
 
@@ -21,6 +23,8 @@ var (
 	ErrConflictingValue = errors.New("conflicting setting")
 	ErrInvalidInput     = errors.New("invalid input")
 	ErrMissingInput     = errors.New("missing input")
+
+	ErrPackContentsMismatch = errors.New("pack contents mismatch")
 )
 
 /* -------------------------------------------------------------------------- */
@@ -34,19 +38,43 @@ type Export struct {
 	Arch platform.Arch
 	// EncryptionKey is an encryption key used to encrypt game assets with.
 	EncryptionKey string
+	// ExtraArtifacts are the names of additional files, relative to the output
+	// directory, which are generated alongside the exported pack files (e.g.
+	// the '.wasm' binary of a web export). If these are missing, 'gdbuild'
+	// will consider the export to have failed.
+	ExtraArtifacts []string `hash:"ignore"`
 	// Features contains the slice of Godot project feature tags to build with.
 	Features []string
+	// ImportTimeout is the maximum duration to wait for the Godot editor to
+	// import the project's assets. If unset, 'DefaultImportTimeout' is used.
+	ImportTimeout time.Duration `hash:"ignore"`
 	// Options are 'export_presets.cfg' overrides, specifically the preset
 	// 'options' table, for the exported artifact.
 	Options map[string]any
+	// PathOptions are 'export_presets.cfg' overrides, like 'Options', whose
+	// values are paths on the local machine (e.g. a keystore). These are
+	// excluded from the checksum so that it doesn't depend on where files are
+	// located; dependencies on file contents should be recorded in 'Paths'.
+	PathOptions map[string]osutil.Path `hash:"ignore"`
 	// PackFiles defines the game files exported as part of this artifact.
 	PackFiles []PackFile
+	// Patch describes the changes since a baseline build when only the changed
+	// game files are exported. It's nil for a complete export.
+	Patch *PatchDiff
+	// Paths is a list of additional files and folders which this export
+	// depends on. Useful for recording dependencies which are defined in
+	// otherwise opaque properties like 'Postexport'.
+	Paths []osutil.Path `hash:"ignore"`
 	// PathTemplate is a path to the export template to use during exporting.
 	PathTemplate osutil.Path `hash:"ignore"`
 	// PathTemplateArchive is an optional path to a non-cached export template
 	// archive containing the export template to use. If specified, this will
 	// take priority over 'Template'.
 	PathTemplateArchive osutil.Path `hash:"ignore"`
+	// SigningFingerprint is a fingerprint of the code signing certificate used
+	// to sign the exported artifacts (e.g. a SHA-256 hash). This is included
+	// so that the checksum changes when the signing identity does.
+	SigningFingerprint string
 	// Template specifies the export template to use.
 	Template *template.Template `hash:"string"`
 	// RunBefore contains an ordered list of actions to execute prior to
@@ -55,12 +83,19 @@ type Export struct {
 	// RunAfter contains an ordered list of actions to execute after exporting
 	// the target.
 	RunAfter action.Action `hash:"string"`
+	// Postexport contains an ordered list of platform-specific actions (e.g.
+	// code signing) to execute after exporting the target. These are run
+	// prior to 'RunAfter' and the verification of exported artifacts.
+	Postexport action.Action `hash:"string"`
 	// Runnable is whether the export artifact should be executable. This should
 	// be true for client and server targets and false for artifacts like DLC.
 	Runnable bool
 	// Server configures the target as a server-only executable, enabling some
 	// optimizations like disabling graphics.
 	Server bool
+	// VerifyPacks is whether to verify the contents of exported pack files
+	// against their configuration after exporting.
+	VerifyPacks bool `hash:"ignore"`
 	// Version is the editor version to use for exporting.
 	Version engine.Version
 }
@@ -74,20 +109,32 @@ func (x *Export) Action(rc *run.Context, pathGodot osutil.Path) (action.Action,
 		return nil, err
 	}
 
-	exports := make([]action.Action, 0, 3+len(presets)) //nolint:gomnd
+	// NOTE: Reuse previously imported assets so that only changed assets need
+	// to be imported again.
+	loadProject, err := NewImportCacheAction(
+		rc,
+		x.Version,
+		NewLoadProjectAction(rc, x.Version, pathGodot, x.ImportTimeout),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	exports := make([]action.Action, 0, 2+len(presets)) //nolint:gomnd
 
 	exports = append(
 		exports,
 		NewWriteExportPresetsAction(rc, x),
-		NewRemoveAllAction(rc.PathWorkspace.Join(".godot").String()),
-		NewLoadProjectAction(rc, pathGodot),
+		loadProject,
 	)
 
 	for _, preset := range presets {
 		exports = append(exports, NewExportAction(rc, preset, pathGodot))
 	}
 
-	return action.InOrder(exports...), nil
+	// NOTE: Restore the project's original files afterwards so that exporting
+	// doesn't modify the user's presets or imported resources.
+	return NewRestoreProjectAction(rc, action.InOrder(exports...)), nil
 }
 
 /* ----------------------------- Method: Presets ---------------------------- */
@@ -138,9 +185,23 @@ func (x *Export) Artifacts(rc *run.Context) ([]string, error) {
 		artifacts[preset.Name] = struct{}{}
 	}
 
+	for _, a := range x.ExtraArtifacts {
+		artifacts[a] = struct{}{}
+	}
+
 	return maps.Keys(artifacts), nil
 }
 
+/* --------------------- Method: RegisterDependencyPath --------------------- */
+
+// RegisterDependencyPath is a convenience function for registering a 'Path'
+// dependency, but only if it hasn't been added yet.
+func (x *Export) RegisterDependencyPath(path osutil.Path) {
+	if !slices.Contains(x.Paths, path) {
+		x.Paths = append(x.Paths, path)
+	}
+}
+
 /* -------------------------------------------------------------------------- */
 /*                          Function: NewExportAction                         */
 /* -------------------------------------------------------------------------- */
@@ -158,8 +219,10 @@ func NewExportAction( //nolint:ireturn
 	cmd.Directory = rc.PathWorkspace.String()
 
 	if preset.EncryptionKey != "" {
+		// NOTE: Inherit the current environment so that other editor settings
+		// (e.g. Android keystore passwords) are still available.
 		cmd.Environment = append(
-			cmd.Environment,
+			os.Environ(),
 			"GODOT_SCRIPT_ENCRYPTION_KEY="+preset.EncryptionKey,
 		)
 	}
@@ -190,7 +253,7 @@ func NewExportAction( //nolint:ireturn
 	cmd.Args = append(
 		cmd.Args,
 		"--export-"+command,
-		preset.Name,
+		preset.ExportName(),
 		pathArtifact,
 	)
 
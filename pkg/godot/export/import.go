@@ -0,0 +1,265 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"hash/crc64"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/archive"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+	"github.com/coffeebeats/gdbuild/pkg/store"
+)
+
+const (
+	// dirnameImportedFiles is the name of the directory, within the project's
+	// editor data directory, which contains imported resources.
+	dirnameImportedFiles = "imported"
+	// filenameGDIgnore is the name of a file which excludes its directory from
+	// the Godot project.
+	filenameGDIgnore = ".gdignore"
+	// filenameUIDCache is the name of the project's cache of resource UIDs,
+	// located within the project's editor data directory.
+	filenameUIDCache = "uid_cache.bin"
+
+	extImport = ".import"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                      Function: NewImportCacheAction                        */
+/* -------------------------------------------------------------------------- */
+
+// NewImportCacheAction wraps the action 'a', which imports the project's assets
+// (see 'NewLoadProjectAction'), such that the project's import state (i.e. the
+// '.godot/imported' directory and the UID cache) is restored from the 'gdbuild'
+// store beforehand and cached in the store afterwards. The most recent import
+// state for the editor version 'ev' and the project's import settings is
+// restored, so the editor only reimports assets which changed since then.
+func NewImportCacheAction( //nolint:ireturn
+	rc *run.Context,
+	ev engine.Version,
+	a action.Action,
+) (action.Action, error) {
+	storePath, err := store.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := importCacheKey(rc, ev)
+	if err != nil {
+		return nil, err
+	}
+
+	pathEditorData := rc.PathWorkspace.Join(dirnameImported).String()
+
+	restore := func(ctx context.Context) error {
+		pathArchive, err := store.LatestImportArchive(storePath, key)
+		if err != nil {
+			return err
+		}
+
+		if pathArchive == "" {
+			log.Debugf("no cached import found; importing all assets")
+
+			return nil
+		}
+
+		log.Debugf("restoring cached import: %s", pathArchive)
+
+		if err := os.MkdirAll(pathEditorData, osutil.ModeUserRWXGroupRX); err != nil {
+			return err
+		}
+
+		if err := archive.Extract(ctx, pathArchive, pathEditorData); err != nil {
+			// NOTE: The cache is only an optimization, so discard a partially
+			// extracted import and import all assets instead.
+			log.Warnf("failed to restore cached import; importing all assets: %s", err)
+
+			return errors.Join(
+				os.RemoveAll(filepath.Join(pathEditorData, dirnameImportedFiles)),
+				os.RemoveAll(filepath.Join(pathEditorData, filenameUIDCache)),
+			)
+		}
+
+		return nil
+	}
+
+	cache := func(_ context.Context) error {
+		cs, err := importChecksum(rc.PathWorkspace)
+		if err != nil {
+			return err
+		}
+
+		pathArchive, err := store.ImportArchive(storePath, key, cs)
+		if err != nil {
+			return err
+		}
+
+		if err := osutil.Path(pathArchive).CheckIsFile(); err == nil {
+			log.Debugf("cached import is up to date: %s", pathArchive)
+
+			return nil
+		}
+
+		artifacts := make([]string, 0, 2) //nolint:gomnd
+
+		for _, name := range []string{dirnameImportedFiles, filenameUIDCache} {
+			if _, err := os.Stat(filepath.Join(pathEditorData, name)); err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+
+				return err
+			}
+
+			artifacts = append(artifacts, name)
+		}
+
+		if len(artifacts) == 0 {
+			log.Debugf("no imported assets found; skipping import cache")
+
+			return nil
+		}
+
+		if err := os.MkdirAll(filepath.Dir(pathArchive), osutil.ModeUserRWXGroupRX); err != nil {
+			return err
+		}
+
+		// NOTE: Write the archive to a hidden file first so that a partially
+		// written archive is never restored.
+		pathTmp := filepath.Join(filepath.Dir(pathArchive), "."+filepath.Base(pathArchive))
+
+		if err := archive.Create(pathEditorData, artifacts, pathTmp); err != nil {
+			return errors.Join(err, os.Remove(pathTmp))
+		}
+
+		if err := os.Rename(pathTmp, pathArchive); err != nil {
+			return errors.Join(err, os.Remove(pathTmp))
+		}
+
+		return store.PruneImportArchives(storePath, key, pathArchive)
+	}
+
+	return action.InOrder(
+		action.WithDescription[action.Function]{
+			Action:      restore,
+			Description: "restore cached import from store: " + storePath,
+		},
+		a,
+		action.WithDescription[action.Function]{
+			Action:      cache,
+			Description: "cache imported assets in store: " + storePath,
+		},
+	), nil
+}
+
+/* ------------------------- Function: importCacheKey ----------------------- */
+
+// importCacheKey returns a key identifying the project, the editor version, and
+// the project settings which affect importing; cached imports are only restored
+// into exports with the same key.
+//
+// NOTE: The project's location is included so that different projects never
+// share (and prune) each other's cached imports, which contain project-specific
+// state like the UID cache.
+func importCacheKey(rc *run.Context, ev engine.Version) (string, error) {
+	pathProject, err := filepath.Abs(rc.PathWorkspace.String())
+	if err != nil {
+		return "", err
+	}
+
+	cs := crc64.New(crc64.MakeTable(crc64.ECMA))
+
+	if _, err := io.WriteString(cs, pathProject+"\n"+ev.String()+"\n"); err != nil {
+		return "", err
+	}
+
+	if rc.Project != nil {
+		for _, s := range rc.Project.ImportSettings() {
+			if _, err := io.WriteString(cs, s+"\n"); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return strconv.FormatUint(cs.Sum64(), 16), nil
+}
+
+/* ------------------------- Function: importChecksum ----------------------- */
+
+// importChecksum produces a checksum hash of the project's imported assets,
+// including both each source file and its '.import' file.
+func importChecksum(root osutil.Path) (string, error) {
+	cs := crc64.New(crc64.MakeTable(crc64.ECMA))
+
+	err := walkImportFiles(root, func(path string) error {
+		if err := osutil.HashFileWithName(cs, root.String(), path); err != nil {
+			return err
+		}
+
+		source := strings.TrimSuffix(path, extImport)
+
+		// NOTE: A source file may have been removed without its '.import' file.
+		info, err := os.Stat(root.Join(source).String())
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		return osutil.HashFileWithName(cs, root.String(), source)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.FormatUint(cs.Sum64(), 16), nil
+}
+
+/* ------------------------ Function: walkImportFiles ----------------------- */
+
+// walkImportFiles calls 'fn' with the path, relative to 'root', of each of the
+// project's '.import' files in lexical order. Hidden directories (e.g. '.godot')
+// and directories excluded via '.gdignore' are skipped, just as they are by the
+// editor.
+func walkImportFiles(root osutil.Path, fn func(path string) error) error {
+	return fs.WalkDir(os.DirFS(root.String()), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != "." && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+
+			if err := root.Join(path, filenameGDIgnore).CheckIsFile(); err == nil {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+
+		if !strings.HasSuffix(path, extImport) {
+			return nil
+		}
+
+		return fn(path)
+	})
+}
@@ -0,0 +1,151 @@
+package export_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+func TestNewImportCacheAction(t *testing.T) {
+	// Given: A store.
+	pathStore := t.TempDir()
+	t.Setenv("GDBUILD_HOME", pathStore)
+
+	// Given: A project with an imported asset.
+	root := t.TempDir()
+
+	pathAsset := filepath.Join(root, "icon.png")
+	pathImported := filepath.Join(root, ".godot", "imported", "icon.ctex")
+
+	require.NoError(t, os.WriteFile(pathAsset, []byte("image"), 0o600))
+	require.NoError(t, os.WriteFile(pathAsset+".import", []byte("[remap]\n"), 0o600))
+
+	rc := run.Context{PathWorkspace: osutil.Path(root)} //nolint:exhaustruct
+
+	var ev engine.Version
+	require.NoError(t, ev.UnmarshalText([]byte("4.2.1-stable")))
+
+	// Given: An import which records whether the asset was already imported.
+	var restored bool
+
+	var importAssets action.Function = func(_ context.Context) error {
+		_, err := os.Stat(pathImported)
+		restored = err == nil
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(pathImported), 0o750))
+
+		return os.WriteFile(pathImported, []byte("texture"), 0o600)
+	}
+
+	runImport := func(ev engine.Version) []string {
+		require.NoError(t, os.RemoveAll(filepath.Join(root, ".godot")))
+
+		a, err := export.NewImportCacheAction(&rc, ev, importAssets)
+		require.NoError(t, err)
+
+		require.NoError(t, a.Run(context.Background()))
+
+		archives, err := filepath.Glob(filepath.Join(pathStore, "imports", "*", "*"))
+		require.NoError(t, err)
+
+		return archives
+	}
+
+	// When: The project is imported for the first time.
+	first := runImport(ev)
+
+	// Then: Nothing was restored, but the import is cached.
+	assert.False(t, restored)
+	assert.Len(t, first, 1)
+
+	// When: The project is imported again without changes.
+	second := runImport(ev)
+
+	// Then: The cached import is restored and reused.
+	assert.True(t, restored)
+	assert.Equal(t, first, second)
+
+	// When: The project is imported after an asset changes.
+	require.NoError(t, os.WriteFile(pathAsset, []byte("new image"), 0o600))
+
+	third := runImport(ev)
+
+	// Then: The cached import is restored and replaced by the new import.
+	assert.True(t, restored)
+	assert.Len(t, third, 1)
+	assert.NotEqual(t, first, third)
+
+	// When: The project is imported with a different editor version.
+	require.NoError(t, ev.UnmarshalText([]byte("4.3-stable")))
+
+	fourth := runImport(ev)
+
+	// Then: Nothing was restored, and the import is cached separately.
+	assert.False(t, restored)
+	assert.Len(t, fourth, 2)
+}
+
+func TestNewImportCacheActionSeparatesProjects(t *testing.T) {
+	// Given: A store.
+	pathStore := t.TempDir()
+	t.Setenv("GDBUILD_HOME", pathStore)
+
+	var ev engine.Version
+	require.NoError(t, ev.UnmarshalText([]byte("4.2.1-stable")))
+
+	// runImport imports a project with identical contents and settings at a new
+	// location, returning whether a cached import was restored.
+	runImport := func() bool {
+		root := t.TempDir()
+
+		pathAsset := filepath.Join(root, "icon.png")
+		pathImported := filepath.Join(root, ".godot", "imported", "icon.ctex")
+
+		require.NoError(t, os.WriteFile(pathAsset, []byte("image"), 0o600))
+		require.NoError(t, os.WriteFile(pathAsset+".import", []byte("[remap]\n"), 0o600))
+
+		rc := run.Context{PathWorkspace: osutil.Path(root)} //nolint:exhaustruct
+
+		var restored bool
+
+		var importAssets action.Function = func(_ context.Context) error {
+			_, err := os.Stat(pathImported)
+			restored = err == nil
+
+			require.NoError(t, os.MkdirAll(filepath.Dir(pathImported), 0o750))
+
+			return os.WriteFile(pathImported, []byte("texture"), 0o600)
+		}
+
+		a, err := export.NewImportCacheAction(&rc, ev, importAssets)
+		require.NoError(t, err)
+
+		require.NoError(t, a.Run(context.Background()))
+
+		return restored
+	}
+
+	// When: Two different projects are imported.
+	first := runImport()
+	second := runImport()
+
+	// Then: Neither project restores the other's import.
+	assert.False(t, first)
+	assert.False(t, second)
+
+	// Then: Each project's import is cached separately.
+	archives, err := filepath.Glob(filepath.Join(pathStore, "imports", "*", "*"))
+	require.NoError(t, err)
+
+	assert.Len(t, archives, 2)
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"os"
+
+	"github.com/coffeebeats/gdbuild/pkg/godot/variant"
+)
+
+var ErrUnsupportedOption = variant.ErrUnsupportedType
+
+/* -------------------------------------------------------------------------- */
+/*                           Function: EncodeOption                           */
+/* -------------------------------------------------------------------------- */
+
+// EncodeOption serializes an export preset option value into the format used
+// by 'export_presets.cfg' (see 'variant.Encode'). TOML values are written as
+// their Godot equivalents: arrays of strings become a 'PackedStringArray',
+// other arrays become an 'Array', and tables become a 'Dictionary'. Strings
+// are interpolated with environment variables.
+func EncodeOption(value any) (string, error) {
+	return variant.Encode(toVariant(value))
+}
+
+/* --------------------------- Function: toVariant -------------------------- */
+
+// toVariant converts an option value into the value which should be encoded,
+// interpolating environment variables in all strings within 'value' and
+// converting arrays of strings into a 'PackedStringArray'.
+func toVariant(value any) any {
+	switch value := value.(type) {
+	case string:
+		return os.ExpandEnv(value)
+	case []string:
+		out := make([]string, len(value))
+		for i, s := range value {
+			out[i] = os.ExpandEnv(s)
+		}
+
+		return out
+	case []any:
+		if ss, ok := toStringSlice(value); ok {
+			return toVariant(ss)
+		}
+
+		out := make([]any, len(value))
+		for i, e := range value {
+			out[i] = toVariant(e)
+		}
+
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(value))
+		for k, e := range value {
+			out[k] = toVariant(e)
+		}
+
+		return out
+	default:
+		return value
+	}
+}
+
+/* ------------------------- Function: toStringSlice ------------------------ */
+
+// toStringSlice converts a non-empty array into a slice of strings if every
+// element is a string.
+func toStringSlice(elements []any) ([]string, bool) {
+	if len(elements) == 0 {
+		return nil, false
+	}
+
+	out := make([]string, len(elements))
+
+	for i, e := range elements {
+		s, ok := e.(string)
+		if !ok {
+			return nil, false
+		}
+
+		out[i] = s
+	}
+
+	return out, true
+}
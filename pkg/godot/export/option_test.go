@@ -0,0 +1,63 @@
+package export_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+)
+
+func TestEncodeOption(t *testing.T) {
+	t.Setenv("GDBUILD_TEST_NAME", "Game")
+
+	tests := []struct {
+		name string
+
+		value any
+
+		want string
+		err  error
+	}{
+		{name: "string", value: "abc", want: `"abc"`},
+		{name: "string with escapes", value: "a\\\"b\"\n`c`;#", want: "\"a\\\\\\\"b\\\"\\n`c`;#\""},
+		{name: "string with variable", value: "${GDBUILD_TEST_NAME}!", want: `"Game!"`},
+		{name: "resource path", value: "res://icon.png", want: `"res://icon.png"`},
+		{name: "bool", value: true, want: "true"},
+		{name: "int", value: int64(-3), want: "-3"},
+		{name: "uint", value: uint(3), want: "3"},
+		{name: "float", value: 1.5, want: "1.5"},
+		{name: "whole float", value: 2.0, want: "2.0"},
+		{name: "infinite float", value: math.Inf(-1), want: "inf_neg"},
+		{name: "string array", value: []any{"a", "${GDBUILD_TEST_NAME}"}, want: `PackedStringArray("a", "Game")`},
+		{name: "string slice", value: []string{"a", "b"}, want: `PackedStringArray("a", "b")`},
+		{name: "empty array", value: []any{}, want: "[]"},
+		{name: "mixed array", value: []any{int64(1), "a", []any{true}}, want: `[1, "a", [true]]`},
+		{
+			name:  "dictionary",
+			value: map[string]any{"b": []any{"x"}, "a": map[string]any{"c": 0.5}},
+			want:  `{"a": {"c": 0.5}, "b": PackedStringArray("x")}`,
+		},
+		{name: "nil", value: nil, want: "null"},
+		{name: "datetime", value: time.Time{}, err: export.ErrUnsupportedOption},
+		{name: "nested datetime", value: map[string]any{"a": []any{time.Time{}}}, err: export.ErrUnsupportedOption},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: The option value is encoded.
+			got, err := export.EncodeOption(tc.value)
+
+			// Then: The returned error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+
+			// Then: The encoded value matches expectations.
+			if got != tc.want {
+				t.Fatalf("output: got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -52,6 +52,11 @@ type PackFile struct {
 	// Zip defines whether to compress the matching game files. The pack files
 	// will use the '.zip' extension instead of '.pck'.
 	Zip *bool `toml:"zip"`
+
+	// files is an explicit list of the game files in this pack file. This is
+	// set on the pack files produced by partitioning, in which case 'Include'
+	// and 'Exclude' are only used for encryption filters.
+	files []osutil.Path
 }
 
 /* ----------------------------- Method: Preset ----------------------------- */
@@ -60,12 +65,14 @@ type PackFile struct {
 func (c *PackFile) Preset(rc *run.Context, xp *Export, index int) (Preset, error) {
 	var preset Preset
 
-	preset.Options = map[string]string{}
+	preset.Options = maps.Clone(xp.Options)
 
-	for key, value := range xp.Options {
-		if value, ok := value.(string); ok {
-			preset.Options[key] = value
+	for key, path := range xp.PathOptions {
+		if preset.Options == nil {
+			preset.Options = make(map[string]any, len(xp.PathOptions))
 		}
+
+		preset.Options[key] = path.String()
 	}
 
 	preset.Arch = xp.Arch
@@ -139,8 +146,12 @@ func (c *PackFile) Filename(pl platform.OS, targetName string, index int) string
 func (c *PackFile) Extension(pl platform.OS) string {
 	if config.Dereference(c.Embed) {
 		switch pl {
+		case platform.OSAndroid:
+			return ".apk"
 		case platform.OSMacOS:
 			return ".app/"
+		case platform.OSWeb:
+			return ".html"
 		case platform.OSWindows:
 			return ".exe"
 		default:
@@ -166,6 +177,10 @@ func (c *PackFile) StripVisuals() bool {
 /* ------------------------------ Method: Files ----------------------------- */
 
 func (c *PackFile) Files(path osutil.Path) ([]osutil.Path, error) { //nolint:funlen
+	if c.files != nil {
+		return slices.Clone(c.files), nil
+	}
+
 	pathRoot, err := filepath.Abs(path.String())
 	if err != nil {
 		return nil, err
@@ -328,6 +343,17 @@ func (c *PackFile) Validate(rc *run.Context) error {
 		}
 	}
 
+	if config.Dereference(c.Embed) && c.Partition.IsEnabled() {
+		return fmt.Errorf(
+			"%w: cannot partition an embedded pack file",
+			ErrInvalidInput,
+		)
+	}
+
+	if err := c.Partition.Validate(rc); err != nil {
+		return err
+	}
+
 	if len(c.Include) == 0 {
 		return fmt.Errorf(
 			"%w: missing required 'glob' property for pack file",
@@ -350,36 +376,3 @@ func (c *PackFile) Validate(rc *run.Context) error {
 
 	return nil
 }
-
-/* -------------------------------------------------------------------------- */
-/*                          Struct: PackFilePartition                         */
-/* -------------------------------------------------------------------------- */
-
-// PackFilePartition describes how to automatically partition a collection of
-// files into multiple '.pck' files.
-//
-// NOTE: This struct contains multiple different expressions of limits, multiple
-// of which may be true at a time. If any of the contained rules would trigger a
-// new '.pck' to be formed within a partition, then that rule will be respected.
-type PackFilePartition struct {
-	// Depth is the maximum folder depth from the project directory containing
-	// the GDBuild manifest to split files between. Any folders past this depth
-	// limit will all be included within the same '.pck' file.
-	Depth uint `toml:"depth"`
-	// Limit describes limits on the files within individual '.pck' files in the
-	// partition.
-	Limit PackFilePartitionLimit `toml:"limit"`
-}
-
-/* --------------------- Struct: PackFilePartitionLimit --------------------- */
-
-// PackFilePartitionLimit describes limits used to determine when a new '.pck'
-// file within a partition should be started.
-type PackFilePartitionLimit struct {
-	// Size is a human-readable file size limit that all '.pck' files within the
-	// partition must adhere to.
-	Size string `toml:"size"`
-	// Files is the maximum count of files within a single '.pck' file within a
-	// partition.
-	Files uint `toml:"files"`
-}
@@ -0,0 +1,321 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/godot/project"
+	"github.com/coffeebeats/gdbuild/pkg/godot/variant"
+)
+
+const (
+	optionArchitecture    = "binary_format/architecture"
+	optionEmbedPCK        = "binary_format/embed_pck"
+	optionTemplateDebug   = "custom_template/debug"
+	optionTemplateRelease = "custom_template/release"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                            Function: ParsePresets                          */
+/* -------------------------------------------------------------------------- */
+
+// ParsePresets parses the contents of an 'export_presets.cfg' file into the
+// presets it defines, ordered by their index, reading the format written by
+// 'Marshal'. Options managed by 'gdbuild' (e.g. the export template paths) are
+// omitted, as are options with values that can't be represented in a 'Preset'.
+// Presets with the reserved 'PresetNamePrefix' were generated by 'gdbuild' and
+// are skipped, matching 'MergePresets'.
+func ParsePresets(cfg string) ([]Preset, error) {
+	sections, err := parseSections(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	presets := make([]Preset, 0, len(sections)/2) //nolint:gomnd
+
+	for _, s := range sections {
+		if s.index < 0 || s.options || strings.HasPrefix(s.name, PresetNamePrefix) {
+			continue
+		}
+
+		var preset Preset
+
+		if err := preset.unmarshal(propertiesOf(&s.Section)); err != nil {
+			return nil, fmt.Errorf("%w: preset.%d: %w", ErrInvalidInput, s.index, err)
+		}
+
+		for _, o := range sections {
+			if !o.options || o.index != s.index {
+				continue
+			}
+
+			if err := preset.unmarshalOptions(propertiesOf(&o.Section)); err != nil {
+				return nil, fmt.Errorf("%w: preset.%d.options: %w", ErrInvalidInput, s.index, err)
+			}
+		}
+
+		presets = append(presets, preset)
+	}
+
+	return presets, nil
+}
+
+/* ---------------------------- Method: unmarshal --------------------------- */
+
+func (p *Preset) unmarshal(properties map[string]string) error { //nolint:cyclop,funlen
+	for key, raw := range properties {
+		value, err := variant.Decode(raw)
+		if err != nil {
+			return fmt.Errorf("property '%s': %w", key, err)
+		}
+
+		switch key {
+		case "name":
+			p.Name = toString(value)
+		case "platform":
+			pl, err := parseExportPlatform(toString(value))
+			if err != nil {
+				return err
+			}
+
+			p.Platform = pl
+		case "runnable":
+			p.Runnable, _ = value.(bool)
+		case "dedicated_server":
+			p.Server, _ = value.(bool)
+		case "custom_features":
+			p.Features = splitList(toString(value))
+		case "export_filter":
+			p.ExportMode = Mode(toString(value))
+		case "include_filter":
+			p.Include = toString(value)
+		case "exclude_filter":
+			p.Exclude = toString(value)
+		case "export_files":
+			p.ExportedFiles = toStrings(value)
+		case "encrypt_pck":
+			p.Encrypt, _ = value.(bool)
+		case "encrypt_directory":
+			p.EncryptIndex, _ = value.(bool)
+		case "encryption_include_filters":
+			p.Encrypted = splitList(toString(value))
+		case "customized_files":
+			files, ok := value.(variant.Dictionary)
+			if !ok {
+				return fmt.Errorf("%w: expected a dictionary: %s", ErrInvalidInput, key)
+			}
+
+			p.CustomizedFiles = make(map[string]string, len(files))
+
+			for _, e := range files {
+				path, _ := e.Key.(string)
+				mode, _ := e.Value.(string)
+
+				p.CustomizedFiles[path] = mode
+			}
+		}
+	}
+
+	return nil
+}
+
+/* ------------------------ Method: unmarshalOptions ------------------------ */
+
+func (p *Preset) unmarshalOptions(properties map[string]string) error {
+	for key, raw := range properties {
+		value, err := variant.Decode(raw)
+		if err != nil {
+			return fmt.Errorf("property '%s': %w", key, err)
+		}
+
+		switch key {
+		case optionArchitecture:
+			arch, err := platform.ParseArch(toString(value))
+			if err != nil {
+				return err
+			}
+
+			p.Arch = arch
+
+			continue
+
+		case optionEmbedPCK:
+			// NOTE: Older versions of 'gdbuild' wrote this option as a string.
+			p.Embed = value == true || value == "true"
+
+			continue
+
+		case optionTemplateDebug, optionTemplateRelease:
+			continue // Export templates are managed by 'gdbuild'.
+		}
+
+		value, ok := optionValue(value)
+		if !ok {
+			log.Warnf("skipping export option with unsupported value: %s = %s", key, raw)
+
+			continue
+		}
+
+		if p.Options == nil {
+			p.Options = map[string]any{}
+		}
+
+		p.Options[key] = value
+	}
+
+	return nil
+}
+
+/* -------------------------- Function: optionValue ------------------------- */
+
+// optionValue converts a decoded option value into one which can be written in
+// a GDBuild manifest (i.e. a 'bool', 'int64', 'float64', 'string', '[]string',
+// '[]any', or 'map[string]any'). Values of other types (e.g. vectors) can't be
+// represented and are reported as unsupported.
+func optionValue(value any) (any, bool) { //nolint:cyclop
+	switch v := value.(type) {
+	case bool, int64, float64, string, []string:
+		return v, true
+	case variant.StringName:
+		return string(v), true
+	case []any:
+		out := make([]any, len(v))
+
+		for i, e := range v {
+			converted, ok := optionValue(e)
+			if !ok {
+				return nil, false
+			}
+
+			out[i] = converted
+		}
+
+		return out, true
+	case variant.Dictionary:
+		m, ok := v.Map()
+		if !ok {
+			return nil, false
+		}
+
+		for k, e := range m {
+			converted, ok := optionValue(e)
+			if !ok {
+				return nil, false
+			}
+
+			m[k] = converted
+		}
+
+		return m, true
+	case []int32:
+		return toAnySlice(v, func(i int32) any { return int64(i) }), true
+	case []int64:
+		return toAnySlice(v, func(i int64) any { return i }), true
+	case []float32:
+		return toAnySlice(v, func(f float32) any { return float64(f) }), true
+	case []float64:
+		return toAnySlice(v, func(f float64) any { return f }), true
+	default:
+		return nil, false
+	}
+}
+
+/* --------------------------- Function: toAnySlice ------------------------- */
+
+func toAnySlice[T any](s []T, fn func(T) any) []any {
+	out := make([]any, len(s))
+	for i, e := range s {
+		out[i] = fn(e)
+	}
+
+	return out
+}
+
+/* --------------------- Function: parseExportPlatform --------------------- */
+
+// parseExportPlatform parses the name of a Godot export platform; this is the
+// inverse of 'Preset.exportPlatform'.
+func parseExportPlatform(name string) (platform.OS, error) {
+	switch name {
+	case "Android":
+		return platform.OSAndroid, nil
+	case "iOS":
+		return platform.OSIOS, nil
+	case "Linux", "Linux/X11", "Linux/BSD":
+		return platform.OSLinux, nil
+	case "macOS", "Mac OSX":
+		return platform.OSMacOS, nil
+	case "Web", "HTML5":
+		return platform.OSWeb, nil
+	case "Windows Desktop":
+		return platform.OSWindows, nil
+	default:
+		return platform.OSUnknown, fmt.Errorf("%w: unsupported platform: %s", ErrInvalidInput, name)
+	}
+}
+
+/* ------------------------ Function: propertiesOf ------------------------- */
+
+func propertiesOf(s *project.Section) map[string]string {
+	properties := make(map[string]string, len(s.Properties))
+
+	for _, p := range s.Properties {
+		properties[p.Key] = p.Value
+	}
+
+	return properties
+}
+
+/* --------------------------- Function: splitList -------------------------- */
+
+func splitList(s string) []string {
+	var out []string
+
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			out = append(out, e)
+		}
+	}
+
+	return out
+}
+
+/* --------------------------- Function: toString --------------------------- */
+
+func toString(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+/* --------------------------- Function: toStrings -------------------------- */
+
+func toStrings(value any) []string {
+	switch value := value.(type) {
+	case []string:
+		return value
+	case []any:
+		out := make([]string, 0, len(value))
+
+		for _, e := range value {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+
+		return out
+	case string:
+		// NOTE: Older versions of 'gdbuild' wrote an empty list as a string.
+		return splitList(value)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,315 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+// sizeUnits maps the supported (case-insensitive) file size suffixes to their
+// multipliers in bytes.
+var sizeUnits = map[string]float64{ //nolint:gochecknoglobals
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+}
+
+var sizePattern = regexp.MustCompile(`^\s*([0-9]+(?:\.[0-9]+)?)\s*([a-zA-Z]*)\s*$`)
+
+/* -------------------------------------------------------------------------- */
+/*                          Struct: PackFilePartition                         */
+/* -------------------------------------------------------------------------- */
+
+// PackFilePartition describes how to automatically partition a collection of
+// files into multiple '.pck' files.
+//
+// NOTE: This struct contains multiple different expressions of limits, multiple
+// of which may be true at a time. If any of the contained rules would trigger a
+// new '.pck' to be formed within a partition, then that rule will be respected.
+type PackFilePartition struct {
+	// Depth is the maximum folder depth from the project directory containing
+	// the GDBuild manifest to split files between. Any folders past this depth
+	// limit will all be included within the same '.pck' file.
+	Depth uint `toml:"depth"`
+	// Limit describes limits on the files within individual '.pck' files in the
+	// partition.
+	Limit PackFilePartitionLimit `toml:"limit"`
+}
+
+/* --------------------------- Method: IsEnabled ---------------------------- */
+
+// IsEnabled returns whether any partitioning rules are set.
+func (p *PackFilePartition) IsEnabled() bool {
+	return p.Depth > 0 || p.Limit.Files > 0 || p.Limit.Size != ""
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (p *PackFilePartition) Validate(_ *run.Context) error {
+	if _, err := p.Limit.Bytes(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/* ---------------------------- Method: Partition --------------------------- */
+
+// Partition splits the files in the project at 'root' into groups according
+// to the partitioning rules. Files are first grouped by their parent folder
+// (up to 'Depth' folders deep) and then each group is split further so that
+// no group exceeds the file count or size limits. A file's '.import' file is
+// always placed alongside it. The result is sorted by folder and then by path,
+// so the same set of files always produces the same partitions.
+func (p *PackFilePartition) Partition(root string, files []osutil.Path) ([][]osutil.Path, error) {
+	limitSize, err := p.Limit.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: '.import' files are kept in the same partition as their source
+	// file, so track them separately from the files being partitioned.
+	imports := make(map[osutil.Path]osutil.Path)
+	sources := make(map[osutil.Path]struct{}, len(files))
+
+	for _, f := range files {
+		sources[f] = struct{}{}
+
+		if src, ok := strings.CutSuffix(f.String(), ".import"); ok {
+			imports[osutil.Path(src)] = f
+		}
+	}
+
+	groups := make(map[string][]osutil.Path)
+
+	for _, f := range files {
+		if src, ok := strings.CutSuffix(f.String(), ".import"); ok {
+			if _, ok := sources[osutil.Path(src)]; ok {
+				continue
+			}
+		}
+
+		rel, err := filepath.Rel(root, f.String())
+		if err != nil {
+			return nil, err
+		}
+
+		key := p.folder(filepath.ToSlash(rel))
+		groups[key] = append(groups[key], f)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	var out [][]osutil.Path
+
+	for _, key := range keys {
+		group := groups[key]
+		slices.Sort(group)
+
+		var current []osutil.Path
+
+		var size int64
+
+		var count uint
+
+		for _, f := range group {
+			unit := []osutil.Path{f}
+			if imp, ok := imports[f]; ok {
+				unit = append(unit, imp)
+			}
+
+			var unitSize int64
+
+			for _, u := range unit {
+				info, err := os.Stat(u.String())
+				if err != nil {
+					return nil, err
+				}
+
+				unitSize += info.Size()
+			}
+
+			isOverFiles := p.Limit.Files > 0 && count >= p.Limit.Files
+			isOverSize := limitSize > 0 && size+unitSize > limitSize
+
+			if len(current) > 0 && (isOverFiles || isOverSize) {
+				out = append(out, current)
+				current, count, size = nil, 0, 0
+			}
+
+			current = append(current, unit...)
+			count++
+			size += unitSize
+		}
+
+		if len(current) > 0 {
+			out = append(out, current)
+		}
+	}
+
+	return out, nil
+}
+
+/* ----------------------------- Method: folder ----------------------------- */
+
+// folder returns the folder, truncated to 'Depth' folders, by which the file
+// at the slash-separated relative path 'rel' is grouped.
+func (p *PackFilePartition) folder(rel string) string {
+	if p.Depth == 0 {
+		return ""
+	}
+
+	parts := strings.Split(rel, "/")
+	parts = parts[:len(parts)-1] // Remove the filename.
+
+	if uint(len(parts)) > p.Depth {
+		parts = parts[:p.Depth]
+	}
+
+	return strings.Join(parts, "/")
+}
+
+/* --------------------- Struct: PackFilePartitionLimit --------------------- */
+
+// PackFilePartitionLimit describes limits used to determine when a new '.pck'
+// file within a partition should be started.
+type PackFilePartitionLimit struct {
+	// Size is a human-readable file size limit that all '.pck' files within the
+	// partition must adhere to (e.g. '500MB' or '2GiB'). The limit applies to
+	// the size of the matched project files; a single file larger than the
+	// limit will be placed in its own '.pck' file.
+	Size string `toml:"size"`
+	// Files is the maximum count of files within a single '.pck' file within a
+	// partition.
+	Files uint `toml:"files"`
+}
+
+/* ----------------------------- Method: Bytes ------------------------------ */
+
+// Bytes parses the size limit into a number of bytes. If no size limit is set,
+// then '0' is returned.
+func (l *PackFilePartitionLimit) Bytes() (int64, error) {
+	if l.Size == "" {
+		return 0, nil
+	}
+
+	m := sizePattern.FindStringSubmatch(l.Size)
+	if m == nil {
+		return 0, fmt.Errorf("%w: invalid size limit: %s", ErrInvalidInput, l.Size)
+	}
+
+	unit, ok := sizeUnits[strings.ToLower(m[2])]
+	if !ok {
+		return 0, fmt.Errorf("%w: unsupported size unit: %s", ErrInvalidInput, m[2])
+	}
+
+	value, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid size limit: %s: %w", ErrInvalidInput, l.Size, err)
+	}
+
+	size := int64(value * unit)
+	if size <= 0 {
+		return 0, fmt.Errorf("%w: size limit must be positive: %s", ErrInvalidInput, l.Size)
+	}
+
+	return size, nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                         Function: PartitionPackFiles                       */
+/* -------------------------------------------------------------------------- */
+
+// PartitionPackFiles expands each pack file with partitioning rules into one
+// pack file per partition. Each resulting pack file is named after the
+// original pack file with the partition's index appended (e.g. 'game.1.pck'
+// becomes 'game.1.0.pck', 'game.1.1.pck', etc.).
+//
+// NOTE: Because default pack file names are derived from a pack file's index,
+// every non-embedded pack file is assigned an explicit name prior to expansion
+// so that the names of other pack files aren't affected.
+func PartitionPackFiles(rc *run.Context, packs []PackFile) ([]PackFile, error) {
+	if !slices.ContainsFunc(packs, func(pf PackFile) bool { return pf.Partition.IsEnabled() }) {
+		return packs, nil
+	}
+
+	root, err := filepath.Abs(rc.PathWorkspace.String())
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]PackFile, 0, len(packs))
+	names := make(map[string]struct{}, len(packs))
+
+	for i, pf := range packs {
+		if config.Dereference(pf.Embed) {
+			out = append(out, pf)
+
+			continue
+		}
+
+		name := pf.Filename(rc.Platform, rc.Target, i)
+		pf.Name = osutil.Path(name)
+
+		if !pf.Partition.IsEnabled() {
+			out = append(out, pf)
+
+			continue
+		}
+
+		ff, err := pf.Files(rc.PathWorkspace)
+		if err != nil {
+			return nil, err
+		}
+
+		partitions, err := pf.Partition.Partition(root, ff)
+		if err != nil {
+			return nil, err
+		}
+
+		ext := pf.Extension(rc.Platform)
+
+		for j, files := range partitions {
+			p := pf
+
+			p.Name = osutil.Path(strings.TrimSuffix(name, ext) + "." + strconv.Itoa(j) + ext)
+			p.Partition = PackFilePartition{} //nolint:exhaustruct
+			p.files = files
+
+			out = append(out, p)
+		}
+	}
+
+	for i, pf := range out {
+		name := pf.Filename(rc.Platform, rc.Target, i)
+		if _, ok := names[name]; ok {
+			return nil, fmt.Errorf(
+				"%w: duplicate pack filename found after partitioning: %s",
+				ErrInvalidInput,
+				name,
+			)
+		}
+
+		names[name] = struct{}{}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,262 @@
+package export_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+func TestPackFilePartitionPartition(t *testing.T) {
+	// Given: A project with files spread across multiple folders.
+	root := t.TempDir()
+
+	files := map[string]int{
+		"levels/forest/a.tscn":     10,
+		"levels/forest/b.tscn":     10,
+		"levels/forest/c.tscn":     10,
+		"levels/desert/a.tscn":     25,
+		"levels/desert/sub/b.tres": 5,
+		"levels/main.tscn":         1,
+		"levels/main.tscn.import":  1,
+	}
+
+	paths := make([]osutil.Path, 0, len(files))
+
+	for name, size := range files {
+		path := filepath.Join(root, name)
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(strings.Repeat("x", size)), 0o600))
+
+		paths = append(paths, osutil.Path(path))
+	}
+
+	tests := []struct {
+		name string
+
+		partition export.PackFilePartition
+
+		want [][]string
+		err  error
+	}{
+		{
+			name: "no rules produces a single partition",
+
+			want: [][]string{{
+				"levels/desert/a.tscn",
+				"levels/desert/sub/b.tres",
+				"levels/forest/a.tscn",
+				"levels/forest/b.tscn",
+				"levels/forest/c.tscn",
+				"levels/main.tscn",
+				"levels/main.tscn.import",
+			}},
+		},
+		{
+			name: "depth splits files by folder",
+
+			partition: export.PackFilePartition{Depth: 2},
+
+			want: [][]string{
+				{"levels/main.tscn", "levels/main.tscn.import"},
+				{"levels/desert/a.tscn", "levels/desert/sub/b.tres"},
+				{"levels/forest/a.tscn", "levels/forest/b.tscn", "levels/forest/c.tscn"},
+			},
+		},
+		{
+			name: "file limit splits folders",
+
+			partition: export.PackFilePartition{
+				Depth: 2,
+				Limit: export.PackFilePartitionLimit{Files: 2},
+			},
+
+			want: [][]string{
+				{"levels/main.tscn", "levels/main.tscn.import"},
+				{"levels/desert/a.tscn", "levels/desert/sub/b.tres"},
+				{"levels/forest/a.tscn", "levels/forest/b.tscn"},
+				{"levels/forest/c.tscn"},
+			},
+		},
+		{
+			name: "size limit splits files and isolates large files",
+
+			partition: export.PackFilePartition{
+				Limit: export.PackFilePartitionLimit{Size: "20b"},
+			},
+
+			want: [][]string{
+				{"levels/desert/a.tscn"},
+				{"levels/desert/sub/b.tres", "levels/forest/a.tscn"},
+				{"levels/forest/b.tscn", "levels/forest/c.tscn"},
+				{"levels/main.tscn", "levels/main.tscn.import"},
+			},
+		},
+		{
+			name: "invalid size limit fails",
+
+			partition: export.PackFilePartition{
+				Limit: export.PackFilePartitionLimit{Size: "20 parsecs"},
+			},
+
+			err: export.ErrInvalidInput,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: The files are partitioned.
+			got, err := tc.partition.Partition(root, paths)
+
+			// Then: The resulting error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+
+			// Then: The partitions match expectations.
+			var rel [][]string
+
+			for _, p := range got {
+				var group []string
+
+				for _, f := range p {
+					r, err := filepath.Rel(root, f.String())
+					require.NoError(t, err)
+
+					group = append(group, filepath.ToSlash(r))
+				}
+
+				rel = append(rel, group)
+			}
+
+			assert.Equal(t, tc.want, rel)
+		})
+	}
+}
+
+func TestPackFilePartitionLimitBytes(t *testing.T) {
+	tests := []struct {
+		size string
+
+		want int64
+		err  error
+	}{
+		{size: "", want: 0},
+		{size: "512", want: 512},
+		{size: "1.5KB", want: 1500},
+		{size: "2 MiB", want: 2 << 20},
+		{size: "1gb", want: 1e9},
+		{size: "0MB", err: export.ErrInvalidInput},
+		{size: "-1MB", err: export.ErrInvalidInput},
+		{size: "1TB", err: export.ErrInvalidInput},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.size, func(t *testing.T) {
+			// When: The size limit is parsed.
+			l := export.PackFilePartitionLimit{Size: tc.size} //nolint:exhaustruct
+			got, err := l.Bytes()
+
+			// Then: The resulting error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+
+			// Then: The number of bytes matches expectations.
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestPartitionPackFiles(t *testing.T) {
+	// Given: A project with a few files.
+	root := t.TempDir()
+
+	for _, name := range []string{"main.tscn", "dlc/a.tscn", "dlc/b.tscn", "extra/c.tscn"} {
+		path := filepath.Join(root, name)
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(name), 0o600))
+	}
+
+	rc := run.Context{Platform: platform.OSLinux, PathWorkspace: osutil.Path(root), Target: "game"} //nolint:exhaustruct
+
+	embed := true
+
+	packs := []export.PackFile{
+		{Embed: &embed, Include: []string{"main.tscn"}},                                                                 //nolint:exhaustruct
+		{Include: []string{"dlc"}, Partition: export.PackFilePartition{Limit: export.PackFilePartitionLimit{Files: 1}}}, //nolint:exhaustruct
+		{Include: []string{"extra"}}, //nolint:exhaustruct
+	}
+
+	// When: The pack files are partitioned.
+	got, err := export.PartitionPackFiles(&rc, packs)
+	require.NoError(t, err)
+
+	// Then: The partitioned pack file is expanded and other names are kept.
+	names := make([]string, 0, len(got))
+	for i, pf := range got {
+		names = append(names, pf.Filename(rc.Platform, rc.Target, i))
+	}
+
+	assert.Equal(t, []string{"game", "game.1.0.pck", "game.1.1.pck", "game.2.pck"}, names)
+
+	// Then: Each partition contains its own files.
+	for i, want := range []string{"dlc/a.tscn", "dlc/b.tscn"} {
+		ff, err := got[i+1].Files(rc.PathWorkspace)
+		require.NoError(t, err)
+
+		assert.Equal(t, []osutil.Path{osutil.Path(filepath.Join(root, want))}, ff)
+	}
+}
+
+func TestPartitionPackFilesChecksum(t *testing.T) {
+	// Given: A project with files in a few folders.
+	root := t.TempDir()
+
+	for _, name := range []string{"x/a.tscn", "y/b.tscn", "y/c.tscn"} {
+		path := filepath.Join(root, name)
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(name), 0o600))
+	}
+
+	rc := run.Context{ //nolint:exhaustruct
+		Platform:      platform.OSLinux,
+		PathManifest:  osutil.Path(filepath.Join(root, "gdbuild.toml")),
+		PathWorkspace: osutil.Path(root),
+		Target:        "game",
+	}
+
+	checksum := func(partition export.PackFilePartition) string {
+		packs, err := export.PartitionPackFiles(&rc, []export.PackFile{
+			{Include: []string{"x", "y"}, Partition: partition}, //nolint:exhaustruct
+		})
+		require.NoError(t, err)
+		require.Len(t, packs, 2)
+
+		cs, err := export.Checksum(&rc, &export.Export{PackFiles: packs}) //nolint:exhaustruct
+		require.NoError(t, err)
+
+		return cs
+	}
+
+	// When: The same files are split into two pack files by folder.
+	byFolder := checksum(export.PackFilePartition{Depth: 1}) //nolint:exhaustruct
+
+	// When: The same files are split into two pack files by file count.
+	byCount := checksum(export.PackFilePartition{Limit: export.PackFilePartitionLimit{Files: 2}}) //nolint:exhaustruct
+
+	// Then: The checksums differ because the files moved between pack files.
+	assert.NotEqual(t, byFolder, byCount)
+}
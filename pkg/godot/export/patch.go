@@ -0,0 +1,726 @@
+package export
+
+import (
+	"context"
+	"crypto/md5" //nolint:gosec
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/archive"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/godot/pck"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+	"github.com/coffeebeats/gdbuild/pkg/store"
+)
+
+const (
+	// fileManifestVersion is the current version of the file manifest format.
+	fileManifestVersion = 1
+
+	// extFileManifest is the file extension of a file manifest.
+	extFileManifest = ".files.json"
+	// extPatchManifest is the file extension of a patch manifest.
+	extPatchManifest = ".patch.json"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                Struct: Patch                               */
+/* -------------------------------------------------------------------------- */
+
+// Patch configures a target to export only the project files which changed
+// since a baseline build (e.g. a previous release).
+type Patch struct {
+	// Baseline is a path to the baseline build. This can be a file manifest
+	// ('*.files.json') written by a previous patch export, an exported pack
+	// file (or executable with an embedded pack), or an archive of exported
+	// artifacts from the 'gdbuild' store.
+	//
+	// NOTE: Only a file manifest records the digests of imported assets' source
+	// files. With any other baseline, an imported asset can only be compared
+	// once it's been imported locally; otherwise, it's included in the patch.
+	Baseline osutil.Path `toml:"baseline"`
+	// BaselineChecksum is the checksum of a previous export cached in the
+	// 'gdbuild' store to use as the baseline.
+	BaselineChecksum string `toml:"baseline_checksum"`
+}
+
+/* --------------------------- Method: IsEnabled ---------------------------- */
+
+// IsEnabled returns whether a patch should be exported.
+func (p *Patch) IsEnabled() bool {
+	return p.Baseline != "" || p.BaselineChecksum != ""
+}
+
+/* ------------------------- Impl: config.Configurer ------------------------ */
+
+func (p *Patch) Configure(rc *run.Context) error {
+	if err := p.Baseline.RelTo(rc.PathManifest); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/* ------------------------- Impl: config.Validator ------------------------- */
+
+func (p *Patch) Validate(_ *run.Context) error {
+	if p.Baseline != "" && p.BaselineChecksum != "" {
+		return fmt.Errorf(
+			"%w: cannot set both 'baseline' and 'baseline_checksum' for a patch",
+			ErrInvalidInput,
+		)
+	}
+
+	if err := p.Baseline.CheckIsFileOrEmpty(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/* ----------------------------- Method: Diff ------------------------------- */
+
+// Diff compares the project files 'files' against the baseline build and
+// returns the project files which were added, changed, or deleted since then.
+func (p *Patch) Diff(rc *run.Context, files []osutil.Path, key []byte) (PatchDiff, error) {
+	current, err := NewFileManifest(rc, files)
+	if err != nil {
+		return PatchDiff{}, err
+	}
+
+	path := p.Baseline.String()
+
+	if p.BaselineChecksum != "" {
+		storePath, err := store.Path()
+		if err != nil {
+			return PatchDiff{}, err
+		}
+
+		path, err = store.TargetArchive(storePath, p.BaselineChecksum)
+		if err != nil {
+			return PatchDiff{}, err
+		}
+	}
+
+	b, err := readBaseline(rc, path, key)
+	if err != nil {
+		return PatchDiff{}, fmt.Errorf("cannot read patch baseline: %s: %w", path, err)
+	}
+
+	diff := b.diff(rc, current)
+	diff.Manifest = current
+
+	return diff, nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                              Struct: PatchDiff                             */
+/* -------------------------------------------------------------------------- */
+
+// PatchDiff describes the changes to a project's files since a baseline build.
+// All paths are resource paths (e.g. 'res://icon.png').
+type PatchDiff struct {
+	// Baseline is a digest of the baseline build's file list.
+	Baseline string `json:"baseline"`
+	// Added are project files which aren't in the baseline build.
+	Added []string `json:"added"`
+	// Changed are project files whose contents (or import settings) differ
+	// from the baseline build.
+	Changed []string `json:"changed"`
+	// Deleted are project files in the baseline build which no longer exist.
+	Deleted []string `json:"deleted"`
+
+	// Manifest is the file manifest of the current project files.
+	Manifest FileManifest `json:"-"`
+}
+
+/* ------------------------- Method: IncludesFile -------------------------- */
+
+// IncludesFile returns whether the resource path 'res' (or the source file of
+// an '.import' file) should be included in the patch. The 'Added' and 'Changed'
+// lists must be sorted.
+func (d *PatchDiff) IncludesFile(res string) bool {
+	res = strings.TrimSuffix(res, ".import")
+
+	_, isAdded := slices.BinarySearch(d.Added, res)
+	_, isChanged := slices.BinarySearch(d.Changed, res)
+
+	return isAdded || isChanged
+}
+
+/* ----------------------- Method: ManifestFilenames ------------------------ */
+
+// ManifestFilenames returns the names of the file manifest and the patch
+// manifest written for the target 'name'.
+func (d *PatchDiff) ManifestFilenames(name string) []string {
+	return []string{name + extFileManifest, name + extPatchManifest}
+}
+
+/* -------------------------------------------------------------------------- */
+/*                            Struct: FileManifest                            */
+/* -------------------------------------------------------------------------- */
+
+// FileManifest records the SHA-256 digests of the project files included in an
+// export. It's written alongside a patch export so that it can be used as the
+// baseline for the next patch.
+type FileManifest struct {
+	// Version is the version of the file manifest format.
+	Version int `json:"version"`
+	// Files maps the resource path of each project file (including '.import'
+	// files) to the hex-encoded SHA-256 digest of its contents.
+	Files map[string]string `json:"files"`
+}
+
+/* ------------------------ Function: NewFileManifest ----------------------- */
+
+// NewFileManifest computes a 'FileManifest' for the project files 'files'.
+func NewFileManifest(rc *run.Context, files []osutil.Path) (FileManifest, error) {
+	root, err := filepath.Abs(rc.PathWorkspace.String())
+	if err != nil {
+		return FileManifest{}, err
+	}
+
+	out := FileManifest{Version: fileManifestVersion, Files: make(map[string]string, len(files))}
+
+	for _, f := range files {
+		res, err := resourcePath(root, f)
+		if err != nil {
+			return FileManifest{}, err
+		}
+
+		digest, err := digestFile(sha256.New(), f.String())
+		if err != nil {
+			return FileManifest{}, err
+		}
+
+		out.Files[res] = digest
+	}
+
+	return out, nil
+}
+
+/* ------------------------ Function: ReadFileManifest ---------------------- */
+
+// ReadFileManifest parses the file manifest at 'path'.
+func ReadFileManifest(path string) (FileManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FileManifest{}, err
+	}
+
+	var m FileManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return FileManifest{}, fmt.Errorf("%w: invalid file manifest: %w", ErrInvalidInput, err)
+	}
+
+	if m.Version != fileManifestVersion {
+		return FileManifest{}, fmt.Errorf(
+			"%w: unsupported file manifest version: %d",
+			ErrInvalidInput,
+			m.Version,
+		)
+	}
+
+	return m, nil
+}
+
+/* ---------------------------- Method: Digest ------------------------------ */
+
+// Digest returns a digest of the entire file manifest.
+func (m *FileManifest) Digest() string {
+	paths := make([]string, 0, len(m.Files))
+	for path := range m.Files {
+		paths = append(paths, path)
+	}
+
+	slices.Sort(paths)
+
+	h := sha256.New()
+
+	for _, path := range paths {
+		fmt.Fprintf(h, "%s\x00%s\n", path, m.Files[path])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+/* -------------------------------------------------------------------------- */
+/*                     Function: NewWritePatchManifestsAction                 */
+/* -------------------------------------------------------------------------- */
+
+// NewWritePatchManifestsAction creates an 'action.Action' which writes the
+// file manifest of the current project files (for use as the next baseline)
+// and the patch manifest listing the added, changed, and deleted files.
+func NewWritePatchManifestsAction(rc *run.Context, diff *PatchDiff) action.WithDescription[action.Function] {
+	names := diff.ManifestFilenames(rc.Target)
+	nameFiles, namePatch := names[0], names[1]
+
+	fn := func(_ context.Context) error {
+		for name, v := range map[string]any{nameFiles: diff.Manifest, namePatch: diff} {
+			data, err := json.MarshalIndent(v, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			path := rc.PathOut.Join(name).String()
+			if err := os.WriteFile(path, append(data, '\n'), osutil.ModeUserRW); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return action.WithDescription[action.Function]{
+		Action: fn,
+		Description: fmt.Sprintf(
+			"write patch manifests (baseline: %s; %d added, %d changed, %d deleted): %s",
+			diff.Baseline,
+			len(diff.Added),
+			len(diff.Changed),
+			len(diff.Deleted),
+			strings.Join(names, ","),
+		),
+	}
+}
+
+/* -------------------------------------------------------------------------- */
+/*                              Struct: baseline                              */
+/* -------------------------------------------------------------------------- */
+
+// baseline contains the contents of a baseline build. Exactly one of the
+// fields will be set.
+type baseline struct {
+	// manifest is the file manifest of the baseline build. This is the most
+	// precise baseline because it records the digests of project files.
+	manifest *FileManifest
+	// files are the entries of the baseline build's pack files. Only exported
+	// file contents are recorded, so files which Godot converts during export
+	// (i.e. those with a '.remap' file) are always considered changed.
+	files map[string]pck.File
+}
+
+/* ------------------------- Function: readBaseline ------------------------- */
+
+func readBaseline(rc *run.Context, path string, key []byte) (baseline, error) {
+	switch {
+	case strings.HasSuffix(path, ".json"):
+		m, err := ReadFileManifest(path)
+		if err != nil {
+			return baseline{}, err
+		}
+
+		return baseline{manifest: &m, files: nil}, nil
+
+	case strings.HasSuffix(path, archive.FileExtension):
+		return readBaselineArchive(rc, path, key)
+
+	default:
+		p, err := pck.OpenWithKey(path, key)
+		if err != nil {
+			return baseline{}, err
+		}
+
+		if p.IsDirectoryEncrypted() && len(key) == 0 {
+			return baseline{}, fmt.Errorf("%w: baseline pack file is encrypted", pck.ErrMissingKey)
+		}
+
+		b := baseline{manifest: nil, files: make(map[string]pck.File, len(p.Files))}
+		b.addPack(p)
+
+		return b, nil
+	}
+}
+
+/* --------------------- Function: readBaselineArchive ---------------------- */
+
+// readBaselineArchive extracts a cached export from the 'gdbuild' store and
+// reads its file manifest, if present, or otherwise its pack files.
+func readBaselineArchive(rc *run.Context, path string, key []byte) (baseline, error) {
+	pathTmp, err := rc.TempDir()
+	if err != nil {
+		return baseline{}, err
+	}
+
+	out, err := os.MkdirTemp(pathTmp, "baseline-*")
+	if err != nil {
+		return baseline{}, err
+	}
+
+	if err := archive.Extract(context.Background(), path, out); err != nil {
+		return baseline{}, err
+	}
+
+	var manifests, packs []string
+
+	if err := filepath.WalkDir(out, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		if strings.HasSuffix(path, extFileManifest) {
+			manifests = append(manifests, path)
+		} else {
+			packs = append(packs, path)
+		}
+
+		return nil
+	}); err != nil {
+		return baseline{}, err
+	}
+
+	if len(manifests) > 1 {
+		return baseline{}, fmt.Errorf("%w: found multiple file manifests in baseline", ErrInvalidInput)
+	}
+
+	if len(manifests) == 1 {
+		return readBaseline(rc, manifests[0], key)
+	}
+
+	b := baseline{manifest: nil, files: make(map[string]pck.File)}
+
+	for _, path := range packs {
+		p, err := pck.OpenWithKey(path, key)
+		if err != nil {
+			if errors.Is(err, pck.ErrInvalidFormat) {
+				continue // Not a pack file.
+			}
+
+			return baseline{}, err
+		}
+
+		b.addPack(p)
+	}
+
+	if len(b.files) == 0 {
+		return baseline{}, fmt.Errorf("%w: no pack files found in baseline", ErrMissingInput)
+	}
+
+	return b, nil
+}
+
+/* ---------------------------- Method: addPack ----------------------------- */
+
+func (b *baseline) addPack(p *pck.Pack) {
+	for _, f := range p.Files {
+		if !f.IsRemoval() {
+			b.files[f.Path] = f
+		}
+	}
+}
+
+/* ----------------------------- Method: digest ----------------------------- */
+
+// digest returns a digest of the baseline's file list.
+func (b *baseline) digest() string {
+	if b.manifest != nil {
+		return b.manifest.Digest()
+	}
+
+	m := FileManifest{Version: fileManifestVersion, Files: make(map[string]string, len(b.files))}
+	for path, f := range b.files {
+		m.Files[path] = f.MD5
+	}
+
+	return m.Digest()
+}
+
+/* ---------------------------- Method: sources ----------------------------- */
+
+// sources returns the set of project files in the baseline build, excluding
+// '.import' files and files generated by Godot.
+func (b *baseline) sources() map[string]struct{} {
+	out := make(map[string]struct{})
+
+	if b.manifest != nil {
+		for path := range b.manifest.Files {
+			src, ok := strings.CutSuffix(path, ".import")
+			if _, hasSource := b.manifest.Files[src]; ok && hasSource {
+				continue
+			}
+
+			out[path] = struct{}{}
+		}
+
+		return out
+	}
+
+	for path := range b.files {
+		if isGeneratedFile(path) || strings.HasPrefix(path, resourcePathPrefix+".godot/") {
+			continue
+		}
+
+		path = strings.TrimSuffix(path, ".import")
+		path = strings.TrimSuffix(path, ".remap")
+
+		// Compiled scripts are remapped from their source file.
+		if src, ok := strings.CutSuffix(path, ".gdc"); ok {
+			path = src + ".gd"
+		}
+
+		out[path] = struct{}{}
+	}
+
+	return out
+}
+
+/* ------------------------------ Method: diff ------------------------------ */
+
+func (b *baseline) diff(rc *run.Context, current FileManifest) PatchDiff {
+	diff := PatchDiff{Baseline: b.digest()} //nolint:exhaustruct
+
+	sources := b.sources()
+	digests := sourceDigests{}
+
+	var uncompared []string
+
+	for path := range current.Files {
+		if src, ok := strings.CutSuffix(path, ".import"); ok {
+			if _, hasSource := current.Files[src]; hasSource {
+				continue
+			}
+		}
+
+		if _, ok := sources[path]; !ok {
+			diff.Added = append(diff.Added, path)
+
+			continue
+		}
+
+		changed, compared := b.hasChanged(rc, current, path, digests)
+		if !compared {
+			uncompared = append(uncompared, path)
+		}
+
+		if changed {
+			diff.Changed = append(diff.Changed, path)
+		}
+	}
+
+	if len(uncompared) > 0 {
+		slices.Sort(uncompared)
+
+		log.Warnf(
+			"cannot compare %d imported asset(s) with the baseline because they "+
+				"haven't been imported locally; including them in the patch "+
+				"(use a file manifest ('*%s') baseline to compare source files): %s",
+			len(uncompared),
+			extFileManifest,
+			summarizeFiles(uncompared),
+		)
+	}
+
+	for path := range sources {
+		if _, ok := current.Files[path]; !ok {
+			diff.Deleted = append(diff.Deleted, path)
+		}
+	}
+
+	slices.Sort(diff.Added)
+	slices.Sort(diff.Changed)
+	slices.Sort(diff.Deleted)
+
+	return diff
+}
+
+/* --------------------------- Method: hasChanged --------------------------- */
+
+// hasChanged returns whether the project file 'path' (or its '.import' file)
+// differs from the baseline build. The second return value reports whether the
+// file could actually be compared; if not, the file is considered changed.
+//
+// NOTE: Pack files only contain an imported asset's imported resources, which
+// are compared with the project's local imported resources. These are only
+// compared once the local import record of the asset matches its current
+// source file (see 'isAssetImported'), since they're otherwise missing or
+// stale (e.g. prior to the export's import).
+func (b *baseline) hasChanged(
+	rc *run.Context,
+	current FileManifest,
+	path string,
+	digests sourceDigests,
+) (bool, bool) {
+	if b.manifest != nil {
+		for _, p := range []string{path, path + ".import"} {
+			if b.manifest.Files[p] != current.Files[p] {
+				return true, true
+			}
+		}
+
+		return false, true
+	}
+
+	// Without a file manifest, compare the exported contents using the MD5
+	// digests recorded in the pack directory.
+	local := rc.PathWorkspace.String()
+
+	if _, ok := current.Files[path+".import"]; ok {
+		if b.hasChangedFile(path+".import", filepath.Join(local, toLocalPath(path+".import"))) {
+			return true, true
+		}
+
+		isImported, err := isAssetImported(rc.PathWorkspace, toLocalPath(path), digests)
+		if err != nil || !isImported {
+			return true, false
+		}
+
+		dest, _, err := readImportDestinations(filepath.Join(local, toLocalPath(path+".import")))
+		if err != nil || len(dest) == 0 {
+			return true, true
+		}
+
+		for _, d := range dest {
+			if _, ok := b.files[d]; !ok {
+				continue // Only one variant of an imported resource is exported.
+			}
+
+			if b.hasChangedFile(d, filepath.Join(local, toLocalPath(d))) {
+				return true, true
+			}
+		}
+
+		return false, true
+	}
+
+	return b.hasChangedFile(path, filepath.Join(local, toLocalPath(path))), true
+}
+
+/* ------------------------- Method: hasChangedFile ------------------------- */
+
+func (b *baseline) hasChangedFile(res, path string) bool {
+	f, ok := b.files[res]
+	if !ok {
+		return true
+	}
+
+	digest, err := digestFile(md5.New(), path) //nolint:gosec
+	if err != nil {
+		log.Debugf("cannot compare file with baseline: %s: %s", path, err)
+
+		return true
+	}
+
+	return digest != f.MD5
+}
+
+/* -------------------------------------------------------------------------- */
+/*                            Function: PatchPackFiles                        */
+/* -------------------------------------------------------------------------- */
+
+// PatchPackFiles restricts the files in each pack file to only those included
+// in the patch. The returned 'PatchDiff' describes the changes across all of
+// the pack files.
+func PatchPackFiles(
+	rc *run.Context,
+	patch *Patch,
+	packs []PackFile,
+	key []byte,
+) ([]PackFile, PatchDiff, error) {
+	root, err := filepath.Abs(rc.PathWorkspace.String())
+	if err != nil {
+		return nil, PatchDiff{}, err
+	}
+
+	var all []osutil.Path
+
+	for _, pf := range packs {
+		ff, err := pf.Files(rc.PathWorkspace)
+		if err != nil {
+			return nil, PatchDiff{}, err
+		}
+
+		all = append(all, ff...)
+	}
+
+	slices.Sort(all)
+	all = slices.Compact(all)
+
+	diff, err := patch.Diff(rc, all, key)
+	if err != nil {
+		return nil, PatchDiff{}, err
+	}
+
+	out := make([]PackFile, 0, len(packs))
+
+	for i, pf := range packs {
+		ff, err := pf.Files(rc.PathWorkspace)
+		if err != nil {
+			return nil, PatchDiff{}, err
+		}
+
+		files := make([]osutil.Path, 0, len(ff))
+
+		for _, f := range ff {
+			res, err := resourcePath(root, f)
+			if err != nil {
+				return nil, PatchDiff{}, err
+			}
+
+			if diff.IncludesFile(res) {
+				files = append(files, f)
+			}
+		}
+
+		if len(files) == 0 {
+			log.Warnf("no files changed in pack file: %s", pf.Filename(rc.Platform, rc.Target, i))
+		}
+
+		slices.Sort(files)
+
+		pf.files = files
+
+		out = append(out, pf)
+	}
+
+	return out, diff, nil
+}
+
+/* -------------------------- Function: resourcePath ------------------------ */
+
+// resourcePath converts the path to a project file into a resource path (e.g.
+// 'res://icon.png'), where 'root' is the absolute path to the project.
+func resourcePath(root string, path osutil.Path) (string, error) {
+	rel, err := filepath.Rel(root, path.String())
+	if err != nil {
+		return "", err
+	}
+
+	return resourcePathPrefix + filepath.ToSlash(rel), nil
+}
+
+/* -------------------------- Function: toLocalPath ------------------------- */
+
+// toLocalPath converts a resource path into a path relative to the project.
+func toLocalPath(res string) string {
+	return filepath.FromSlash(strings.TrimPrefix(res, resourcePathPrefix))
+}
+
+/* --------------------------- Function: digestFile ------------------------- */
+
+func digestFile(h hash.Hash, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,292 @@
+package export_test
+
+import (
+	"bytes"
+	"crypto/md5" //nolint:gosec
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+func TestPatchPackFiles(t *testing.T) {
+	// Given: A project directory.
+	root := t.TempDir()
+
+	write := func(name, contents string) {
+		path := filepath.Join(root, name)
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	}
+
+	rc := run.Context{Platform: platform.OSLinux, PathWorkspace: osutil.Path(root), Target: "game"} //nolint:exhaustruct
+
+	packs := []export.PackFile{{Include: []string{"game"}}} //nolint:exhaustruct
+
+	// Given: A baseline file manifest recorded from the original project.
+	write("game/icon.png", "image")
+	write("game/icon.png.import", "[remap]\n")
+	write("game/main.gd", "extends Node")
+	write("game/old.gd", "extends Node")
+	write("game/same.tscn", "[gd_scene]")
+
+	ff, err := packs[0].Files(rc.PathWorkspace)
+	require.NoError(t, err)
+
+	m, err := export.NewFileManifest(&rc, ff)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+
+	pathBaseline := filepath.Join(t.TempDir(), "game.files.json")
+	require.NoError(t, os.WriteFile(pathBaseline, data, 0o600))
+
+	// Given: The project is updated.
+	write("game/icon.png.import", "[remap]\ncompress/mode=2\n")
+	write("game/main.gd", "extends Node2D")
+	write("game/new.gd", "extends Node")
+	require.NoError(t, os.Remove(filepath.Join(root, "game/old.gd")))
+
+	patch := export.Patch{Baseline: osutil.Path(pathBaseline)} //nolint:exhaustruct
+	require.NoError(t, patch.Validate(&rc))
+
+	// When: The pack files are restricted to the patch.
+	got, diff, err := export.PatchPackFiles(&rc, &patch, packs, nil)
+	require.NoError(t, err)
+
+	// Then: The changes since the baseline are detected.
+	assert.Equal(t, []string{"res://game/new.gd"}, diff.Added)
+	assert.Equal(t, []string{"res://game/icon.png", "res://game/main.gd"}, diff.Changed)
+	assert.Equal(t, []string{"res://game/old.gd"}, diff.Deleted)
+	assert.Equal(t, m.Digest(), diff.Baseline)
+
+	// Then: The pack file only contains the changed files.
+	ff, err = got[0].Files(rc.PathWorkspace)
+	require.NoError(t, err)
+
+	want := []osutil.Path{}
+	for _, name := range []string{"icon.png", "icon.png.import", "main.gd", "new.gd"} {
+		want = append(want, osutil.Path(filepath.Join(root, "game", name)))
+	}
+
+	assert.Equal(t, want, ff)
+}
+
+func TestPatchPackFilesWithPackBaseline(t *testing.T) {
+	const importFile = "[remap]\n\nimporter=\"texture\"\n\n[deps]\n\n" +
+		"source_file=\"res://icon.png\"\ndest_files=[\"res://.godot/imported/icon.ctex\"]\n"
+
+	// Given: A baseline pack file containing an imported asset and a script.
+	pathBaseline := filepath.Join(t.TempDir(), "game.pck")
+	writePack(t, pathBaseline, map[string]string{
+		"res://.godot/imported/icon.ctex": "texture",
+		"res://icon.png.import":           importFile,
+		"res://main.gd":                   "extends Node",
+	})
+
+	tests := []struct {
+		name string
+
+		source   string // Contents of the asset's source file.
+		imported string // Contents of the locally imported resource, if any.
+		recorded string // Source file contents recorded upon the local import.
+
+		want []string
+	}{
+		{
+			name:   "asset which hasn't been imported is changed",
+			source: "image",
+			want:   []string{"res://icon.png"},
+		},
+		{
+			name:     "asset whose import matches the baseline is unchanged",
+			source:   "image",
+			imported: "texture",
+			recorded: "image",
+			want:     nil,
+		},
+		{
+			name:     "asset whose import differs from the baseline is changed",
+			source:   "new image",
+			imported: "new texture",
+			recorded: "new image",
+			want:     []string{"res://icon.png"},
+		},
+		{
+			name:     "changed asset with a stale import matching the baseline is changed",
+			source:   "new image",
+			imported: "texture",
+			recorded: "image",
+			want:     []string{"res://icon.png"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A project directory.
+			root := t.TempDir()
+
+			write := func(name, contents string) {
+				path := filepath.Join(root, name)
+
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+				require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+			}
+
+			write("icon.png", tc.source)
+			write("icon.png.import", importFile)
+			write("main.gd", "extends Node")
+
+			// Given: The asset's local import, if any.
+			if tc.imported != "" {
+				write(".godot/imported/icon.ctex", tc.imported)
+				write(".godot/imported/icon.png-"+digest("res://icon.png")+".md5", record(tc.recorded))
+			}
+
+			rc := run.Context{Platform: platform.OSLinux, PathWorkspace: osutil.Path(root), Target: "game"} //nolint:exhaustruct
+
+			packs := []export.PackFile{{Include: []string{"icon.png", "icon.png.import", "main.gd"}}} //nolint:exhaustruct
+			patch := export.Patch{Baseline: osutil.Path(pathBaseline)}                                //nolint:exhaustruct
+
+			// When: The pack files are restricted to the patch.
+			_, diff, err := export.PatchPackFiles(&rc, &patch, packs, nil)
+			require.NoError(t, err)
+
+			// Then: Only the expected files are changed.
+			assert.Empty(t, diff.Added)
+			assert.Equal(t, tc.want, diff.Changed)
+			assert.Empty(t, diff.Deleted)
+		})
+	}
+}
+
+func TestPatchConfigure(t *testing.T) {
+	// Given: A manifest in a different directory than the workspace.
+	pathManifest := filepath.Join(t.TempDir(), "gdbuild.toml")
+	require.NoError(t, os.WriteFile(pathManifest, nil, 0o600))
+
+	rc := run.Context{ //nolint:exhaustruct
+		PathManifest:  osutil.Path(pathManifest),
+		PathWorkspace: osutil.Path(t.TempDir()),
+	}
+
+	patch := export.Patch{Baseline: "baseline/game.files.json"} //nolint:exhaustruct
+
+	// When: The patch configuration is configured.
+	err := patch.Configure(&rc)
+
+	// Then: There's no error.
+	require.NoError(t, err)
+
+	// Then: The baseline path is relative to the manifest.
+	assert.Equal(t, osutil.Path(filepath.Join(filepath.Dir(pathManifest), "baseline", "game.files.json")), patch.Baseline)
+}
+
+func TestPatchValidate(t *testing.T) {
+	pathBaseline := filepath.Join(t.TempDir(), "game.pck")
+	require.NoError(t, os.WriteFile(pathBaseline, nil, 0o600))
+
+	tests := []struct {
+		name  string
+		patch export.Patch
+		err   error
+	}{
+		{
+			name:  "disabled patch is valid",
+			patch: export.Patch{},
+		},
+		{
+			name:  "baseline path is valid",
+			patch: export.Patch{Baseline: osutil.Path(pathBaseline)}, //nolint:exhaustruct
+		},
+		{
+			name:  "baseline checksum is valid",
+			patch: export.Patch{BaselineChecksum: "abc"}, //nolint:exhaustruct
+		},
+		{
+			name:  "both baselines are invalid",
+			patch: export.Patch{Baseline: osutil.Path(pathBaseline), BaselineChecksum: "abc"},
+			err:   export.ErrInvalidInput,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: The patch configuration is validated.
+			err := tc.patch.Validate(&run.Context{}) //nolint:exhaustruct
+
+			// Then: The resulting error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+// writePack writes a version 1 pack file containing 'files', which maps each
+// resource path to its contents, to 'path'.
+func writePack(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+
+	le := binary.LittleEndian
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	padded := make([][]byte, len(names))
+
+	// NOTE: The header is followed by the file count and then the directory,
+	// after which the file contents begin.
+	offset := 4*4 + 16*4 + 4
+
+	for i, name := range names {
+		padded[i] = []byte(name)
+		for len(padded[i])%4 != 0 {
+			padded[i] = append(padded[i], 0)
+		}
+
+		offset += 4 + len(padded[i]) + 8 + 8 + md5.Size
+	}
+
+	var data bytes.Buffer
+
+	_ = binary.Write(&data, le, uint32(0x43504447))
+	_ = binary.Write(&data, le, [4]uint32{1, 3, 5, 0})
+	data.Write(make([]byte, 16*4))
+	_ = binary.Write(&data, le, uint32(len(names))) //nolint:gosec
+
+	for i, name := range names {
+		sum := md5.Sum([]byte(files[name])) //nolint:gosec
+
+		_ = binary.Write(&data, le, uint32(len(padded[i]))) //nolint:gosec
+		data.Write(padded[i])
+		_ = binary.Write(&data, le, uint64(offset)) //nolint:gosec
+		_ = binary.Write(&data, le, uint64(len(files[name])))
+		data.Write(sum[:])
+
+		offset += len(files[name])
+	}
+
+	for _, name := range names {
+		data.WriteString(files[name])
+	}
+
+	require.NoError(t, os.WriteFile(path, data.Bytes(), 0o600))
+}
@@ -2,9 +2,10 @@ package export
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"maps"
 	"os"
 	"path/filepath"
@@ -18,12 +19,24 @@ import (
 	"github.com/coffeebeats/gdbuild/internal/action"
 	"github.com/coffeebeats/gdbuild/internal/osutil"
 	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/godot/project"
 	"github.com/coffeebeats/gdbuild/pkg/run"
 
 	ini "gopkg.in/ini.v1"
 )
 
-const resourcePathPrefix = "res://"
+const (
+	// FilenameExportPresets is the name of the Godot project's export presets
+	// file.
+	FilenameExportPresets = "export_presets.cfg"
+
+	// PresetNamePrefix is a reserved prefix for the names of presets generated
+	// by 'gdbuild'. Presets with this prefix are replaced when the export
+	// presets file is written; all others are preserved.
+	PresetNamePrefix = "gdbuild:"
+
+	resourcePathPrefix = "res://"
+)
 
 /* -------------------------------------------------------------------------- */
 /*                                 Enum: Mode                                 */
@@ -54,27 +67,29 @@ const (
 
 // Preset defines the parameters used in a Godot export preset.
 type Preset struct {
-	Arch            platform.Arch     `ini:"-"`
-	CustomizedFiles map[string]string `ini:"-"`
-	Embed           bool              `ini:"-"`
-	Encrypt         bool              `ini:"encrypt_pck"`
-	EncryptIndex    bool              `ini:"encrypt_directory"`
-	Encrypted       []string          `ini:"encryption_include_filters"`
-	EncryptionKey   string            `ini:"-"`
-	Exclude         string            `ini:"exclude_filter"`
-	ExportedFiles   []string          `ini:"export_files"`
+	Arch            platform.Arch
+	CustomizedFiles map[string]string
+	Embed           bool
+	Encrypt         bool
+	EncryptIndex    bool
+	Encrypted       []string
+	EncryptionKey   string
+	Exclude         string
+	ExportedFiles   []string
 	// ExportMode sets the type of export to use. Should be 'resources' for a
 	// standard pack file and 'customized' for dedicated server pack files.
-	ExportMode   Mode        `ini:"export_filter"`
-	Features     []string    `ini:"custom_features"`
-	Include      string      `ini:"include_filter"`
-	Name         string      `ini:"name"`
-	PathTemplate osutil.Path `ini:"-"`
-	Platform     platform.OS `ini:"-"`
-	Runnable     bool        `ini:"runnable"`
-	Server       bool        `ini:"dedicated_server"`
-
-	Options map[string]string `ini:"-"`
+	ExportMode   Mode
+	Features     []string
+	Include      string
+	Name         string
+	PathTemplate osutil.Path
+	Platform     platform.OS
+	Runnable     bool
+	Server       bool
+
+	// Options are the preset's 'options' table. See 'EncodeOption' for the
+	// supported value types.
+	Options map[string]any
 }
 
 /* ----------------------------- Method: AddFile ---------------------------- */
@@ -136,14 +151,28 @@ func (p *Preset) AddServerFile(rc *run.Context, path osutil.Path, visuals FileVi
 	return nil
 }
 
+/* --------------------------- Method: ExportName --------------------------- */
+
+// ExportName returns the name of the preset within 'export_presets.cfg', which
+// is the artifact name with the reserved 'gdbuild' prefix.
+func (p *Preset) ExportName() string {
+	return PresetNamePrefix + p.Name
+}
+
 /* ------------------------- Method: exportPlatform ------------------------- */
 
 func (p *Preset) exportPlatform() string {
 	switch pl := p.Platform; pl {
+	case platform.OSAndroid:
+		return "Android"
+	case platform.OSIOS:
+		return "iOS"
 	case platform.OSLinux:
 		return "Linux/X11"
 	case platform.OSMacOS:
 		return "macOS"
+	case platform.OSWeb:
+		return "Web"
 	case platform.OSWindows:
 		return "Windows Desktop"
 	default:
@@ -159,65 +188,81 @@ func (p *Preset) Marshal(w io.Writer, index int) error { //nolint:funlen
 		return nil
 	}
 
-	preset := p
-
-	lo := ini.LoadOptions{PreserveSurroundedQuote: true} //nolint:exhaustruct
+	// NOTE: Values are written as-is; Godot doesn't support inline comments,
+	// so don't quote values containing comment characters.
+	lo := ini.LoadOptions{IgnoreInlineComment: true} //nolint:exhaustruct
 
 	cfg := ini.Empty(lo)
-	cfg.ValueMapper = valueMapper
 
 	presetName := "preset." + strconv.Itoa(index)
-	section := cfg.Section(presetName)
 
-	section.Key("platform").SetValue(valueMapper(p.exportPlatform()))
-
-	// Initially hydrate the 'ini' file.
-	if err := section.ReflectFrom(preset); err != nil {
-		return err
+	type property struct {
+		key   string
+		value any
 	}
 
-	// Unmarshal the file back into the struct to trigger the value mapper.
-	if err := section.MapTo(preset); err != nil {
-		return err
+	properties := []property{
+		{"platform", p.exportPlatform()},
+		{"encrypt_pck", p.Encrypt},
+		{"encrypt_directory", p.EncryptIndex},
+		{"encryption_include_filters", strings.Join(p.Encrypted, ",")},
+		{"exclude_filter", p.Exclude},
+		{"export_files", p.ExportedFiles},
+		{"export_filter", string(p.ExportMode)},
+		{"custom_features", strings.Join(p.Features, ",")},
+		{"include_filter", p.Include},
+		{"name", p.ExportName()},
+		{"runnable", p.Runnable},
+		{"dedicated_server", p.Server},
 	}
 
-	// Finally, re-populate the file with the updated values.
-	if err := section.ReflectFrom(preset); err != nil {
-		return err
+	if len(p.CustomizedFiles) > 0 {
+		files := make(map[string]any, len(p.CustomizedFiles))
+		for path, mode := range p.CustomizedFiles {
+			files[path] = mode
+		}
+
+		properties = append(properties, property{"customized_files", files})
 	}
 
-	if len(preset.CustomizedFiles) > 0 {
-		customizedFiles, err := json.Marshal(preset.CustomizedFiles)
-		if err != nil {
+	section := cfg.Section(presetName)
+
+	for _, property := range properties {
+		if err := setValue(section, property.key, property.value); err != nil {
 			return err
 		}
-
-		section.Key("customized_files").SetValue(string(customizedFiles))
 	}
 
-	section = cfg.Section(presetName + ".options")
-
-	options := maps.Clone(preset.Options)
+	options := maps.Clone(p.Options)
 	if options == nil {
-		options = map[string]string{}
+		options = map[string]any{}
 	}
 
 	if p.Embed {
-		options["binary_format/embed_pck"] = strconv.FormatBool(preset.Embed)
+		options[optionEmbedPCK] = p.Embed
 	}
 
-	options["binary_format/architecture"] = preset.Arch.String()
-	options["custom_template/debug"] = preset.PathTemplate.String()
-	options["custom_template/release"] = preset.PathTemplate.String()
+	options[optionArchitecture] = p.Arch.String()
+	options[optionTemplateDebug] = p.PathTemplate.String()
+	options[optionTemplateRelease] = p.PathTemplate.String()
 
-	for key, value := range options {
-		if value == "" {
-			delete(options, key)
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	section = cfg.Section(presetName + ".options")
 
+	for _, key := range keys {
+		if s, ok := options[key].(string); ok && s == "" {
 			continue
 		}
 
-		section.Key(key).SetValue(valueMapper(value))
+		if err := setValue(section, key, options[key]); err != nil {
+			return fmt.Errorf("%w: option '%s': %w", ErrInvalidInput, key, err)
+		}
 	}
 
 	if _, err := cfg.WriteTo(w); err != nil {
@@ -227,40 +272,199 @@ func (p *Preset) Marshal(w io.Writer, index int) error { //nolint:funlen
 	return nil
 }
 
-/* -------------------------- Function: valueMapper ------------------------- */
+/* --------------------------- Function: setValue --------------------------- */
+
+// setValue encodes 'value' (see 'EncodeOption') and sets it as the value of the
+// property 'key' within 'section'.
+func setValue(section *ini.Section, key string, value any) error {
+	encoded, err := EncodeOption(value)
+	if err != nil {
+		return err
+	}
+
+	// NOTE: The 'ini' writer wraps values containing backticks in triple quotes,
+	// which Godot doesn't support. Backticks can only occur within strings, so
+	// replace them with an equivalent escape sequence.
+	encoded = strings.ReplaceAll(encoded, "`", `\u0060`)
+
+	section.Key(key).SetValue(encoded)
+
+	return nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                            Function: MergePresets                          */
+/* -------------------------------------------------------------------------- */
 
-func valueMapper(s string) string {
-	s = os.ExpandEnv(s)
+// MergePresets combines the contents of an existing 'export_presets.cfg' file
+// with the specified generated presets. Presets in 'cfg' are preserved as-is
+// (though renumbered), except for those with the reserved 'PresetNamePrefix',
+// which are stale presets from a prior export and are dropped. The generated
+// presets are appended after the preserved ones.
+func MergePresets(cfg string, presets []*Preset) (string, error) {
+	sections, err := parseSections(cfg)
+	if err != nil {
+		return "", err
+	}
 
-	// NOTE: There doesn't seem to be a way to apply this value mapper to
-	// specific fields, so this is the best way to match the 'export_files'
-	// field. This may need to be updated in the future.
-	if strings.Contains(s, resourcePathPrefix) {
-		paths := strings.Split(s, ",")
+	var out strings.Builder
 
-		elements := make([]string, len(paths))
-		for i, path := range paths {
-			elements[i] = fmt.Sprintf(`"%s"`, path)
+	var index int
+
+	for _, s := range sections {
+		if s.index < 0 {
+			writeSection(&out, s.header(), s.Lines)
+
+			continue
 		}
 
-		return fmt.Sprintf("PackedStringArray(%s)", strings.Join(elements, ", "))
+		if s.options || strings.HasPrefix(s.name, PresetNamePrefix) {
+			continue
+		}
+
+		header := "preset." + strconv.Itoa(index)
+
+		writeSection(&out, "["+header+"]", s.Lines)
+
+		// NOTE: The 'options' section may precede the preset's section, so
+		// search all sections for it.
+		for _, o := range sections {
+			if o.options && o.index == s.index {
+				writeSection(&out, "["+header+".options]", o.Lines)
+			}
+		}
+
+		index++
 	}
 
-	return `"` + s + `"`
+	for _, preset := range presets {
+		var p strings.Builder
+
+		if err := preset.Marshal(&p, index); err != nil {
+			return "", err
+		}
+
+		if p.Len() == 0 {
+			continue
+		}
+
+		if out.Len() > 0 {
+			out.WriteString("\n")
+		}
+
+		out.WriteString(strings.TrimRight(p.String(), "\n") + "\n")
+
+		index++
+	}
+
+	return out.String(), nil
+}
+
+/* ---------------------------- Struct: section ---------------------------- */
+
+// section is a single section of an 'export_presets.cfg' file. Sections are
+// preserved verbatim rather than re-serialized.
+type section struct {
+	project.Section
+
+	// index is the preset index of a '[preset.N]' or '[preset.N.options]'
+	// section, or -1 for any other section.
+	index int
+	// name is the value of the preset's 'name' property, if any.
+	name string
+	// options is whether this is a '[preset.N.options]' section.
+	options bool
+}
+
+/* ----------------------------- Method: header ----------------------------- */
+
+// header returns the section's header line, if any.
+func (s *section) header() string {
+	if s.Name == "" {
+		return ""
+	}
+
+	return "[" + s.Name + "]"
+}
+
+/* ------------------------- Function: parseSections ------------------------ */
+
+// parseSections splits the contents of an 'export_presets.cfg' file into its
+// sections. Any lines preceding the first section are returned as a section
+// without a name.
+func parseSections(cfg string) ([]section, error) {
+	cf, err := project.ParseConfigFile(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := make([]section, 0, len(cf.Sections))
+
+	for _, s := range cf.Sections {
+		sec := section{Section: s, index: -1} //nolint:exhaustruct
+
+		rest, ok := strings.CutPrefix(s.Name, "preset.")
+		if ok {
+			rest, sec.options = strings.CutSuffix(rest, ".options")
+
+			if index, err := strconv.Atoi(rest); err == nil && index >= 0 {
+				sec.index = index
+			} else {
+				sec.options = false
+			}
+		}
+
+		if sec.index >= 0 && !sec.options {
+			sec.name = s.String("name")
+		}
+
+		sections = append(sections, sec)
+	}
+
+	return sections, nil
+}
+
+/* -------------------------- Function: writeSection ------------------------ */
+
+func writeSection(w *strings.Builder, header string, body []string) {
+	// Trim surrounding blank lines; sections are separated by a single one.
+	for len(body) > 0 && strings.TrimSpace(body[0]) == "" {
+		body = body[1:]
+	}
+
+	for len(body) > 0 && strings.TrimSpace(body[len(body)-1]) == "" {
+		body = body[:len(body)-1]
+	}
+
+	if header == "" && len(body) == 0 {
+		return
+	}
+
+	if w.Len() > 0 {
+		w.WriteString("\n")
+	}
+
+	if header != "" {
+		w.WriteString(header + "\n")
+	}
+
+	for _, line := range body {
+		w.WriteString(line + "\n")
+	}
 }
 
 /* -------------------------------------------------------------------------- */
 /*                    Function: NewWriteExportPresetsAction                   */
 /* -------------------------------------------------------------------------- */
 
-// NewWriteExportPresetsAction creates a new 'action.Action' which constructs an
-// 'export_presets.cfg' file based on the target. It will be written to the
-// workspace directory and overwrite any existing files.
+// NewWriteExportPresetsAction creates a new 'action.Action' which writes the
+// target's presets to the workspace's 'export_presets.cfg' file. Existing
+// presets in the file are preserved; see 'MergePresets' for details.
 func NewWriteExportPresetsAction(
 	rc *run.Context,
 	x *Export,
 ) action.WithDescription[action.Function] {
-	path := filepath.Join(rc.PathWorkspace.String(), "export_presets.cfg")
+	path := filepath.Join(rc.PathWorkspace.String(), FilenameExportPresets)
 
 	fn := func(_ context.Context) error {
 		presets, err := x.Presets(rc)
@@ -268,30 +472,21 @@ func NewWriteExportPresetsAction(
 			return err
 		}
 
-		var cfg strings.Builder
-
-		for i, preset := range presets {
-			if err := preset.Marshal(&cfg, i); err != nil {
-				return err
-			}
-		}
-
-		f, err := os.Create(filepath.Join(rc.PathWorkspace.String(), "export_presets.cfg"))
-		if err != nil {
+		existing, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
 			return err
 		}
 
-		defer f.Close()
-
-		if _, err := io.Copy(f, strings.NewReader(cfg.String()+"\n")); err != nil {
+		cfg, err := MergePresets(string(existing), presets)
+		if err != nil {
 			return err
 		}
 
-		return nil
+		return os.WriteFile(path, []byte(cfg), osutil.ModeUserRW)
 	}
 
 	return action.WithDescription[action.Function]{
 		Action:      fn,
-		Description: "generate export presets file: " + path,
+		Description: "write export presets file: " + path,
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/coffeebeats/gdbuild/pkg/godot/export"
 	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
@@ -43,11 +44,11 @@ encrypt_pck                = false
 encrypt_directory          = false
 encryption_include_filters = ""
 exclude_filter             = ""
-export_files               = ""
+export_files               = PackedStringArray()
 export_filter              = ""
 custom_features            = ""
 include_filter             = ""
-name                       = "Export name"
+name                       = "gdbuild:Export name"
 runnable                   = true
 dedicated_server           = false
 
@@ -73,15 +74,47 @@ encrypt_pck                = false
 encrypt_directory          = false
 encryption_include_filters = ""
 exclude_filter             = ""
-export_files               = PackedStringArray("res://A/B/C.gd","res://B/C/D.tscn")
+export_files               = PackedStringArray("res://A/B/C.gd", "res://B/C/D.tscn")
 export_filter              = ""
 custom_features            = "feature1,feature2"
 include_filter             = ""
-name                       = ""
+name                       = "gdbuild:"
 runnable                   = false
 dedicated_server           = false
 
 [preset.1.options]
+`,
+		},
+		{
+			name: "options are encoded correctly",
+
+			preset: export.Preset{
+				Platform: platform.OSWeb,
+				Options: map[string]any{
+					"html/head_include":   "<script src=\"res://a.js\"></script>\n`x`;#",
+					"progressive_web_app": true,
+					"texture_format/s3tc": []any{"a", "b"},
+				},
+			},
+
+			want: `[preset.0]
+platform                   = "Web"
+encrypt_pck                = false
+encrypt_directory          = false
+encryption_include_filters = ""
+exclude_filter             = ""
+export_files               = PackedStringArray()
+export_filter              = ""
+custom_features            = ""
+include_filter             = ""
+name                       = "gdbuild:"
+runnable                   = false
+dedicated_server           = false
+
+[preset.0.options]
+html/head_include   = "<script src=\"res://a.js\"></script>\n\u0060x\u0060;#"
+progressive_web_app = true
+texture_format/s3tc = PackedStringArray("a", "b")
 `,
 		},
 	}
@@ -102,3 +135,204 @@ dedicated_server           = false
 		})
 	}
 }
+
+func TestMergePresets(t *testing.T) {
+	preset := export.Preset{Name: "game.pck", Platform: platform.OSLinux} //nolint:exhaustruct
+
+	var generated strings.Builder
+	require.NoError(t, (&export.Preset{Name: "game.pck", Platform: platform.OSLinux}).Marshal(&generated, 1)) //nolint:exhaustruct
+
+	tests := []struct {
+		name string
+
+		cfg string
+
+		want string
+	}{
+		{
+			name: "empty file returns generated presets",
+
+			cfg: "",
+
+			want: strings.ReplaceAll(generated.String(), "preset.1", "preset.0"),
+		},
+		{
+			name: "user presets are preserved and stale presets are replaced",
+
+			cfg: `[preset.0]
+
+name="gdbuild:old.pck"
+platform="Linux/X11"
+
+[preset.0.options]
+
+custom_template/debug=""
+
+[preset.1]
+
+name="Linux"
+platform="Linux/X11"
+export_path="build/game.x86_64"
+
+[preset.1.options]
+
+ssh_remote_deploy/run_script="#!/usr/bin/env bash
+[preset.9]
+\"{temp_dir}/{exe_name}\" {cmd_args}"
+`,
+
+			want: `[preset.0]
+name="Linux"
+platform="Linux/X11"
+export_path="build/game.x86_64"
+
+[preset.0.options]
+ssh_remote_deploy/run_script="#!/usr/bin/env bash
+[preset.9]
+\"{temp_dir}/{exe_name}\" {cmd_args}"
+
+` + generated.String(),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A fresh copy of the generated preset.
+			p := preset
+
+			// When: The existing file is merged with the generated preset.
+			got, err := export.MergePresets(tc.cfg, []*export.Preset{&p})
+
+			// Then: There's no error.
+			require.NoError(t, err)
+
+			// Then: The resulting file matches expectations.
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestParsePresets(t *testing.T) {
+	// Given: A preset which is serialized by 'gdbuild'.
+	embed := export.Preset{ //nolint:exhaustruct
+		Arch:          platform.ArchAmd64,
+		Embed:         true,
+		Encrypt:       true,
+		EncryptIndex:  true,
+		Encrypted:     []string{"scripts/*"},
+		ExportedFiles: []string{"res://a.gd", "res://b.tscn"},
+		ExportMode:    export.ModeResources,
+		Features:      []string{"demo", "steam"},
+		Name:          "game.x86_64",
+		Options: map[string]any{
+			"application/name":    "Game",
+			"application/scale":   2.0,
+			"application/tags":    []any{"a", "b"},
+			"application/version": int64(3),
+			"texture_format/s3tc": true,
+		},
+		Platform: platform.OSLinux,
+		Runnable: true,
+	}
+
+	var cfg strings.Builder
+	require.NoError(t, (&export.Preset{}).Marshal(&cfg, 0)) //nolint:exhaustruct
+
+	// NOTE: Rename the preset so that it's not treated as a generated preset.
+	var serialized strings.Builder
+
+	p := embed
+	require.NoError(t, p.Marshal(&serialized, 0))
+
+	cfg.WriteString(strings.Replace(serialized.String(), export.PresetNamePrefix, "", 1))
+
+	// Given: A preset which was generated by 'gdbuild' during an export.
+	generated := export.Preset{Name: "stale", Platform: platform.OSLinux} //nolint:exhaustruct
+	require.NoError(t, generated.Marshal(&cfg, 2))
+
+	// Given: A preset written by the Godot editor.
+	cfg.WriteString(`
+[preset.1]
+
+name="Server"
+platform="Linux"
+runnable=true
+dedicated_server=true
+custom_features=""
+export_filter="customized"
+customized_files={
+"res://": "strip",
+"res://main.tscn": "keep"
+}
+include_filter="*.json, data/*"
+exclude_filter=""
+export_path="build/server.x86_64"
+encrypt_pck=false
+
+[preset.1.options]
+
+custom_template/debug=""
+binary_format/embed_pck=false
+texture_format/etc2_astc=false
+ssh_remote_deploy/run_script="#!/usr/bin/env bash
+export DISPLAY=:0"
+application/ratio=1.5
+application/size=Vector2i(640, 480)
+application/layers=[1, "two", {
+"three": 3.0
+}]
+application/weights=PackedFloat32Array(0.5, 1)
+ssh_remote_deploy/port="22"
+binary_format/architecture="x86_64"
+`)
+
+	// When: The presets are parsed.
+	got, err := export.ParsePresets(cfg.String())
+
+	// Then: There's no error.
+	require.NoError(t, err)
+
+	// Then: The presets match expectations, excluding the generated preset.
+	want := []export.Preset{
+		{ //nolint:exhaustruct
+			Arch:          platform.ArchAmd64,
+			Embed:         true,
+			Encrypt:       true,
+			EncryptIndex:  true,
+			Encrypted:     []string{"scripts/*"},
+			ExportedFiles: []string{"res://a.gd", "res://b.tscn"},
+			ExportMode:    export.ModeResources,
+			Features:      []string{"demo", "steam"},
+			Name:          "game.x86_64",
+			Options: map[string]any{
+				"application/name":    "Game",
+				"application/scale":   2.0,
+				"application/tags":    []string{"a", "b"},
+				"application/version": int64(3),
+				"texture_format/s3tc": true,
+			},
+			Platform: platform.OSLinux,
+			Runnable: true,
+		},
+		{ //nolint:exhaustruct
+			Arch:            platform.ArchAmd64,
+			CustomizedFiles: map[string]string{"res://": "strip", "res://main.tscn": "keep"},
+			ExportMode:      export.ModeCustomized,
+			Include:         "*.json, data/*",
+			Name:            "Server",
+			Options: map[string]any{
+				"application/layers":           []any{int64(1), "two", map[string]any{"three": 3.0}},
+				"application/ratio":            1.5,
+				"application/weights":          []any{0.5, 1.0},
+				"ssh_remote_deploy/port":       "22",
+				"ssh_remote_deploy/run_script": "#!/usr/bin/env bash\nexport DISPLAY=:0",
+				"texture_format/etc2_astc":     false,
+			},
+			Platform: platform.OSLinux,
+			Runnable: true,
+			Server:   true,
+		},
+	}
+
+	assert.Equal(t, want, got)
+}
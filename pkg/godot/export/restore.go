@@ -0,0 +1,146 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+const (
+	// dirnameImported is the name of the Godot project's editor data directory,
+	// which contains imported resources.
+	dirnameImported = ".godot"
+
+	// patternBackup is the pattern of the workspace directory into which
+	// project files are moved during an export. NOTE: This is a hidden
+	// directory so that the Godot editor doesn't scan it.
+	patternBackup = ".gdbuild-backup-*"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                     Function: NewRestoreProjectAction                      */
+/* -------------------------------------------------------------------------- */
+
+// NewRestoreProjectAction wraps the provided action such that the workspace's
+// 'export_presets.cfg' file and '.godot' directory are restored to their
+// original state afterwards, even if 'a' fails or is interrupted. The '.godot'
+// directory is moved aside prior to running 'a' so that the project is freshly
+// imported.
+func NewRestoreProjectAction(rc *run.Context, a action.Action) action.Action { //nolint:ireturn
+	var pathBackup string
+
+	backup := func(_ context.Context) error {
+		path, err := os.MkdirTemp(rc.PathWorkspace.String(), patternBackup)
+		if err != nil {
+			return err
+		}
+
+		log.Debugf("backing up project files to: %s", path)
+
+		// NOTE: The presets file is copied (rather than moved) because its
+		// existing presets are merged with the generated ones.
+		if err := copyIfExists(
+			rc.PathWorkspace.Join(FilenameExportPresets).String(),
+			filepath.Join(path, FilenameExportPresets),
+		); err != nil {
+			return errors.Join(err, os.RemoveAll(path))
+		}
+
+		if err := renameIfExists(
+			rc.PathWorkspace.Join(dirnameImported).String(),
+			filepath.Join(path, dirnameImported),
+		); err != nil {
+			return errors.Join(err, os.RemoveAll(path))
+		}
+
+		pathBackup = path
+
+		return nil
+	}
+
+	restore := func(_ context.Context) error {
+		// Nothing was backed up, so there's nothing to restore.
+		if pathBackup == "" {
+			return nil
+		}
+
+		log.Debugf("restoring project files from: %s", pathBackup)
+
+		var err error
+
+		for _, name := range []string{FilenameExportPresets, dirnameImported} {
+			path := rc.PathWorkspace.Join(name).String()
+
+			if errRemove := os.RemoveAll(path); errRemove != nil {
+				err = errors.Join(err, errRemove)
+
+				continue
+			}
+
+			err = errors.Join(err, renameIfExists(filepath.Join(pathBackup, name), path))
+		}
+
+		if err != nil {
+			return fmt.Errorf(
+				"failed to restore project files; originals remain in '%s': %w",
+				pathBackup,
+				err,
+			)
+		}
+
+		return os.RemoveAll(pathBackup)
+	}
+
+	return action.InOrder(
+		action.WithDescription[action.Function]{
+			Action:      backup,
+			Description: "back up project files: " + rc.PathWorkspace.String(),
+		},
+		action.Finally{
+			Action: a,
+			Cleanup: action.WithDescription[action.Function]{
+				Action:      restore,
+				Description: "restore project files: " + rc.PathWorkspace.String(),
+			},
+		},
+	)
+}
+
+/* ------------------------- Function: copyIfExists ------------------------- */
+
+func copyIfExists(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	mode, err := osutil.ModeOf(src)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(dst, data, mode)
+}
+
+/* ------------------------ Function: renameIfExists ------------------------ */
+
+func renameIfExists(src, dst string) error {
+	if err := os.Rename(src, dst); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
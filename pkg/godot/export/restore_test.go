@@ -0,0 +1,101 @@
+package export_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+var errExport = errors.New("export failed")
+
+func TestNewRestoreProjectAction(t *testing.T) {
+	tests := []struct {
+		name string
+
+		presets string // Empty if the file doesn't exist.
+		cancel  bool
+
+		err error
+	}{
+		{name: "missing presets file is removed afterwards"},
+		{name: "existing presets file is restored", presets: "[preset.0]\nname=\"Linux\"\n"},
+		{name: "files are restored after a failure", presets: "[preset.0]\n", err: errExport},
+		{name: "files are restored after an interrupt", presets: "[preset.0]\n", cancel: true, err: context.Canceled},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A project with an imported resource and, optionally, presets.
+			root := t.TempDir()
+
+			pathPresets := filepath.Join(root, export.FilenameExportPresets)
+			pathImported := filepath.Join(root, ".godot", "imported", "icon.ctex")
+
+			require.NoError(t, os.MkdirAll(filepath.Dir(pathImported), 0o750))
+			require.NoError(t, os.WriteFile(pathImported, []byte("texture"), 0o600))
+
+			if tc.presets != "" {
+				require.NoError(t, os.WriteFile(pathPresets, []byte(tc.presets), 0o600))
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// Given: An export which modifies the project files.
+			var fn action.Function = func(ctx context.Context) error {
+				// The '.godot' directory is moved aside for a fresh import.
+				if _, err := os.Stat(pathImported); !errors.Is(err, os.ErrNotExist) {
+					t.Fatalf("output: got %v, want %v", err, os.ErrNotExist)
+				}
+
+				require.NoError(t, os.WriteFile(pathPresets, []byte("generated"), 0o600))
+				require.NoError(t, os.MkdirAll(filepath.Join(root, ".godot"), 0o750))
+
+				if tc.cancel {
+					cancel()
+
+					return ctx.Err()
+				}
+
+				return tc.err
+			}
+
+			rc := run.Context{PathWorkspace: osutil.Path(root)} //nolint:exhaustruct
+
+			// When: The export is run.
+			err := export.NewRestoreProjectAction(&rc, fn).Run(ctx)
+
+			// Then: The returned error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+
+			// Then: The presets file is restored.
+			got, err := os.ReadFile(pathPresets)
+			if tc.presets == "" {
+				assert.ErrorIs(t, err, os.ErrNotExist)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.presets, string(got))
+			}
+
+			// Then: The imported resources are restored.
+			assert.FileExists(t, pathImported)
+
+			// Then: The backup directory is removed.
+			matches, err := filepath.Glob(filepath.Join(root, ".gdbuild-backup-*"))
+			require.NoError(t, err)
+			assert.Empty(t, matches)
+		})
+	}
+}
@@ -0,0 +1,442 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/config"
+	"github.com/coffeebeats/gdbuild/pkg/godot/pck"
+	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+// maxReportedFiles is the maximum number of files listed in a verification
+// error message.
+const maxReportedFiles = 10
+
+// generatedFiles are files which Godot adds to every pack file, regardless of
+// which project files were selected.
+var generatedFiles = []string{ //nolint:gochecknoglobals
+	"res://project.binary",
+	"res://.godot/extension_list.cfg",
+	"res://.godot/global_script_class_cache.cfg",
+	"res://.godot/uid_cache.bin",
+}
+
+// generatedDirs are directories into which Godot writes converted versions of
+// selected project files (e.g. binary scenes), each referenced by a '.remap'
+// file which is itself verified.
+var generatedDirs = []string{ //nolint:gochecknoglobals
+	"res://.godot/exported/",
+}
+
+/* -------------------------------------------------------------------------- */
+/*                        Function: NewVerifyPacksAction                      */
+/* -------------------------------------------------------------------------- */
+
+// NewVerifyPacksAction creates an 'action.Action' which verifies that each
+// exported pack file contains exactly the project files selected by its
+// 'PackFile' configuration (along with their imported resources) and that its
+// encryption matches the configuration.
+func NewVerifyPacksAction(rc *run.Context, xp *Export) action.WithDescription[action.Function] {
+	names := make([]string, 0, len(xp.PackFiles))
+	for i, pf := range xp.PackFiles {
+		names = append(names, pf.Filename(rc.Platform, rc.Target, i))
+	}
+
+	fn := func(_ context.Context) error {
+		for i, pf := range xp.PackFiles {
+			if err := verifyPack(rc, xp, &pf, i); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return action.WithDescription[action.Function]{
+		Action:      fn,
+		Description: "verify contents of pack files: " + strings.Join(names, ","),
+	}
+}
+
+/* --------------------------- Function: verifyPack -------------------------- */
+
+func verifyPack(rc *run.Context, xp *Export, pf *PackFile, index int) error { //nolint:cyclop
+	name := pf.Filename(rc.Platform, rc.Target, index)
+
+	path, err := packPath(rc, pf, index)
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		log.Warnf("skipping verification of pack file; unsupported for platform: %s", name)
+
+		return nil
+	}
+
+	var entries []pck.File
+
+	isEncrypted := config.Dereference(pf.Encrypt)
+
+	if config.Dereference(pf.Zip) {
+		entries, err = readZipEntries(path)
+		if err != nil {
+			return err
+		}
+	} else {
+		key, err := hex.DecodeString(xp.EncryptionKey)
+		if err != nil {
+			return fmt.Errorf("%w: invalid encryption key: %w", ErrInvalidInput, err)
+		}
+
+		if isEncrypted && len(key) == 0 {
+			return fmt.Errorf("%w: cannot verify encrypted pack file without a key: %s", ErrMissingInput, name)
+		}
+
+		p, err := pck.OpenWithKey(path, key)
+		if err != nil {
+			return fmt.Errorf("cannot read pack file: %s: %w", name, err)
+		}
+
+		if p.IsDirectoryEncrypted() != isEncrypted {
+			return fmt.Errorf(
+				"%w: %s: expected encrypted directory to be %t",
+				ErrPackContentsMismatch,
+				name,
+				isEncrypted,
+			)
+		}
+
+		entries = p.Files
+	}
+
+	expected, err := pf.ExpectedContents(rc)
+	if err != nil {
+		return err
+	}
+
+	return expected.Verify(name, entries, isEncrypted, pf.Include)
+}
+
+/* ---------------------------- Function: packPath --------------------------- */
+
+// packPath returns the path to the file containing the exported pack. If the
+// pack file can't be inspected for the platform, an empty string is returned.
+func packPath(rc *run.Context, pf *PackFile, index int) (string, error) {
+	name := pf.Filename(rc.Platform, rc.Target, index)
+	path := rc.PathOut.Join(name).String()
+
+	if !config.Dereference(pf.Embed) {
+		return path, nil
+	}
+
+	switch rc.Platform { //nolint:exhaustive
+	case platform.OSLinux, platform.OSWindows:
+		return path, nil
+	case platform.OSWeb:
+		return strings.TrimSuffix(path, ".html") + ".pck", nil
+	case platform.OSMacOS:
+		// NOTE: The pack file is named after the application, so search for it.
+		matches, err := filepath.Glob(filepath.Join(path, "Contents/Resources/*.pck"))
+		if err != nil {
+			return "", err
+		}
+
+		if len(matches) != 1 {
+			return "", fmt.Errorf(
+				"%w: expected a single pack file in application bundle: %s",
+				ErrMissingInput,
+				name,
+			)
+		}
+
+		return matches[0], nil
+	default:
+		return "", nil
+	}
+}
+
+/* ------------------------ Function: readZipEntries ------------------------- */
+
+func readZipEntries(path string) ([]pck.File, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close()
+
+	entries := make([]pck.File, 0, len(r.File))
+
+	for _, f := range r.File {
+		if strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+
+		entries = append(entries, pck.File{ //nolint:exhaustruct
+			Path: resourcePathPrefix + f.Name,
+			Size: int64(f.UncompressedSize64), //nolint:gosec
+		})
+	}
+
+	return entries, nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                            Struct: PackContents                            */
+/* -------------------------------------------------------------------------- */
+
+// PackContents describes the expected contents of an exported pack file.
+type PackContents struct {
+	// Required contains, for each selected project file, the set of pack
+	// entries of which at least one must be present.
+	Required map[string][]string
+	// Allowed contains additional pack entries which may be present.
+	Allowed map[string]struct{}
+}
+
+/* ------------------------ Method: ExpectedContents ------------------------ */
+
+// ExpectedContents computes the expected contents of the exported pack file.
+// Project files which Godot imports are represented in the pack file by their
+// '.import' file and the imported resources listed in it. Other files may be
+// converted during export, in which case a '.remap' file is present instead.
+func (c *PackFile) ExpectedContents(rc *run.Context) (PackContents, error) {
+	ff, err := c.Files(rc.PathWorkspace)
+	if err != nil {
+		return PackContents{}, err
+	}
+
+	root, err := filepath.Abs(rc.PathWorkspace.String())
+	if err != nil {
+		return PackContents{}, err
+	}
+
+	out := PackContents{
+		Required: make(map[string][]string, len(ff)),
+		Allowed:  make(map[string]struct{}),
+	}
+
+	for _, f := range ff {
+		res, err := resourcePath(root, f)
+		if err != nil {
+			return PackContents{}, err
+		}
+
+		// NOTE: '.import' files are verified alongside their source file.
+		if strings.HasSuffix(res, ".import") {
+			continue
+		}
+
+		dest, isImported, err := readImportDestinations(f.String() + ".import")
+		if err != nil {
+			return PackContents{}, err
+		}
+
+		if !isImported {
+			out.Required[res] = []string{res, res + ".remap"}
+
+			// Compiled scripts are remapped to a '.gdc' file.
+			if strings.HasSuffix(res, ".gd") {
+				out.Allowed[strings.TrimSuffix(res, ".gd")+".gdc"] = struct{}{}
+			}
+
+			continue
+		}
+
+		out.Required[res] = []string{res + ".import"}
+
+		// NOTE: Only one variant (e.g. 's3tc' or 'etc2') of an imported
+		// resource is exported, depending on the target platform.
+		if len(dest) > 0 {
+			out.Required[res+" (imported)"] = dest
+		}
+	}
+
+	return out, nil
+}
+
+/* ----------------------------- Method: Verify ----------------------------- */
+
+// Verify compares the entries of the pack file 'name' against the expected
+// contents. If 'encrypt' is set, entries for project files matching one of
+// the 'filters' must be encrypted; otherwise, no entries may be encrypted.
+func (pc PackContents) Verify( //nolint:cyclop,funlen
+	name string,
+	entries []pck.File,
+	encrypt bool,
+	filters []string,
+) error {
+	present := make(map[string]pck.File, len(entries))
+	for _, e := range entries {
+		if !e.IsRemoval() {
+			present[e.Path] = e
+		}
+	}
+
+	expected := make(map[string]struct{}, len(pc.Allowed))
+	for path := range pc.Allowed {
+		expected[path] = struct{}{}
+	}
+
+	var missing, unencrypted, encrypted []string
+
+	for res, candidates := range pc.Required {
+		found := false
+
+		for _, c := range candidates {
+			expected[c] = struct{}{}
+
+			e, ok := present[c]
+			if !ok {
+				continue
+			}
+
+			found = true
+
+			if encrypt && !e.IsEncrypted() && matchesAnyFilter(c, filters) {
+				unencrypted = append(unencrypted, c)
+			}
+		}
+
+		if !found {
+			missing = append(missing, strings.TrimSuffix(res, " (imported)"))
+		}
+	}
+
+	var unexpected []string
+
+	for path, e := range present {
+		if !encrypt && e.IsEncrypted() {
+			encrypted = append(encrypted, path)
+		}
+
+		if _, ok := expected[path]; ok || isGeneratedFile(path) {
+			continue
+		}
+
+		unexpected = append(unexpected, path)
+	}
+
+	for _, check := range []struct {
+		files  []string
+		reason string
+	}{
+		{missing, "missing files"},
+		{unexpected, "unexpected files"},
+		{unencrypted, "unencrypted files"},
+		{encrypted, "encrypted files"},
+	} {
+		if len(check.files) == 0 {
+			continue
+		}
+
+		return fmt.Errorf(
+			"%w: %s: %s: %s",
+			ErrPackContentsMismatch,
+			name,
+			check.reason,
+			summarizeFiles(check.files),
+		)
+	}
+
+	return nil
+}
+
+/* ------------------------ Function: isGeneratedFile ------------------------ */
+
+func isGeneratedFile(path string) bool {
+	if slices.Contains(generatedFiles, path) {
+		return true
+	}
+
+	for _, dir := range generatedDirs {
+		if strings.HasPrefix(path, dir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/* ------------------------- Function: summarizeFiles ------------------------ */
+
+func summarizeFiles(files []string) string {
+	slices.Sort(files)
+
+	if len(files) <= maxReportedFiles {
+		return strings.Join(files, ", ")
+	}
+
+	return fmt.Sprintf(
+		"%s (and %d more)",
+		strings.Join(files[:maxReportedFiles], ", "),
+		len(files)-maxReportedFiles,
+	)
+}
+
+/* ----------------------- Function: matchesAnyFilter ----------------------- */
+
+// matchesAnyFilter reports whether 'path' matches one of the filters, using
+// the same rules as Godot's export filters: a case-insensitive wildcard match
+// against either the full resource path or the path without the 'res://'
+// prefix, where '*' matches any sequence of characters (including '/').
+func matchesAnyFilter(path string, filters []string) bool {
+	for _, f := range filters {
+		re := wildcardToRegexp(f)
+
+		if re.MatchString(path) || re.MatchString(strings.TrimPrefix(path, resourcePathPrefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+/* ----------------------- Function: wildcardToRegexp ----------------------- */
+
+func wildcardToRegexp(pattern string) *regexp.Regexp {
+	var sb strings.Builder
+
+	sb.WriteString("(?i)^")
+
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	sb.WriteString("$")
+
+	return regexp.MustCompile(sb.String())
+}
+
+/* -------------------- Function: readImportDestinations -------------------- */
+
+// readImportDestinations parses the '.import' file at 'path' and returns the
+// imported resources listed under 'dest_files'. The boolean return value
+// reports whether the file exists (i.e. whether the source file is imported).
+func readImportDestinations(path string) ([]string, bool, error) {
+	cfg, err := readConfigFile(path)
+	if err != nil || cfg == nil {
+		return nil, false, err
+	}
+
+	return cfg.Strings("deps", "dest_files"), true, nil
+}
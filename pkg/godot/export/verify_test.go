@@ -0,0 +1,173 @@
+package export_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/godot/export"
+	"github.com/coffeebeats/gdbuild/pkg/godot/pck"
+	"github.com/coffeebeats/gdbuild/pkg/run"
+)
+
+func TestPackFileExpectedContents(t *testing.T) {
+	// Given: A project with an imported texture, a script, and a scene.
+	root := t.TempDir()
+
+	for name, contents := range map[string]string{
+		"assets/icon.png":        "image",
+		"assets/icon.png.import": "[remap]\n\nimporter=\"texture\"\n\n[deps]\n\ndest_files=[\"res://.godot/imported/icon.png-abc.ctex\"]\n",
+		"scripts/main.gd":        "extends Node",
+		"scenes/main.tscn":       "[gd_scene]",
+	} {
+		path := filepath.Join(root, name)
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	}
+
+	rc := run.Context{PathWorkspace: osutil.Path(root)}                     //nolint:exhaustruct
+	pf := export.PackFile{Include: []string{"assets", "scripts", "scenes"}} //nolint:exhaustruct
+
+	// When: The expected contents of the pack file are computed.
+	got, err := pf.ExpectedContents(&rc)
+	require.NoError(t, err)
+
+	// Then: Imported files require their '.import' file and imported resources.
+	assert.Equal(t, []string{"res://assets/icon.png.import"}, got.Required["res://assets/icon.png"])
+	assert.Equal(t, []string{"res://.godot/imported/icon.png-abc.ctex"}, got.Required["res://assets/icon.png (imported)"])
+
+	// Then: Other files may be remapped.
+	assert.Equal(t, []string{"res://scripts/main.gd", "res://scripts/main.gd.remap"}, got.Required["res://scripts/main.gd"])
+	assert.Equal(t, []string{"res://scenes/main.tscn", "res://scenes/main.tscn.remap"}, got.Required["res://scenes/main.tscn"])
+	assert.Contains(t, got.Allowed, "res://scripts/main.gdc")
+
+	// Then: '.import' files aren't required on their own.
+	assert.NotContains(t, got.Required, "res://assets/icon.png.import")
+}
+
+func TestPackContentsVerify(t *testing.T) {
+	contents := export.PackContents{
+		Required: map[string][]string{
+			"res://assets/icon.png":            {"res://assets/icon.png.import"},
+			"res://assets/icon.png (imported)": {"res://.godot/imported/icon.png-abc.ctex"},
+			"res://scripts/main.gd":            {"res://scripts/main.gd", "res://scripts/main.gd.remap"},
+		},
+		Allowed: map[string]struct{}{"res://scripts/main.gdc": {}},
+	}
+
+	encrypted := func(path string) pck.File {
+		return pck.File{Path: path, Flags: pck.FileFlagEncrypted} //nolint:exhaustruct
+	}
+
+	plain := func(path string) pck.File {
+		return pck.File{Path: path} //nolint:exhaustruct
+	}
+
+	tests := []struct {
+		name string
+
+		entries []pck.File
+		encrypt bool
+		filters []string
+
+		err error
+	}{
+		{
+			name: "exact contents pass",
+
+			entries: []pck.File{
+				plain("res://project.binary"),
+				plain("res://assets/icon.png.import"),
+				plain("res://.godot/imported/icon.png-abc.ctex"),
+				plain("res://scripts/main.gd.remap"),
+				plain("res://scripts/main.gdc"),
+				plain("res://.godot/exported/133200997/export-abc-main.scn"),
+			},
+		},
+		{
+			name: "missing imported resource fails",
+
+			entries: []pck.File{
+				plain("res://assets/icon.png.import"),
+				plain("res://scripts/main.gd"),
+			},
+
+			err: export.ErrPackContentsMismatch,
+		},
+		{
+			name: "unexpected file fails",
+
+			entries: []pck.File{
+				plain("res://assets/icon.png.import"),
+				plain("res://.godot/imported/icon.png-abc.ctex"),
+				plain("res://scripts/main.gd"),
+				plain("res://levels/secret.tscn"),
+			},
+
+			err: export.ErrPackContentsMismatch,
+		},
+		{
+			name: "removal entries are ignored",
+
+			entries: []pck.File{
+				plain("res://assets/icon.png.import"),
+				plain("res://.godot/imported/icon.png-abc.ctex"),
+				plain("res://scripts/main.gd"),
+				{Path: "res://levels/old.tscn", Flags: pck.FileFlagRemoval}, //nolint:exhaustruct
+			},
+		},
+		{
+			name: "encrypted files matching filters pass",
+
+			entries: []pck.File{
+				plain("res://assets/icon.png.import"),
+				plain("res://.godot/imported/icon.png-abc.ctex"),
+				encrypted("res://scripts/main.gd"),
+			},
+			encrypt: true,
+			filters: []string{"scripts/*"},
+		},
+		{
+			name: "unencrypted file matching filters fails",
+
+			entries: []pck.File{
+				plain("res://assets/icon.png.import"),
+				plain("res://.godot/imported/icon.png-abc.ctex"),
+				plain("res://scripts/main.gd"),
+			},
+			encrypt: true,
+			filters: []string{"*.GD"},
+
+			err: export.ErrPackContentsMismatch,
+		},
+		{
+			name: "encrypted file without encryption fails",
+
+			entries: []pck.File{
+				plain("res://assets/icon.png.import"),
+				plain("res://.godot/imported/icon.png-abc.ctex"),
+				encrypted("res://scripts/main.gd"),
+			},
+
+			err: export.ErrPackContentsMismatch,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: The pack file's entries are verified.
+			err := contents.Verify("game.pck", tc.entries, tc.encrypt, tc.filters)
+
+			// Then: The resulting error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
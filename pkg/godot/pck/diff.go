@@ -0,0 +1,210 @@
+package pck
+
+import (
+	"cmp"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Change statuses; see 'Change.Status'.
+const (
+	StatusAdded   = "added"
+	StatusChanged = "changed"
+	StatusRemoved = "removed"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                Struct: Diff                                */
+/* -------------------------------------------------------------------------- */
+
+// Diff describes the differences between the files of two builds.
+type Diff struct {
+	// Changes are the added, changed, and removed files, sorted by the
+	// magnitude of their size change (largest first).
+	Changes []Change `json:"changes"`
+	// Directories are the per-directory totals of the changes, sorted by the
+	// magnitude of their size change (largest first).
+	Directories []DirectoryTotal `json:"directories"`
+	// Total is the total of all changes.
+	Total DirectoryTotal `json:"total"`
+}
+
+/* ------------------------------ Struct: Change ----------------------------- */
+
+// Change describes a single file which differs between two builds.
+type Change struct {
+	// Path is the path of the file within the project.
+	Path string `json:"path"`
+	// Status is one of 'added', 'changed', or 'removed'.
+	Status string `json:"status"`
+	// SizeOld is the size of the file in the old build.
+	SizeOld int64 `json:"size_old"`
+	// SizeNew is the size of the file in the new build.
+	SizeNew int64 `json:"size_new"`
+	// Delta is the change in size of the file.
+	Delta int64 `json:"delta"`
+}
+
+/* -------------------------- Struct: DirectoryTotal ------------------------- */
+
+// DirectoryTotal summarizes the changes to the files within a directory.
+type DirectoryTotal struct {
+	// Directory is the path of the directory within the project.
+	Directory string `json:"directory"`
+	// Added is the number of added files.
+	Added int `json:"added"`
+	// Changed is the number of changed files.
+	Changed int `json:"changed"`
+	// Removed is the number of removed files.
+	Removed int `json:"removed"`
+	// SizeOld is the total size of the directory's files in the old build.
+	SizeOld int64 `json:"size_old"`
+	// SizeNew is the total size of the directory's files in the new build.
+	SizeNew int64 `json:"size_new"`
+	// Delta is the change in size of the directory's files.
+	Delta int64 `json:"delta"`
+}
+
+/* ------------------------------ Method: add ------------------------------- */
+
+func (t *DirectoryTotal) add(c Change) {
+	switch c.Status {
+	case StatusAdded:
+		t.Added++
+	case StatusChanged:
+		t.Changed++
+	case StatusRemoved:
+		t.Removed++
+	}
+
+	t.Delta += c.Delta
+}
+
+/* -------------------------------------------------------------------------- */
+/*                             Function: DiffFiles                            */
+/* -------------------------------------------------------------------------- */
+
+// DiffFiles compares the files of an old build with those of a new build. Files
+// are matched by path and considered changed if either their size or digest
+// differs. Removal entries are ignored.
+func DiffFiles(before, after []File) Diff {
+	old := indexFiles(before)
+	cur := indexFiles(after)
+
+	var out Diff
+
+	dirs := make(map[string]*DirectoryTotal)
+
+	total := func(dir string) *DirectoryTotal {
+		t, ok := dirs[dir]
+		if !ok {
+			t = &DirectoryTotal{Directory: dir} //nolint:exhaustruct
+			dirs[dir] = t
+		}
+
+		return t
+	}
+
+	for p, f := range old {
+		total(directory(p)).SizeOld += f.Size
+		out.Total.SizeOld += f.Size
+	}
+
+	for p, f := range cur {
+		total(directory(p)).SizeNew += f.Size
+		out.Total.SizeNew += f.Size
+
+		prev, ok := old[p]
+
+		switch {
+		case !ok:
+			out.Changes = append(out.Changes, Change{Path: p, Status: StatusAdded, SizeOld: 0, SizeNew: f.Size, Delta: f.Size})
+		case prev.Size != f.Size || prev.MD5 != f.MD5:
+			out.Changes = append(out.Changes, Change{
+				Path:    p,
+				Status:  StatusChanged,
+				SizeOld: prev.Size,
+				SizeNew: f.Size,
+				Delta:   f.Size - prev.Size,
+			})
+		}
+	}
+
+	for p, f := range old {
+		if _, ok := cur[p]; !ok {
+			out.Changes = append(out.Changes, Change{Path: p, Status: StatusRemoved, SizeOld: f.Size, SizeNew: 0, Delta: -f.Size})
+		}
+	}
+
+	for _, c := range out.Changes {
+		total(directory(c.Path)).add(c)
+		out.Total.add(c)
+	}
+
+	slices.SortFunc(out.Changes, func(a, b Change) int {
+		return cmp.Or(cmpImpact(a.Delta, b.Delta), cmp.Compare(a.Path, b.Path))
+	})
+
+	for _, t := range dirs {
+		if t.Added+t.Changed+t.Removed == 0 {
+			continue
+		}
+
+		out.Directories = append(out.Directories, *t)
+	}
+
+	slices.SortFunc(out.Directories, func(a, b DirectoryTotal) int {
+		return cmp.Or(cmpImpact(a.Delta, b.Delta), cmp.Compare(a.Directory, b.Directory))
+	})
+
+	return out
+}
+
+/* --------------------------- Function: cmpImpact -------------------------- */
+
+// cmpImpact orders size changes by descending magnitude.
+func cmpImpact(a, b int64) int {
+	return cmp.Compare(abs(b), abs(a))
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+/* --------------------------- Function: directory --------------------------- */
+
+// directory returns the directory of the file at 'p' (e.g. 'res://assets').
+func directory(p string) string {
+	rel, ok := strings.CutPrefix(p, "res://")
+	if !ok {
+		return path.Dir(p)
+	}
+
+	dir := path.Dir(rel)
+	if dir == "." {
+		dir = ""
+	}
+
+	return "res://" + dir
+}
+
+/* -------------------------- Function: indexFiles --------------------------- */
+
+func indexFiles(files []File) map[string]File {
+	out := make(map[string]File, len(files))
+
+	for _, f := range files {
+		if f.IsRemoval() {
+			continue
+		}
+
+		out[f.Path] = f
+	}
+
+	return out
+}
@@ -0,0 +1,55 @@
+package pck_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/coffeebeats/gdbuild/pkg/godot/pck"
+)
+
+func TestDiffFiles(t *testing.T) {
+	// Given: The files of an old build.
+	before := []pck.File{
+		{Path: "res://project.binary", Size: 10, MD5: "a"},
+		{Path: "res://assets/icon.png", Size: 100, MD5: "b"},
+		{Path: "res://assets/music.ogg", Size: 5000, MD5: "c"},
+		{Path: "res://levels/old.tscn", Size: 300, MD5: "d"},
+	}
+
+	// Given: The files of a new build.
+	after := []pck.File{
+		{Path: "res://project.binary", Size: 10, MD5: "a"},
+		{Path: "res://assets/icon.png", Size: 100, MD5: "e"},
+		{Path: "res://assets/music.ogg", Size: 9000, MD5: "f"},
+		{Path: "res://levels/new.tscn", Size: 200, MD5: "g"},
+		{Path: "res://levels/gone.tscn", Flags: pck.FileFlagRemoval},
+	}
+
+	// When: The builds are compared.
+	got := pck.DiffFiles(before, after)
+
+	// Then: Changes are sorted by the magnitude of their size change.
+	assert.Equal(t, []pck.Change{
+		{Path: "res://assets/music.ogg", Status: pck.StatusChanged, SizeOld: 5000, SizeNew: 9000, Delta: 4000},
+		{Path: "res://levels/old.tscn", Status: pck.StatusRemoved, SizeOld: 300, SizeNew: 0, Delta: -300},
+		{Path: "res://levels/new.tscn", Status: pck.StatusAdded, SizeOld: 0, SizeNew: 200, Delta: 200},
+		{Path: "res://assets/icon.png", Status: pck.StatusChanged, SizeOld: 100, SizeNew: 100, Delta: 0},
+	}, got.Changes)
+
+	// Then: Directory totals are sorted by the magnitude of their size change.
+	assert.Equal(t, []pck.DirectoryTotal{
+		{Directory: "res://assets", Changed: 2, SizeOld: 5100, SizeNew: 9100, Delta: 4000},
+		{Directory: "res://levels", Added: 1, Removed: 1, SizeOld: 300, SizeNew: 200, Delta: -100},
+	}, got.Directories)
+
+	// Then: The total summarizes all changes.
+	assert.Equal(t, pck.DirectoryTotal{
+		Added:   1,
+		Changed: 2,
+		Removed: 1,
+		SizeOld: 5410,
+		SizeNew: 9310,
+		Delta:   3900,
+	}, got.Total)
+}
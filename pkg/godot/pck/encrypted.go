@@ -0,0 +1,105 @@
+package pck
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5" //nolint:gosec
+	"errors"
+	"fmt"
+)
+
+const (
+	// KeySize is the size of a Godot script encryption key in bytes.
+	KeySize = 32
+
+	// encryptedMagic is the 'GDEC' magic number which precedes encrypted
+	// file contents (but not an encrypted pack directory).
+	encryptedMagic uint32 = 0x43454447
+
+	// maxEncryptedLength is a sanity limit on the length of encrypted data.
+	maxEncryptedLength = 1 << 32
+)
+
+var (
+	ErrDecryptionFailed = errors.New("decryption failed")
+	ErrMissingKey       = errors.New("missing encryption key")
+)
+
+/* -------------------------------------------------------------------------- */
+/*                              Function: decrypt                             */
+/* -------------------------------------------------------------------------- */
+
+// decrypt reads and decrypts a block of data encrypted by Godot's
+// 'FileAccessEncrypted' (AES-256 in CFB mode). The layout is:
+//
+//	| magic (u32, optional) | MD5 (16 bytes) | length (u64) | IV (16 bytes) | data |
+//
+// where 'data' is padded to the AES block size. The MD5 digest of the
+// decrypted data is verified, which detects an incorrect key.
+func decrypt(pr *reader, key []byte, withMagic bool) ([]byte, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: expected %d bytes", ErrMissingKey, KeySize)
+	}
+
+	if withMagic && pr.u32() != encryptedMagic && pr.err == nil {
+		return nil, fmt.Errorf("%w: missing encryption header", ErrInvalidFormat)
+	}
+
+	digest := pr.bytes(md5.Size)
+	length := pr.u64()
+	iv := pr.bytes(aes.BlockSize)
+
+	if pr.err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFormat, pr.err)
+	}
+
+	if length > maxEncryptedLength {
+		return nil, fmt.Errorf("%w: encrypted data too large: %d", ErrInvalidFormat, length)
+	}
+
+	size := length
+	if rem := size % aes.BlockSize; rem != 0 {
+		size += aes.BlockSize - rem
+	}
+
+	data := pr.bytes(int(size)) //nolint:gosec
+	if pr.err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFormat, pr.err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	cipher.NewCFBDecrypter(block, iv).XORKeyStream(data, data) //nolint:staticcheck
+
+	data = data[:length]
+
+	sum := md5.Sum(data) //nolint:gosec
+	if !bytes.Equal(sum[:], digest) {
+		return nil, fmt.Errorf("%w: checksum mismatch (is the key correct?)", ErrDecryptionFailed)
+	}
+
+	return data, nil
+}
+
+/* -------------------- Method: readEncryptedDirectory -------------------- */
+
+// readEncryptedDirectory decrypts the pack directory and parses its entries.
+// Godot writes the number of files in plaintext; only the entries themselves
+// are encrypted.
+func (p *Pack) readEncryptedDirectory(pr *reader, key []byte, fileBase uint64) error {
+	count := pr.u32()
+	if pr.err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidFormat, pr.err)
+	}
+
+	data, err := decrypt(pr, key, false)
+	if err != nil {
+		return err
+	}
+
+	return p.readEntries(&reader{r: bytes.NewReader(data)}, count, fileBase) //nolint:exhaustruct
+}
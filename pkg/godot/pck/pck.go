@@ -0,0 +1,374 @@
+package pck
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// magic is the 'GDPC' magic number found at the start of a pack file and,
+	// for packs embedded in an executable, at the very end of the file.
+	magic uint32 = 0x43504447
+
+	// reservedFields is the number of reserved 32-bit fields in the header.
+	reservedFields = 16
+
+	// maxPathLength is a sanity limit on the length of a file path entry.
+	maxPathLength = 1 << 16
+)
+
+// Pack-level flags; see 'core/io/file_access_pack.h' in the Godot source.
+const (
+	FlagDirectoryEncrypted uint32 = 1 << 0
+	FlagRelativeFileBase   uint32 = 1 << 1
+	FlagSparseBundle       uint32 = 1 << 2
+)
+
+// File-level flags; see 'core/io/file_access_pack.h' in the Godot source.
+const (
+	FileFlagEncrypted uint32 = 1 << 0
+	FileFlagRemoval   uint32 = 1 << 1
+)
+
+var (
+	ErrInvalidFormat      = errors.New("invalid pack file")
+	ErrUnsupportedVersion = errors.New("unsupported pack format version")
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                Struct: Pack                                */
+/* -------------------------------------------------------------------------- */
+
+// Pack is a parsed Godot '.pck' file. Only the header and file directory are
+// read; file contents can be accessed using the recorded offsets.
+type Pack struct {
+	// FormatVersion is the version of the pack file format.
+	FormatVersion uint32 `json:"format_version"`
+	// GodotVersion is the version of Godot which created the pack file.
+	GodotVersion Version `json:"godot_version"`
+	// Flags are the pack-level flags.
+	Flags uint32 `json:"flags"`
+	// Embedded is whether the pack is embedded in an executable.
+	Embedded bool `json:"embedded"`
+	// Offset is the position of the pack header within the file.
+	Offset int64 `json:"offset"`
+	// Files is the directory of files in the pack. This will be empty if the
+	// directory is encrypted and no key was provided.
+	Files []File `json:"files"`
+}
+
+/* ---------------------- Method: IsDirectoryEncrypted ---------------------- */
+
+// IsDirectoryEncrypted returns whether the file directory is encrypted. If
+// so, the directory cannot be read without the encryption key.
+func (p *Pack) IsDirectoryEncrypted() bool {
+	return p.Flags&FlagDirectoryEncrypted != 0
+}
+
+/* -------------------------------------------------------------------------- */
+/*                               Struct: Version                              */
+/* -------------------------------------------------------------------------- */
+
+// Version is the version of Godot which created the pack file.
+type Version struct {
+	Major uint32 `json:"major"`
+	Minor uint32 `json:"minor"`
+	Patch uint32 `json:"patch"`
+}
+
+/* ----------------------------- Impl: Stringer ----------------------------- */
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+/* -------------------------------------------------------------------------- */
+/*                                Struct: File                                */
+/* -------------------------------------------------------------------------- */
+
+// File is a single entry in the pack file directory.
+type File struct {
+	// Path is the path of the file within the project (e.g. 'res://icon.png').
+	Path string `json:"path"`
+	// Offset is the absolute position of the file contents within the file.
+	Offset int64 `json:"offset"`
+	// Size is the size of the (possibly encrypted) file contents in bytes.
+	Size int64 `json:"size"`
+	// MD5 is the hex-encoded MD5 digest of the unencrypted file contents.
+	MD5 string `json:"md5"`
+	// Flags are the file-level flags.
+	Flags uint32 `json:"flags"`
+}
+
+/* --------------------------- Method: IsEncrypted -------------------------- */
+
+// IsEncrypted returns whether the file contents are encrypted.
+func (f *File) IsEncrypted() bool {
+	return f.Flags&FileFlagEncrypted != 0
+}
+
+/* ---------------------------- Method: IsRemoval --------------------------- */
+
+// IsRemoval returns whether the entry marks the file as removed (used by
+// patch packs).
+func (f *File) IsRemoval() bool {
+	return f.Flags&FileFlagRemoval != 0
+}
+
+/* -------------------------------------------------------------------------- */
+/*                               Function: Open                               */
+/* -------------------------------------------------------------------------- */
+
+// Open parses the pack file at 'path', which may be a '.pck' file or an
+// executable with an embedded pack.
+func Open(path string) (*Pack, error) {
+	return OpenWithKey(path, nil)
+}
+
+/* -------------------------------------------------------------------------- */
+/*                            Function: OpenWithKey                           */
+/* -------------------------------------------------------------------------- */
+
+// OpenWithKey parses the pack file at 'path' like 'Open', but uses 'key' to
+// decrypt the file directory if it's encrypted.
+func OpenWithKey(path string, key []byte) (*Pack, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return ReadWithKey(f, key)
+}
+
+/* -------------------------------------------------------------------------- */
+/*                               Function: Read                               */
+/* -------------------------------------------------------------------------- */
+
+// Read parses a pack file from 'r', which may contain a standalone pack or an
+// executable with an embedded pack. If the file directory is encrypted, no
+// files are read.
+func Read(r io.ReadSeeker) (*Pack, error) {
+	return ReadWithKey(r, nil)
+}
+
+/* -------------------------------------------------------------------------- */
+/*                            Function: ReadWithKey                           */
+/* -------------------------------------------------------------------------- */
+
+// ReadWithKey parses a pack file from 'r' like 'Read', but uses 'key' to
+// decrypt the file directory if it's encrypted. If 'key' is empty, the files
+// of an encrypted directory are not read.
+func ReadWithKey(r io.ReadSeeker, key []byte) (*Pack, error) {
+	start, embedded, err := locate(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.Seek(start+4, io.SeekStart); err != nil { //nolint:gomnd
+		return nil, err
+	}
+
+	pr := reader{r: r} //nolint:exhaustruct
+
+	p := Pack{ //nolint:exhaustruct
+		Embedded:      embedded,
+		FormatVersion: pr.u32(),
+		GodotVersion:  Version{Major: pr.u32(), Minor: pr.u32(), Patch: pr.u32()},
+		Offset:        start,
+	}
+
+	var fileBase, dirOffset uint64
+
+	switch p.FormatVersion {
+	case 1:
+	case 2, 3: //nolint:gomnd
+		p.Flags = pr.u32()
+		fileBase = pr.u64()
+
+		if p.FormatVersion == 3 { //nolint:gomnd
+			dirOffset = pr.u64()
+		}
+	default:
+		if pr.err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidFormat, pr.err)
+		}
+
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, p.FormatVersion)
+	}
+
+	for range reservedFields {
+		pr.u32()
+	}
+
+	if pr.err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFormat, pr.err)
+	}
+
+	if p.Flags&FlagRelativeFileBase != 0 {
+		fileBase += uint64(start)
+	}
+
+	if dirOffset != 0 {
+		if _, err := r.Seek(start+int64(dirOffset), io.SeekStart); err != nil { //nolint:gosec
+			return nil, err
+		}
+	}
+
+	if p.IsDirectoryEncrypted() {
+		if len(key) == 0 {
+			return &p, nil
+		}
+
+		if err := p.readEncryptedDirectory(&pr, key, fileBase); err != nil {
+			return nil, err
+		}
+
+		return &p, nil
+	}
+
+	if err := p.readDirectory(&pr, fileBase); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+/* -------------------------- Method: readDirectory ------------------------- */
+
+func (p *Pack) readDirectory(pr *reader, fileBase uint64) error {
+	count := pr.u32()
+	if pr.err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidFormat, pr.err)
+	}
+
+	return p.readEntries(pr, count, fileBase)
+}
+
+/* -------------------------- Method: readEntries --------------------------- */
+
+// readEntries parses 'count' file entries of the pack directory.
+func (p *Pack) readEntries(pr *reader, count uint32, fileBase uint64) error {
+	for range count {
+		length := pr.u32()
+		if pr.err == nil && length > maxPathLength {
+			return fmt.Errorf("%w: path length too large: %d", ErrInvalidFormat, length)
+		}
+
+		path := strings.TrimRight(string(pr.bytes(int(length))), "\x00")
+
+		f := File{ //nolint:exhaustruct
+			Path:   path,
+			Offset: int64(fileBase + pr.u64()), //nolint:gosec
+			Size:   int64(pr.u64()),            //nolint:gosec
+			MD5:    hex.EncodeToString(pr.bytes(16)),
+		}
+
+		if p.FormatVersion >= 2 { //nolint:gomnd
+			f.Flags = pr.u32()
+		}
+
+		if pr.err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidFormat, pr.err)
+		}
+
+		p.Files = append(p.Files, f)
+	}
+
+	return pr.err
+}
+
+/* ---------------------------- Function: locate ---------------------------- */
+
+// locate determines the offset of the pack header within 'r' and whether the
+// pack is embedded in an executable.
+func locate(r io.ReadSeeker) (int64, bool, error) {
+	pr := reader{r: r} //nolint:exhaustruct
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return 0, false, err
+	}
+
+	if pr.u32() == magic && pr.err == nil {
+		return 0, false, nil
+	}
+
+	// Embedded packs are followed by a trailer containing the pack size and
+	// the magic number: '| pack | size (u64) | magic (u32) |'.
+	end, err := r.Seek(-12, io.SeekEnd) //nolint:gomnd
+	if err != nil {
+		return 0, false, fmt.Errorf("%w: missing header", ErrInvalidFormat)
+	}
+
+	pr.err = nil
+
+	size := pr.u64()
+	if pr.u32() != magic || pr.err != nil {
+		return 0, false, fmt.Errorf("%w: missing header", ErrInvalidFormat)
+	}
+
+	start := end - int64(size) //nolint:gosec
+	if size > uint64(end) || start < 0 {
+		return 0, false, fmt.Errorf("%w: invalid embedded pack size: %d", ErrInvalidFormat, size)
+	}
+
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return 0, false, err
+	}
+
+	if pr.u32() != magic || pr.err != nil {
+		return 0, false, fmt.Errorf("%w: missing embedded pack header", ErrInvalidFormat)
+	}
+
+	return start, true, nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                               Struct: reader                               */
+/* -------------------------------------------------------------------------- */
+
+// reader is a little-endian binary reader which records the first error
+// encountered; subsequent reads return zero values.
+type reader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+
+	out := make([]byte, n)
+	if _, err := io.ReadFull(r.r, out); err != nil {
+		r.err = err
+
+		return nil
+	}
+
+	return out
+}
+
+func (r *reader) u32() uint32 {
+	b := r.bytes(4) //nolint:gomnd
+	if b == nil {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint32(b)
+}
+
+func (r *reader) u64() uint64 {
+	b := r.bytes(8) //nolint:gomnd
+	if b == nil {
+		return 0
+	}
+
+	return binary.LittleEndian.Uint64(b)
+}
@@ -0,0 +1,360 @@
+package pck_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5" //nolint:gosec
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/pkg/godot/pck"
+)
+
+func TestRead(t *testing.T) {
+	files := []entry{
+		{path: "res://project.binary", data: []byte("config")},
+		{path: "res://icon.png", data: []byte("image"), flags: pck.FileFlagEncrypted},
+	}
+
+	tests := []struct {
+		name string
+
+		version  uint32
+		flags    uint32
+		prefix   []byte
+		embedded bool
+
+		want []pck.File
+		err  error
+	}{
+		{
+			name: "version 1 pack is parsed",
+
+			version: 1,
+
+			want: []pck.File{
+				{Path: "res://project.binary", Offset: 0, Size: 6, MD5: md5Hex(0)},
+				{Path: "res://icon.png", Offset: 6, Size: 5, MD5: md5Hex(1)},
+			},
+		},
+		{
+			name: "version 2 pack with relative file base is parsed",
+
+			version: 2,
+			flags:   pck.FlagRelativeFileBase,
+
+			want: []pck.File{
+				{Path: "res://project.binary", Offset: 0, Size: 6, MD5: md5Hex(0)},
+				{Path: "res://icon.png", Offset: 6, Size: 5, MD5: md5Hex(1), Flags: pck.FileFlagEncrypted},
+			},
+		},
+		{
+			name: "version 3 pack with directory offset is parsed",
+
+			version: 3,
+			flags:   pck.FlagRelativeFileBase,
+
+			want: []pck.File{
+				{Path: "res://project.binary", Offset: 0, Size: 6, MD5: md5Hex(0)},
+				{Path: "res://icon.png", Offset: 6, Size: 5, MD5: md5Hex(1), Flags: pck.FileFlagEncrypted},
+			},
+		},
+		{
+			name: "embedded pack is located and parsed",
+
+			version:  2,
+			flags:    pck.FlagRelativeFileBase,
+			prefix:   []byte("\x7fELF executable contents"),
+			embedded: true,
+
+			want: []pck.File{
+				{Path: "res://project.binary", Offset: 0, Size: 6, MD5: md5Hex(0)},
+				{Path: "res://icon.png", Offset: 6, Size: 5, MD5: md5Hex(1), Flags: pck.FileFlagEncrypted},
+			},
+		},
+		{
+			name: "encrypted directory is reported without files",
+
+			version: 2,
+			flags:   pck.FlagDirectoryEncrypted | pck.FlagRelativeFileBase,
+
+			want: nil,
+		},
+		{
+			name: "unsupported version fails",
+
+			version: 9,
+
+			err: pck.ErrUnsupportedVersion,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A pack file with the specified contents.
+			data := encode(tc.version, tc.flags, files, nil)
+
+			if tc.embedded {
+				data = embed(tc.prefix, data)
+			}
+
+			// When: The pack file is parsed.
+			got, err := pck.Read(bytes.NewReader(data))
+
+			// Then: The resulting error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			// Then: The header matches expectations.
+			assert.Equal(t, tc.version, got.FormatVersion)
+			assert.Equal(t, pck.Version{Major: 4, Minor: 2, Patch: 1}, got.GodotVersion)
+			assert.Equal(t, tc.embedded, got.Embedded)
+			assert.Equal(t, tc.flags&pck.FlagDirectoryEncrypted != 0, got.IsDirectoryEncrypted())
+
+			// Then: File offsets are absolute positions of the file contents.
+			base := dataOffset(got, data, files)
+
+			want := make([]pck.File, 0, len(tc.want))
+			for _, f := range tc.want {
+				f.Offset += base
+				want = append(want, f)
+			}
+
+			if len(want) == 0 {
+				want = nil
+			}
+
+			assert.Equal(t, want, got.Files)
+
+			for i, f := range got.Files {
+				assert.Equal(t, files[i].data, data[f.Offset:f.Offset+f.Size])
+			}
+		})
+	}
+}
+
+func TestReadWithKey(t *testing.T) {
+	files := []entry{
+		{path: "res://project.binary", data: []byte("config"), flags: pck.FileFlagEncrypted},
+	}
+
+	key := bytes.Repeat([]byte{0x01}, pck.KeySize)
+
+	// Given: A pack file with an encrypted directory.
+	data := encode(2, pck.FlagDirectoryEncrypted|pck.FlagRelativeFileBase, files, key)
+
+	// When: The pack file is parsed with the correct key.
+	got, err := pck.ReadWithKey(bytes.NewReader(data), key)
+	require.NoError(t, err)
+
+	// Then: The directory is decrypted.
+	require.Len(t, got.Files, 1)
+	assert.Equal(t, "res://project.binary", got.Files[0].Path)
+	assert.True(t, got.Files[0].IsEncrypted())
+	assert.Equal(t, files[0].data, data[got.Files[0].Offset:got.Files[0].Offset+got.Files[0].Size])
+
+	// When: The pack file is parsed with an incorrect key.
+	_, err = pck.ReadWithKey(bytes.NewReader(data), bytes.Repeat([]byte{0x02}, pck.KeySize))
+
+	// Then: Decryption fails.
+	require.ErrorIs(t, err, pck.ErrDecryptionFailed)
+}
+
+func TestReadInvalid(t *testing.T) {
+	// Given: A file which is not a pack file.
+	data := []byte("not a pack file at all")
+
+	// When: The file is parsed.
+	_, err := pck.Read(bytes.NewReader(data))
+
+	// Then: The file is rejected.
+	require.ErrorIs(t, err, pck.ErrInvalidFormat)
+}
+
+/* ---------------------------- Function: helpers --------------------------- */
+
+type entry struct {
+	path  string
+	data  []byte
+	flags uint32
+}
+
+func md5Hex(i int) string {
+	return string(bytes.Repeat([]byte{'0' + byte(i)}, 32)) //nolint:gosec
+}
+
+// dataOffset returns the absolute position of the first file's contents.
+func dataOffset(p *pck.Pack, data []byte, files []entry) int64 {
+	if len(p.Files) == 0 {
+		return 0
+	}
+
+	return int64(bytes.Index(data[p.Offset:], files[0].data)) + p.Offset
+}
+
+// encode writes a pack file in the specified format version.
+func encode(version, flags uint32, files []entry, key []byte) []byte {
+	le := binary.LittleEndian
+
+	var contents bytes.Buffer
+	for _, f := range files {
+		contents.Write(f.data)
+	}
+
+	// headerLen is the size of the header, up to and including the reserved
+	// fields.
+	headerLen := 5 * 4
+	switch version {
+	case 2:
+		headerLen += 4 + 8
+	case 3:
+		headerLen += 4 + 8 + 8
+	}
+
+	headerLen += 16 * 4
+
+	count := uint32(len(files)) //nolint:gosec
+	dirLen := 4 + len(directory(version, files, 0))
+
+	var header bytes.Buffer
+
+	_ = binary.Write(&header, le, uint32(0x43504447))
+	_ = binary.Write(&header, le, version)
+	_ = binary.Write(&header, le, [3]uint32{4, 2, 1})
+
+	reserved := make([]byte, 16*4)
+
+	switch version {
+	case 1:
+		_ = binary.Write(&header, le, reserved)
+		_ = binary.Write(&header, le, count)
+		header.Write(directory(version, files, uint64(headerLen+dirLen))) //nolint:gosec
+		header.Write(contents.Bytes())
+	case 2:
+		// NOTE: Godot writes the file count in plaintext, even when the
+		// directory's entries are encrypted.
+		var dir bytes.Buffer
+
+		_ = binary.Write(&dir, le, count)
+
+		entries := directory(version, files, 0)
+		if flags&pck.FlagDirectoryEncrypted != 0 {
+			entries = encrypt(key, entries)
+		}
+
+		dir.Write(entries)
+
+		dirBytes := dir.Bytes()
+
+		_ = binary.Write(&header, le, flags)
+		_ = binary.Write(&header, le, uint64(headerLen+len(dirBytes))) //nolint:gosec
+		_ = binary.Write(&header, le, reserved)
+		header.Write(dirBytes)
+		header.Write(contents.Bytes())
+	case 3:
+		_ = binary.Write(&header, le, flags)
+		_ = binary.Write(&header, le, uint64(headerLen))                //nolint:gosec
+		_ = binary.Write(&header, le, uint64(headerLen+contents.Len())) //nolint:gosec
+		_ = binary.Write(&header, le, reserved)
+		header.Write(contents.Bytes())
+		_ = binary.Write(&header, le, count)
+		header.Write(directory(version, files, 0))
+	default:
+		_ = binary.Write(&header, le, reserved)
+	}
+
+	return header.Bytes()
+}
+
+// directory encodes the file entries of a pack file, with file offsets
+// starting at 'base'.
+func directory(version uint32, files []entry, base uint64) []byte {
+	le := binary.LittleEndian
+
+	var dir bytes.Buffer
+
+	offset := base
+
+	for i, f := range files {
+		path := []byte(f.path)
+		for len(path)%4 != 0 {
+			path = append(path, 0)
+		}
+
+		_ = binary.Write(&dir, le, uint32(len(path))) //nolint:gosec
+		dir.Write(path)
+		_ = binary.Write(&dir, le, offset)
+		_ = binary.Write(&dir, le, uint64(len(f.data)))
+
+		for range 16 {
+			dir.WriteByte(byte(i) * 0x11) //nolint:gosec
+		}
+
+		if version >= 2 {
+			_ = binary.Write(&dir, le, f.flags)
+		}
+
+		offset += uint64(len(f.data))
+	}
+
+	return dir.Bytes()
+}
+
+// encrypt encrypts 'data' in the format written by Godot's
+// 'FileAccessEncrypted' (without the magic number). If 'key' is nil, the
+// contents are zeroed instead.
+func encrypt(key, data []byte) []byte {
+	size := len(data)
+	if rem := size % aes.BlockSize; rem != 0 {
+		size += aes.BlockSize - rem
+	}
+
+	padded := make([]byte, size)
+
+	if key != nil {
+		copy(padded, data)
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			panic(err)
+		}
+
+		iv := bytes.Repeat([]byte{0x42}, aes.BlockSize)
+		cipher.NewCFBEncrypter(block, iv).XORKeyStream(padded, padded) //nolint:staticcheck
+
+		sum := md5.Sum(data) //nolint:gosec
+
+		var out bytes.Buffer
+
+		out.Write(sum[:])
+		_ = binary.Write(&out, binary.LittleEndian, uint64(len(data)))
+		out.Write(iv)
+		out.Write(padded)
+
+		return out.Bytes()
+	}
+
+	return make([]byte, md5.Size+8+aes.BlockSize+size)
+}
+
+// embed appends a pack file and its trailer to an executable.
+func embed(exe, pack []byte) []byte {
+	var out bytes.Buffer
+
+	out.Write(exe)
+	out.Write(pack)
+	_ = binary.Write(&out, binary.LittleEndian, uint64(len(pack)))
+	_ = binary.Write(&out, binary.LittleEndian, uint32(0x43504447))
+
+	return out.Bytes()
+}
@@ -26,6 +26,7 @@ const (
 	ArchArm64
 	ArchI386
 	ArchUniversal
+	ArchWasm32
 )
 
 /* ----------------------------- Method: IsOneOf ---------------------------- */
@@ -51,6 +52,8 @@ func (a Arch) String() string {
 		return "x86_32"
 	case ArchUniversal:
 		return "universal"
+	case ArchWasm32:
+		return "wasm32"
 	default:
 		return ""
 	}
@@ -71,10 +74,10 @@ func ParseArch(input string) (Arch, error) {
 	case "amd64", "x86_64", "x86-64":
 		return ArchAmd64, nil
 
-	case "arm32":
+	case "arm", "arm32", "armv7":
 		return ArchArm32, nil
 
-	case "arm64", "arm64be":
+	case "aarch64", "arm64", "arm64be":
 		return ArchArm64, nil
 
 	case "386", "i386", "x86", "x86_32":
@@ -83,6 +86,9 @@ func ParseArch(input string) (Arch, error) {
 	case "fat", "universal":
 		return ArchUniversal, nil
 
+	case "wasm", "wasm32":
+		return ArchWasm32, nil
+
 	default:
 		return 0, fmt.Errorf("%w: '%s'", ErrUnrecognizedArch, input)
 	}
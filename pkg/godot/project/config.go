@@ -0,0 +1,281 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/coffeebeats/gdbuild/pkg/godot/variant"
+)
+
+var ErrInvalidInput = errors.New("invalid input")
+
+/* -------------------------------------------------------------------------- */
+/*                             Struct: ConfigFile                             */
+/* -------------------------------------------------------------------------- */
+
+// ConfigFile is a parsed Godot 'ConfigFile' document (e.g. 'project.godot' or
+// 'export_presets.cfg'). Godot's format resembles 'ini', but values may span
+// multiple lines (e.g. strings with newlines and dictionaries), so each section
+// also retains its original lines.
+type ConfigFile struct {
+	// Sections are the file's sections, in order. Properties preceding the
+	// first section header are contained in a section with an empty name.
+	Sections []Section
+}
+
+/* ----------------------------- Method: Section ---------------------------- */
+
+// Section returns the first section with the specified name, if any.
+func (c *ConfigFile) Section(name string) *Section {
+	for i, s := range c.Sections {
+		if s.Name == name {
+			return &c.Sections[i]
+		}
+	}
+
+	return nil
+}
+
+/* ------------------------------- Method: Get ------------------------------ */
+
+// Get returns the raw value of the property 'key' within section 'section'.
+func (c *ConfigFile) Get(section, key string) (string, bool) {
+	s := c.Section(section)
+	if s == nil {
+		return "", false
+	}
+
+	return s.Get(key)
+}
+
+/* ----------------------------- Method: String ----------------------------- */
+
+// String returns the value of the property 'key' within section 'section' as
+// a string. An empty string is returned if the property isn't a string.
+func (c *ConfigFile) String(section, key string) string {
+	s := c.Section(section)
+	if s == nil {
+		return ""
+	}
+
+	return s.String(key)
+}
+
+/* ----------------------------- Method: Strings ---------------------------- */
+
+// Strings returns the value of the property 'key' within section 'section' as
+// a slice of strings. 'nil' is returned if the property isn't a string array.
+func (c *ConfigFile) Strings(section, key string) []string {
+	s := c.Section(section)
+	if s == nil {
+		return nil
+	}
+
+	return s.Strings(key)
+}
+
+/* -------------------------------------------------------------------------- */
+/*                               Struct: Section                              */
+/* -------------------------------------------------------------------------- */
+
+// Section is a single section of a 'ConfigFile'.
+type Section struct {
+	// Name is the name of the section, excluding brackets.
+	Name string
+	// Lines are the original lines of the section, excluding the header.
+	Lines []string
+	// Properties are the section's properties, in order.
+	Properties []Property
+}
+
+/* ------------------------------- Method: Get ------------------------------ */
+
+// Get returns the raw value of the property 'key', if present.
+func (s *Section) Get(key string) (string, bool) {
+	for _, p := range s.Properties {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+
+	return "", false
+}
+
+/* ------------------------------ Method: Value ----------------------------- */
+
+// Value decodes the value of the property 'key' (see 'variant.Decode'). 'nil'
+// is returned if the property isn't present.
+func (s *Section) Value(key string) (any, error) {
+	raw, ok := s.Get(key)
+	if !ok {
+		return nil, nil
+	}
+
+	v, err := variant.Decode(raw)
+	if err != nil {
+		return nil, fmt.Errorf("property '%s': %w", key, err)
+	}
+
+	return v, nil
+}
+
+/* ----------------------------- Method: String ----------------------------- */
+
+// String returns the value of the property 'key' as a string. An empty string
+// is returned if the property isn't a string.
+func (s *Section) String(key string) string {
+	v, _ := s.Value(key)
+
+	switch v := v.(type) {
+	case string:
+		return v
+	case variant.StringName:
+		return string(v)
+	default:
+		return ""
+	}
+}
+
+/* ----------------------------- Method: Strings ---------------------------- */
+
+// Strings returns the value of the property 'key' as a slice of strings. 'nil'
+// is returned if the property isn't a 'PackedStringArray' or an 'Array' of
+// strings.
+func (s *Section) Strings(key string) []string {
+	v, _ := s.Value(key)
+
+	switch v := v.(type) {
+	case []string:
+		return v
+	case []any:
+		out := make([]string, 0, len(v))
+
+		for _, e := range v {
+			s, ok := e.(string)
+			if !ok {
+				return nil
+			}
+
+			out = append(out, s)
+		}
+
+		return out
+	default:
+		return nil
+	}
+}
+
+/* ----------------------------- Struct: Property --------------------------- */
+
+// Property is a single 'key=value' entry within a 'Section'. 'Value' is the raw
+// (i.e. encoded) value, which can be decoded with 'variant.Decode'.
+type Property struct {
+	Key   string
+	Value string
+}
+
+/* -------------------------------------------------------------------------- */
+/*                          Function: ParseConfigFile                         */
+/* -------------------------------------------------------------------------- */
+
+// ParseConfigFile parses the contents of a Godot 'ConfigFile' document.
+func ParseConfigFile(data string) (*ConfigFile, error) {
+	cf := ConfigFile{Sections: []Section{{}}} //nolint:exhaustruct
+
+	var key string
+
+	var value strings.Builder
+
+	var inString bool
+
+	var depth int
+
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		current := &cf.Sections[len(cf.Sections)-1]
+
+		isContinued := inString || depth > 0
+
+		if !isContinued {
+			trimmed := strings.TrimSpace(line)
+
+			if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+				name := strings.TrimSuffix(strings.TrimPrefix(trimmed, "["), "]")
+				cf.Sections = append(cf.Sections, Section{Name: name}) //nolint:exhaustruct
+
+				continue
+			}
+		}
+
+		current.Lines = append(current.Lines, line)
+
+		if isContinued {
+			value.WriteString("\n" + line)
+		} else {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || strings.HasPrefix(trimmed, ";") {
+				continue
+			}
+
+			k, v, ok := strings.Cut(trimmed, "=")
+			if !ok {
+				return nil, fmt.Errorf("%w: expected a property: %s", ErrInvalidInput, trimmed)
+			}
+
+			key = strings.TrimSpace(k)
+
+			value.Reset()
+			value.WriteString(strings.TrimSpace(v))
+		}
+
+		if inString, depth = scanLine(line, inString, depth); !inString && depth <= 0 {
+			current.Properties = append(current.Properties, Property{Key: key, Value: value.String()})
+		}
+	}
+
+	if inString || depth > 0 {
+		return nil, fmt.Errorf("%w: unterminated value for property: %s", ErrInvalidInput, key)
+	}
+
+	// Omit an empty leading section.
+	if s := cf.Sections[0]; len(s.Properties) == 0 && strings.TrimSpace(strings.Join(s.Lines, "")) == "" {
+		cf.Sections = cf.Sections[1:]
+	}
+
+	return &cf, nil
+}
+
+/* --------------------------- Function: scanLine --------------------------- */
+
+// scanLine updates the state of an open value (i.e. whether a quoted string is
+// open and how many brackets are unclosed) after 'line'. Values are complete
+// once neither a string nor any brackets remain open.
+func scanLine(line string, inString bool, depth int) (bool, int) {
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ';':
+			return false, depth // The rest of the line is a comment.
+		}
+	}
+
+	return inString, depth
+}
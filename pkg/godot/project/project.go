@@ -0,0 +1,213 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/coffeebeats/gdenv/pkg/godot/version"
+
+	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+)
+
+const (
+	// FilenameProject is the name of a Godot project's manifest file.
+	FilenameProject = "project.godot"
+
+	// RenderingMethodForwardPlus is the default renderer of Godot 4 projects.
+	RenderingMethodForwardPlus = "forward_plus"
+
+	featureDotNet = "C#"
+)
+
+// Environment variables which expose project settings for interpolation (e.g.
+// '${GDBUILD_PROJECT_NAME}' within paths, export options, and hook commands).
+const (
+	EnvProjectDescription = "GDBUILD_PROJECT_DESCRIPTION"
+	EnvProjectName        = "GDBUILD_PROJECT_NAME"
+	EnvProjectVersion     = "GDBUILD_PROJECT_VERSION"
+)
+
+var ErrIncompatibleVersion = errors.New("incompatible Godot version")
+
+// featureVersion matches the engine version feature tag (e.g. '4.2') which the
+// editor adds to a project's 'config/features'.
+var featureVersion = regexp.MustCompile(`^\d+\.\d+$`)
+
+/* -------------------------------------------------------------------------- */
+/*                               Struct: Project                              */
+/* -------------------------------------------------------------------------- */
+
+// Project contains the settings of a Godot project relevant to 'gdbuild', as
+// read from its 'project.godot' file.
+type Project struct {
+	// Config is the parsed 'project.godot' file.
+	Config *ConfigFile
+
+	// Description is the project's description.
+	Description string
+	// Features is the list of feature tags saved by the editor in the
+	// 'application/config/features' setting (e.g. '4.2' and 'Forward Plus').
+	Features []string
+	// IsDotNet is whether this is a C# project.
+	IsDotNet bool
+	// Name is the name of the project.
+	Name string
+	// RenderingMethod is the project's renderer (e.g. 'forward_plus', 'mobile',
+	// or 'gl_compatibility').
+	RenderingMethod string
+	// Release is the project's own version (i.e. 'application/config/version').
+	Release string
+}
+
+/* ------------------------------ Function: Load ----------------------------- */
+
+// Load reads the Godot project located in the directory 'path'.
+func Load(path string) (*Project, error) {
+	bb, err := os.ReadFile(filepath.Join(path, FilenameProject))
+	if err != nil {
+		return nil, err
+	}
+
+	cf, err := ParseConfigFile(string(bb))
+	if err != nil {
+		return nil, err
+	}
+
+	p := Project{ //nolint:exhaustruct
+		Config:          cf,
+		Description:     cf.String("application", "config/description"),
+		Features:        cf.Strings("application", "config/features"),
+		Name:            cf.String("application", "config/name"),
+		Release:         cf.String("application", "config/version"),
+		RenderingMethod: cf.String("rendering", "renderer/rendering_method"),
+	}
+
+	if p.RenderingMethod == "" {
+		p.RenderingMethod = RenderingMethodForwardPlus
+	}
+
+	// NOTE: The editor adds a 'dotnet' section to C# projects, but check for a
+	// C# project file too in case the project hasn't been saved since.
+	matches, err := filepath.Glob(filepath.Join(path, "*.csproj"))
+	if err != nil {
+		return nil, err
+	}
+
+	p.IsDotNet = slices.Contains(p.Features, featureDotNet) ||
+		cf.Section("dotnet") != nil ||
+		len(matches) > 0
+
+	return &p, nil
+}
+
+/* ---------------------------- Method: Version ----------------------------- */
+
+// Version returns the Godot engine version (in 'major.minor' format) that the
+// project was last saved with, if known.
+func (p *Project) Version() string {
+	for _, f := range p.Features {
+		if featureVersion.MatchString(strings.TrimSpace(f)) {
+			return strings.TrimSpace(f)
+		}
+	}
+
+	return ""
+}
+
+/* ------------------------ Method: CheckEditorVersion ---------------------- */
+
+// CheckEditorVersion verifies that the Godot editor version 'ev' can export the
+// project. The editor must be at least as new as the version the project was
+// last saved with, and C# projects require a .NET-enabled editor.
+func (p *Project) CheckEditorVersion(ev engine.Version) error {
+	if p.IsDotNet && !version.Version(ev).IsMono() {
+		return fmt.Errorf(
+			"%w: C# projects require a .NET-enabled editor (e.g. '%s-%s'): %s",
+			ErrIncompatibleVersion,
+			version.Version(ev).Normal(),
+			version.LabelMono,
+			ev,
+		)
+	}
+
+	saved := p.Version()
+	if saved == "" {
+		return nil
+	}
+
+	pv, err := version.Parse(saved)
+	if err != nil {
+		return fmt.Errorf("%w: %w: %s", ErrInvalidInput, err, saved)
+	}
+
+	// NOTE: Only the major and minor versions are recorded in the project.
+	if version.Version(ev).CompareNormal(pv) < 0 {
+		return fmt.Errorf(
+			"%w: project was saved with Godot %s but the editor is older: %s",
+			ErrIncompatibleVersion,
+			saved,
+			ev,
+		)
+	}
+
+	return nil
+}
+
+/* ------------------------- Method: ImportSettings ------------------------- */
+
+// ImportSettings returns the project settings which affect how assets are
+// imported (e.g. importer defaults and texture compression formats), sorted and
+// formatted as 'section/key=value' using their raw values.
+func (p *Project) ImportSettings() []string {
+	if p.Config == nil {
+		return nil
+	}
+
+	var out []string
+
+	for _, s := range p.Config.Sections {
+		for _, prop := range s.Properties {
+			if !isImportSetting(s.Name, prop.Key) {
+				continue
+			}
+
+			out = append(out, s.Name+"/"+prop.Key+"="+prop.Value)
+		}
+	}
+
+	slices.Sort(out)
+
+	return out
+}
+
+/* ------------------------ Function: isImportSetting ----------------------- */
+
+func isImportSetting(section, key string) bool {
+	switch section {
+	case "importer_defaults":
+		return true
+	case "editor", "filesystem":
+		return strings.HasPrefix(key, "import/")
+	case "rendering":
+		return strings.HasPrefix(key, "textures/")
+	default:
+		return false
+	}
+}
+
+/* -------------------------- Method: Environment --------------------------- */
+
+// Environment returns the project's settings as a list of environment
+// variables (in 'KEY=VALUE' format) so that they can be interpolated.
+func (p *Project) Environment() []string {
+	return []string{
+		EnvProjectDescription + "=" + p.Description,
+		EnvProjectName + "=" + p.Name,
+		EnvProjectVersion + "=" + p.Release,
+	}
+}
@@ -0,0 +1,258 @@
+package project_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
+	"github.com/coffeebeats/gdbuild/pkg/godot/project"
+	"github.com/coffeebeats/gdbuild/pkg/godot/variant"
+)
+
+func TestParseConfigFile(t *testing.T) {
+	// Given: A 'ConfigFile' document with multi-line values.
+	data := `; Engine configuration file.
+
+config_version=5
+
+[application]
+
+config/name="My \"Game\""
+config/features=PackedStringArray("4.2", "Forward Plus")
+
+[editor_plugins]
+
+enabled=PackedStringArray(
+"res://addons/a/plugin.cfg",
+"res://addons/b/plugin.cfg"
+)
+
+[input]
+
+jump={
+"deadzone": 0.5,
+"events": []
+}
+`
+
+	// When: The document is parsed.
+	got, err := project.ParseConfigFile(data)
+
+	// Then: There's no error.
+	require.NoError(t, err)
+
+	// Then: The sections match expectations.
+	names := make([]string, 0, len(got.Sections))
+	for _, s := range got.Sections {
+		names = append(names, s.Name)
+	}
+
+	assert.Equal(t, []string{"", "application", "editor_plugins", "input"}, names)
+
+	// Then: Values are decoded correctly.
+	assert.Equal(t, `My "Game"`, got.String("application", "config/name"))
+	assert.Equal(t, []string{"4.2", "Forward Plus"}, got.Strings("application", "config/features"))
+	assert.Equal(t, []string{"res://addons/a/plugin.cfg", "res://addons/b/plugin.cfg"}, got.Strings("editor_plugins", "enabled"))
+
+	raw, ok := got.Get("input", "jump")
+	assert.True(t, ok)
+	assert.Equal(t, "{\n\"deadzone\": 0.5,\n\"events\": []\n}", raw)
+
+	jump, err := got.Section("input").Value("jump")
+	require.NoError(t, err)
+	assert.Equal(t, variant.Dictionary{{Key: "deadzone", Value: 0.5}, {Key: "events", Value: []any{}}}, jump)
+
+	version, err := got.Sections[0].Value("config_version")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), version)
+}
+
+func TestParseConfigFileUnterminated(t *testing.T) {
+	// Given: A document with an unterminated string.
+	data := "[application]\nconfig/name=\"My Game\n"
+
+	// When: The document is parsed.
+	_, err := project.ParseConfigFile(data)
+
+	// Then: The expected error is returned.
+	assert.ErrorIs(t, err, project.ErrInvalidInput)
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name string
+
+		project string
+		files   []string
+
+		want project.Project
+	}{
+		{
+			name: "gdscript project is detected",
+
+			project: `[application]
+
+config/name="Game"
+config/description="A game."
+config/version="1.2.0"
+config/features=PackedStringArray("4.3", "GL Compatibility")
+
+[rendering]
+
+renderer/rendering_method="gl_compatibility"
+`,
+
+			want: project.Project{ //nolint:exhaustruct
+				Description:     "A game.",
+				Features:        []string{"4.3", "GL Compatibility"},
+				Name:            "Game",
+				Release:         "1.2.0",
+				RenderingMethod: "gl_compatibility",
+			},
+		},
+		{
+			name: "c# project is detected from features",
+
+			project: `[application]
+
+config/name="Game"
+config/features=PackedStringArray("4.2", "C#", "Forward Plus")
+`,
+
+			want: project.Project{ //nolint:exhaustruct
+				Features:        []string{"4.2", "C#", "Forward Plus"},
+				IsDotNet:        true,
+				Name:            "Game",
+				RenderingMethod: project.RenderingMethodForwardPlus,
+			},
+		},
+		{
+			name: "c# project is detected from project file",
+
+			project: "[application]\n\nconfig/name=\"Game\"\n",
+			files:   []string{"Game.csproj"},
+
+			want: project.Project{ //nolint:exhaustruct
+				IsDotNet:        true,
+				Name:            "Game",
+				RenderingMethod: project.RenderingMethodForwardPlus,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A Godot project directory.
+			root := t.TempDir()
+
+			require.NoError(t, os.WriteFile(filepath.Join(root, project.FilenameProject), []byte(tc.project), 0o600))
+
+			for _, f := range tc.files {
+				require.NoError(t, os.WriteFile(filepath.Join(root, f), nil, 0o600))
+			}
+
+			// When: The project is loaded.
+			got, err := project.Load(root)
+
+			// Then: There's no error.
+			require.NoError(t, err)
+
+			// Then: The project matches expectations.
+			got.Config = nil
+			assert.Equal(t, tc.want, *got)
+		})
+	}
+}
+
+func TestProjectCheckEditorVersion(t *testing.T) {
+	tests := []struct {
+		name string
+
+		features []string
+		isDotNet bool
+		editor   string
+
+		err error
+	}{
+		{name: "unknown project version is allowed", editor: "4.2-stable"},
+		{name: "same version is allowed", features: []string{"4.2"}, editor: "4.2.1-stable"},
+		{name: "newer editor is allowed", features: []string{"4.2"}, editor: "4.3-stable"},
+		{
+			name:     "older editor returns an error",
+			features: []string{"4.3", "Forward Plus"},
+			editor:   "4.2.2-stable",
+			err:      project.ErrIncompatibleVersion,
+		},
+		{
+			name:     "c# project with .NET editor is allowed",
+			features: []string{"4.2", "C#"},
+			isDotNet: true,
+			editor:   "4.2.1-stable_mono",
+		},
+		{
+			name:     "c# project without .NET editor returns an error",
+			features: []string{"4.2", "C#"},
+			isDotNet: true,
+			editor:   "4.2.1-stable",
+			err:      project.ErrIncompatibleVersion,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given: A Godot project.
+			p := project.Project{Features: tc.features, IsDotNet: tc.isDotNet} //nolint:exhaustruct
+
+			// Given: A Godot editor version.
+			var ev engine.Version
+			require.NoError(t, ev.UnmarshalText([]byte(tc.editor)))
+
+			// When: The editor version is checked.
+			err := p.CheckEditorVersion(ev)
+
+			// Then: The returned error matches expectations.
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestProjectImportSettings(t *testing.T) {
+	// Given: A Godot project with import-related and unrelated settings.
+	cf, err := project.ParseConfigFile(`[application]
+
+config/name="Game"
+
+[editor]
+
+run/main_run_args=""
+import/use_multiple_threads=false
+
+[importer_defaults]
+
+texture={
+"compress/mode": 2
+}
+
+[rendering]
+
+renderer/rendering_method="mobile"
+textures/vram_compression/import_etc2_astc=true
+`)
+	require.NoError(t, err)
+
+	p := project.Project{Config: cf} //nolint:exhaustruct
+
+	// When: The project's import settings are determined.
+	got := p.ImportSettings()
+
+	// Then: Only the import-related settings are returned, in sorted order.
+	assert.Equal(t, []string{
+		"editor/import/use_multiple_threads=false",
+		"importer_defaults/texture={\n\"compress/mode\": 2\n}",
+		"rendering/textures/vram_compression/import_etc2_astc=true",
+	}, got)
+}
@@ -49,6 +49,11 @@ type Build struct {
 	// setting it externally (i.e. via the 'target' command).
 	EncryptionKey string
 
+	// ExtraSuffix is an optional suffix appended to the name of the compiled
+	// artifact (e.g. '.nothreads'). This must match the suffix which SCons
+	// adds for the platform-specific build options.
+	ExtraSuffix string `hash:"ignore"` // Ignore; derived from 'SCons' arguments.
+
 	// Env is a map of environment variables to set during the build step.
 	Env map[string]string
 
@@ -68,13 +73,6 @@ type Build struct {
 	SCons SCons
 }
 
-/* --------------------- Function: EncryptionKeyFromEnv --------------------- */
-
-// EncryptionKeyFromEnv returns the encryption key set via environment variable.
-func EncryptionKeyFromEnv() string {
-	return os.Getenv(envEncryptionKey)
-}
-
 /* ---------------------------- Method: Basename ---------------------------- */
 
 // Basename returns the base name of the compiled engine artifact generated by
@@ -82,7 +80,13 @@ func EncryptionKeyFromEnv() string {
 func (b *Build) Basename(rc *run.Context) string {
 	var name strings.Builder
 
-	name.WriteString("godot")
+	// NOTE: iOS templates are compiled as static libraries.
+	if rc.Platform == platform.OSIOS {
+		name.WriteString("libgodot")
+	} else {
+		name.WriteString("godot")
+	}
+
 	name.WriteString("." + rc.Platform.String())
 	name.WriteString("." + rc.Profile.TargetName())
 
@@ -91,8 +95,14 @@ func (b *Build) Basename(rc *run.Context) string {
 	}
 
 	name.WriteString("." + b.Arch.String())
-
-	if rc.Platform == platform.OSWindows {
+	name.WriteString(b.ExtraSuffix)
+
+	switch rc.Platform { //nolint:exhaustive
+	case platform.OSIOS:
+		name.WriteString(".a")
+	case platform.OSWeb:
+		name.WriteString(".zip")
+	case platform.OSWindows:
 		name.WriteString(".exe")
 	}
 
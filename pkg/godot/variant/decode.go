@@ -0,0 +1,824 @@
+package variant
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                              Function: Decode                              */
+/* -------------------------------------------------------------------------- */
+
+// Decode parses a single value written in Godot's text 'Variant' format (e.g.
+// the value of a property in a 'ConfigFile'). Values are decoded into Go types
+// as follows:
+//
+//	null                       -> nil
+//	bool, int, float           -> bool, int64, float64
+//	String                     -> string
+//	StringName, NodePath       -> StringName, NodePath
+//	Array                      -> []any (or 'TypedArray' if typed)
+//	Dictionary                 -> Dictionary (or 'TypedDictionary' if typed)
+//	PackedByteArray            -> []byte
+//	PackedInt32Array           -> []int32
+//	PackedInt64Array           -> []int64
+//	PackedFloat32Array         -> []float32
+//	PackedFloat64Array         -> []float64
+//	PackedStringArray          -> []string
+//	PackedVector2Array         -> []Vector2
+//	PackedVector3Array         -> []Vector3
+//	PackedColorArray           -> []Color
+//	Vector2, Vector2i          -> Vector2, Vector2i
+//	Vector3, Vector3i          -> Vector3, Vector3i
+//	Color                      -> Color
+//	Object(...)                -> Object
+//	ExtResource, SubResource   -> Reference (as well as 'Resource')
+//
+// Any other constructor (e.g. 'Transform3D(...)') is decoded into a generic
+// 'Constructor' so that it can be re-encoded without loss.
+func Decode(raw string) (any, error) {
+	d := decoder{input: raw, pos: 0}
+
+	value, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+
+	if d.skipSpace(); d.pos < len(d.input) {
+		return nil, d.errorf("unexpected trailing input")
+	}
+
+	return value, nil
+}
+
+/* -------------------------------------------------------------------------- */
+/*                              Struct: decoder                               */
+/* -------------------------------------------------------------------------- */
+
+// decoder is a recursive-descent parser over a single encoded value.
+type decoder struct {
+	input string
+	pos   int
+}
+
+/* ----------------------------- Method: errorf ----------------------------- */
+
+func (d *decoder) errorf(format string, args ...any) error {
+	return fmt.Errorf(
+		"%w: %s (at offset %d): %s",
+		ErrInvalidInput,
+		fmt.Sprintf(format, args...),
+		d.pos,
+		d.input,
+	)
+}
+
+/* ---------------------------- Method: skipSpace --------------------------- */
+
+// skipSpace advances past whitespace and comments (which start with ';' and
+// continue to the end of the line).
+func (d *decoder) skipSpace() {
+	for d.pos < len(d.input) {
+		switch c := d.input[d.pos]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			d.pos++
+		case c == ';':
+			if i := strings.IndexByte(d.input[d.pos:], '\n'); i >= 0 {
+				d.pos += i
+			} else {
+				d.pos = len(d.input)
+			}
+		default:
+			return
+		}
+	}
+}
+
+/* ----------------------------- Method: consume ---------------------------- */
+
+// consume advances past the next non-space character if it equals 'c'.
+func (d *decoder) consume(c byte) bool {
+	d.skipSpace()
+
+	if d.pos < len(d.input) && d.input[d.pos] == c {
+		d.pos++
+
+		return true
+	}
+
+	return false
+}
+
+/* ----------------------------- Method: expect ----------------------------- */
+
+func (d *decoder) expect(c byte) error {
+	if !d.consume(c) {
+		return d.errorf("expected '%c'", c)
+	}
+
+	return nil
+}
+
+/* ------------------------------ Method: value ----------------------------- */
+
+func (d *decoder) value() (any, error) { //nolint:cyclop
+	d.skipSpace()
+
+	if d.pos >= len(d.input) {
+		return nil, d.errorf("unexpected end of input")
+	}
+
+	switch c := d.input[d.pos]; {
+	case c == '"':
+		return d.string()
+	case c == '&':
+		d.pos++
+
+		s, err := d.string()
+
+		return StringName(s), err
+	case c == '^':
+		d.pos++
+
+		s, err := d.string()
+
+		return NodePath(s), err
+	case c == '[':
+		d.pos++
+
+		return d.array()
+	case c == '{':
+		d.pos++
+
+		return d.dictionary()
+	case c == '-' || c == '+' || c == '.' || isDigit(c):
+		return d.number()
+	case isIdentStart(c):
+		return d.identifier()
+	default:
+		return nil, d.errorf("unexpected character '%c'", c)
+	}
+}
+
+/* ----------------------------- Method: string ----------------------------- */
+
+func (d *decoder) string() (string, error) { //nolint:cyclop,funlen
+	if err := d.expect('"'); err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+
+	for d.pos < len(d.input) {
+		c := d.input[d.pos]
+		d.pos++
+
+		switch c {
+		case '"':
+			return out.String(), nil
+		case '\\':
+		default:
+			out.WriteByte(c)
+
+			continue
+		}
+
+		if d.pos >= len(d.input) {
+			break
+		}
+
+		e := d.input[d.pos]
+		d.pos++
+
+		switch e {
+		case 'b':
+			out.WriteByte('\b')
+		case 't':
+			out.WriteByte('\t')
+		case 'n':
+			out.WriteByte('\n')
+		case 'f':
+			out.WriteByte('\f')
+		case 'r':
+			out.WriteByte('\r')
+		case 'u', 'U':
+			size := 4
+			if e == 'U' {
+				size = 6
+			}
+
+			r, err := d.hex(size)
+			if err != nil {
+				return "", err
+			}
+
+			// NOTE: Characters outside the BMP may be written as a UTF-16
+			// surrogate pair.
+			if utf16.IsSurrogate(r) && strings.HasPrefix(d.input[d.pos:], `\u`) {
+				d.pos += 2
+
+				low, err := d.hex(4) //nolint:gomnd
+				if err != nil {
+					return "", err
+				}
+
+				r = utf16.DecodeRune(r, low)
+			}
+
+			if !utf8.ValidRune(r) {
+				r = utf8.RuneError
+			}
+
+			out.WriteRune(r)
+		default: // Includes quotes and backslashes.
+			out.WriteByte(e)
+		}
+	}
+
+	return "", d.errorf("unterminated string")
+}
+
+/* ------------------------------- Method: hex ------------------------------ */
+
+func (d *decoder) hex(size int) (rune, error) {
+	if d.pos+size > len(d.input) {
+		return 0, d.errorf("invalid escape sequence")
+	}
+
+	n, err := strconv.ParseUint(d.input[d.pos:d.pos+size], 16, 32)
+	if err != nil {
+		return 0, d.errorf("invalid escape sequence")
+	}
+
+	d.pos += size
+
+	return rune(n), nil
+}
+
+/* ----------------------------- Method: number ----------------------------- */
+
+func (d *decoder) number() (any, error) { //nolint:cyclop
+	start := d.pos
+
+	if c := d.input[d.pos]; c == '-' || c == '+' {
+		d.pos++
+	}
+
+	// Support signed constants (e.g. '-inf').
+	if d.pos < len(d.input) && isIdentStart(d.input[d.pos]) {
+		v, err := d.identifier()
+		if err != nil {
+			return nil, err
+		}
+
+		f, ok := v.(float64)
+		if !ok {
+			return nil, d.errorf("invalid number")
+		}
+
+		if d.input[start] == '-' {
+			return -f, nil
+		}
+
+		return f, nil
+	}
+
+	isHex := strings.HasPrefix(d.input[d.pos:], "0x") || strings.HasPrefix(d.input[d.pos:], "0X")
+	if isHex {
+		d.pos += 2
+	}
+
+	isFloat := false
+
+	for d.pos < len(d.input) {
+		c := d.input[d.pos]
+
+		switch {
+		case isDigit(c) || c == '_':
+		case isHex && strings.IndexByte("abcdefABCDEF", c) >= 0:
+		case !isHex && (c == '.' || c == 'e' || c == 'E'):
+			isFloat = true
+		case !isHex && (c == '-' || c == '+') && (d.input[d.pos-1] == 'e' || d.input[d.pos-1] == 'E'):
+		default:
+			return d.parseNumber(d.input[start:d.pos], isHex, isFloat)
+		}
+
+		d.pos++
+	}
+
+	return d.parseNumber(d.input[start:d.pos], isHex, isFloat)
+}
+
+/* --------------------------- Method: parseNumber -------------------------- */
+
+func (d *decoder) parseNumber(s string, isHex, isFloat bool) (any, error) {
+	s = strings.ReplaceAll(s, "_", "")
+
+	switch {
+	case isHex:
+		sign := ""
+		if s[0] == '-' || s[0] == '+' {
+			sign, s = s[:1], s[1:]
+		}
+
+		i, err := strconv.ParseInt(sign+s[2:], 16, 64)
+		if err != nil {
+			return nil, d.errorf("invalid number '%s'", s)
+		}
+
+		return i, nil
+	case isFloat:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, d.errorf("invalid number '%s'", s)
+		}
+
+		return f, nil
+	default:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, d.errorf("invalid number '%s'", s)
+		}
+
+		return i, nil
+	}
+}
+
+/* ------------------------------ Method: array ----------------------------- */
+
+// array parses the elements of an 'Array', following its opening bracket.
+func (d *decoder) array() ([]any, error) {
+	out := []any{}
+
+	for !d.consume(']') {
+		if len(out) > 0 {
+			if err := d.expect(','); err != nil {
+				return nil, err
+			}
+
+			// Allow a trailing comma.
+			if d.consume(']') {
+				break
+			}
+		}
+
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, v)
+	}
+
+	return out, nil
+}
+
+/* --------------------------- Method: dictionary --------------------------- */
+
+// dictionary parses the entries of a 'Dictionary', following its opening
+// brace.
+func (d *decoder) dictionary() (Dictionary, error) {
+	out := Dictionary{}
+
+	for !d.consume('}') {
+		if len(out) > 0 {
+			if err := d.expect(','); err != nil {
+				return nil, err
+			}
+
+			// Allow a trailing comma.
+			if d.consume('}') {
+				break
+			}
+		}
+
+		k, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := d.expect(':'); err != nil {
+			return nil, err
+		}
+
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, Entry{Key: k, Value: v})
+	}
+
+	return out, nil
+}
+
+/* --------------------------- Method: identifier --------------------------- */
+
+func (d *decoder) identifier() (any, error) {
+	start := d.pos
+
+	for d.pos < len(d.input) && (isIdentStart(d.input[d.pos]) || isDigit(d.input[d.pos])) {
+		d.pos++
+	}
+
+	name := d.input[start:d.pos]
+
+	switch name {
+	case "null", "nil":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "inf":
+		return math.Inf(1), nil
+	case "inf_neg":
+		return math.Inf(-1), nil
+	case "nan":
+		return math.NaN(), nil
+	}
+
+	if (name == nameArray || name == nameDictionary) && d.consume('[') {
+		return d.typed(name)
+	}
+
+	if err := d.expect('('); err != nil {
+		return nil, err
+	}
+
+	return d.constructor(name)
+}
+
+/* ------------------------------ Method: typed ----------------------------- */
+
+// typed parses a typed 'Array' or 'Dictionary' (e.g. 'Array[int]([1, 2])'),
+// following the opening bracket of its type.
+func (d *decoder) typed(name string) (any, error) {
+	start := d.pos
+
+	// NOTE: Types may contain brackets and strings (e.g. 'ExtResource("1")'),
+	// so find the matching bracket.
+	for depth := 1; depth > 0; d.pos++ {
+		if d.pos >= len(d.input) {
+			return nil, d.errorf("unterminated type")
+		}
+
+		switch d.input[d.pos] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '"':
+			if _, err := d.string(); err != nil {
+				return nil, err
+			}
+
+			d.pos-- // Offset the increment.
+		}
+	}
+
+	typ := strings.TrimSpace(d.input[start : d.pos-1])
+
+	if err := d.expect('('); err != nil {
+		return nil, err
+	}
+
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.expect(')'); err != nil {
+		return nil, err
+	}
+
+	if name == nameArray {
+		elements, ok := v.([]any)
+		if !ok {
+			return nil, d.errorf("expected an array")
+		}
+
+		return TypedArray{Type: typ, Elements: elements}, nil
+	}
+
+	entries, ok := v.(Dictionary)
+	if !ok {
+		return nil, d.errorf("expected a dictionary")
+	}
+
+	keyType, valueType, _ := cutTopLevel(typ, ',')
+
+	return TypedDictionary{
+		KeyType:   strings.TrimSpace(keyType),
+		ValueType: strings.TrimSpace(valueType),
+		Entries:   entries,
+	}, nil
+}
+
+/* --------------------------- Method: constructor -------------------------- */
+
+// constructor parses the arguments of the constructor 'name', following its
+// opening parenthesis.
+func (d *decoder) constructor(name string) (any, error) { //nolint:cyclop,funlen
+	if name == nameObject {
+		return d.object()
+	}
+
+	var args []any
+
+	for !d.consume(')') {
+		if len(args) > 0 {
+			if err := d.expect(','); err != nil {
+				return nil, err
+			}
+		}
+
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, v)
+	}
+
+	switch name {
+	case "ExtResource", "SubResource", "Resource":
+		if len(args) != 1 {
+			return nil, d.errorf("expected 1 argument to '%s'", name)
+		}
+
+		switch id := args[0].(type) {
+		case string:
+			return Reference{Kind: name, ID: id}, nil
+		case int64: // Godot 3 uses integer IDs.
+			return Reference{Kind: name, ID: strconv.FormatInt(id, 10)}, nil
+		default:
+			return nil, d.errorf("invalid argument to '%s'", name)
+		}
+
+	case nameNodePath, "StringName":
+		if len(args) != 1 {
+			return nil, d.errorf("expected 1 argument to '%s'", name)
+		}
+
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, d.errorf("invalid argument to '%s'", name)
+		}
+
+		if name == nameNodePath {
+			return NodePath(s), nil
+		}
+
+		return StringName(s), nil
+
+	case namePackedStringArray:
+		return convertEach(d, args, func(v any) (string, bool) {
+			s, ok := v.(string)
+
+			return s, ok
+		})
+
+	case namePackedByteArray:
+		// NOTE: Godot 4.3+ may write byte arrays as a base64-encoded string.
+		if len(args) == 1 {
+			if s, ok := args[0].(string); ok {
+				bb, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return nil, d.errorf("invalid base64 data: %s", err)
+				}
+
+				return bb, nil
+			}
+		}
+
+		return convertEach(d, args, func(v any) (byte, bool) {
+			i, ok := v.(int64)
+
+			return byte(i), ok && i >= 0 && i <= math.MaxUint8
+		})
+
+	case namePackedInt32Array:
+		return convertEach(d, args, func(v any) (int32, bool) {
+			i, ok := v.(int64)
+
+			return int32(i), ok && i >= math.MinInt32 && i <= math.MaxInt32
+		})
+
+	case namePackedInt64Array:
+		return convertEach(d, args, func(v any) (int64, bool) {
+			i, ok := v.(int64)
+
+			return i, ok
+		})
+
+	case namePackedFloat32Array:
+		return convertEach(d, args, func(v any) (float32, bool) {
+			f, ok := toFloat(v)
+
+			return float32(f), ok
+		})
+
+	case namePackedFloat64Array:
+		return convertEach(d, args, toFloat)
+
+	case namePackedVector2Array:
+		return convertGroups(d, args, 2, func(f []float64) Vector2 { //nolint:gomnd
+			return Vector2{X: f[0], Y: f[1]}
+		})
+
+	case namePackedVector3Array:
+		return convertGroups(d, args, 3, func(f []float64) Vector3 { //nolint:gomnd
+			return Vector3{X: f[0], Y: f[1], Z: f[2]}
+		})
+
+	case namePackedColorArray:
+		return convertGroups(d, args, 4, func(f []float64) Color { //nolint:gomnd
+			return Color{R: f[0], G: f[1], B: f[2], A: f[3]}
+		})
+
+	case nameVector2, nameVector3, nameColor:
+		size := map[string]int{nameVector2: 2, nameVector3: 3, nameColor: 4}[name] //nolint:gomnd
+		if len(args) != size {
+			return nil, d.errorf("expected %d arguments to '%s'", size, name)
+		}
+
+		v, err := convertGroups(d, args, size, func(f []float64) any {
+			switch name {
+			case nameVector2:
+				return Vector2{X: f[0], Y: f[1]}
+			case nameVector3:
+				return Vector3{X: f[0], Y: f[1], Z: f[2]}
+			default:
+				return Color{R: f[0], G: f[1], B: f[2], A: f[3]}
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return v[0], nil
+
+	case nameVector2i, nameVector3i:
+		size := map[string]int{nameVector2i: 2, nameVector3i: 3}[name] //nolint:gomnd
+		if len(args) != size {
+			return nil, d.errorf("expected %d arguments to '%s'", size, name)
+		}
+
+		ii, err := convertEach(d, args, func(v any) (int64, bool) {
+			i, ok := v.(int64)
+
+			return i, ok
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if name == nameVector2i {
+			return Vector2i{X: ii[0], Y: ii[1]}, nil
+		}
+
+		return Vector3i{X: ii[0], Y: ii[1], Z: ii[2]}, nil
+	}
+
+	return Constructor{Name: name, Args: args}, nil
+}
+
+/* ------------------------------ Method: object ---------------------------- */
+
+// object parses an inline 'Object' (e.g. 'Object(Class,"key":value)'),
+// following its opening parenthesis.
+func (d *decoder) object() (Object, error) {
+	d.skipSpace()
+
+	start := d.pos
+
+	for d.pos < len(d.input) && (isIdentStart(d.input[d.pos]) || isDigit(d.input[d.pos])) {
+		d.pos++
+	}
+
+	out := Object{Class: d.input[start:d.pos], Properties: Dictionary{}}
+	if out.Class == "" {
+		return Object{}, d.errorf("expected an object class")
+	}
+
+	for !d.consume(')') {
+		if err := d.expect(','); err != nil {
+			return Object{}, err
+		}
+
+		k, err := d.string()
+		if err != nil {
+			return Object{}, err
+		}
+
+		if err := d.expect(':'); err != nil {
+			return Object{}, err
+		}
+
+		v, err := d.value()
+		if err != nil {
+			return Object{}, err
+		}
+
+		out.Properties = append(out.Properties, Entry{Key: k, Value: v})
+	}
+
+	return out, nil
+}
+
+/* -------------------------- Function: convertEach ------------------------- */
+
+func convertEach[T any](d *decoder, args []any, fn func(any) (T, bool)) ([]T, error) {
+	out := make([]T, len(args))
+
+	for i, a := range args {
+		v, ok := fn(a)
+		if !ok {
+			return nil, d.errorf("invalid element: %v", a)
+		}
+
+		out[i] = v
+	}
+
+	return out, nil
+}
+
+/* ------------------------- Function: convertGroups ------------------------ */
+
+// convertGroups converts a flattened list of floats into a list of values
+// (e.g. vectors) each made up of 'size' components.
+func convertGroups[T any](d *decoder, args []any, size int, fn func([]float64) T) ([]T, error) {
+	if len(args)%size != 0 {
+		return nil, d.errorf("expected a multiple of %d elements", size)
+	}
+
+	ff, err := convertEach(d, args, toFloat)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]T, 0, len(ff)/size)
+
+	for i := 0; i < len(ff); i += size {
+		out = append(out, fn(ff[i:i+size]))
+	}
+
+	return out, nil
+}
+
+/* --------------------------- Function: toFloat ---------------------------- */
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+/* ------------------------- Function: cutTopLevel -------------------------- */
+
+// cutTopLevel slices 's' around the first instance of 'sep' which isn't nested
+// within brackets, parentheses, or a string.
+func cutTopLevel(s string, sep byte) (string, string, bool) {
+	depth, inString := 0, false
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == sep && depth == 0:
+			return s[:i], s[i+1:], true
+		}
+	}
+
+	return s, "", false
+}
+
+/* ------------------------- Function: isIdentStart ------------------------- */
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+/* --------------------------- Function: isDigit ---------------------------- */
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,361 @@
+package variant
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                              Function: Encode                              */
+/* -------------------------------------------------------------------------- */
+
+// Encode serializes a value into Godot's text 'Variant' format. In addition to
+// the types produced by 'Decode', any Go integer or float type is supported,
+// as are slices (written as an 'Array') and maps with string keys (written as
+// a 'Dictionary' with sorted keys). Values are always written on a single
+// line.
+func Encode(value any) (string, error) {
+	var out strings.Builder
+
+	if err := encode(&out, value); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+/* ----------------------------- Function: encode --------------------------- */
+
+func encode(out *strings.Builder, value any) error { //nolint:cyclop,funlen,gocyclo
+	switch value := value.(type) {
+	case nil:
+		out.WriteString("null")
+	case bool:
+		out.WriteString(strconv.FormatBool(value))
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		fmt.Fprintf(out, "%d", value)
+	case float32:
+		out.WriteString(formatFloat(float64(value)))
+	case float64:
+		out.WriteString(formatFloat(value))
+	case string:
+		out.WriteString(quote(value))
+	case StringName:
+		out.WriteString("&" + quote(string(value)))
+	case NodePath:
+		out.WriteString(nameNodePath + "(" + quote(string(value)) + ")")
+
+	case Vector2:
+		writeConstructor(out, nameVector2, value.X, value.Y)
+	case Vector2i:
+		fmt.Fprintf(out, "%s(%d, %d)", nameVector2i, value.X, value.Y)
+	case Vector3:
+		writeConstructor(out, nameVector3, value.X, value.Y, value.Z)
+	case Vector3i:
+		fmt.Fprintf(out, "%s(%d, %d, %d)", nameVector3i, value.X, value.Y, value.Z)
+	case Color:
+		writeConstructor(out, nameColor, value.R, value.G, value.B, value.A)
+
+	case []byte:
+		writePacked(out, namePackedByteArray, value, func(b byte) string {
+			return strconv.Itoa(int(b))
+		})
+	case []int32:
+		writePacked(out, namePackedInt32Array, value, func(i int32) string {
+			return strconv.FormatInt(int64(i), 10)
+		})
+	case []int64:
+		writePacked(out, namePackedInt64Array, value, func(i int64) string {
+			return strconv.FormatInt(i, 10)
+		})
+	case []float32:
+		writePacked(out, namePackedFloat32Array, value, func(f float32) string {
+			return formatComponent(float64(f))
+		})
+	case []float64:
+		writePacked(out, namePackedFloat64Array, value, formatComponent)
+	case []string:
+		writePacked(out, namePackedStringArray, value, quote)
+	case []Vector2:
+		writePacked(out, namePackedVector2Array, value, func(v Vector2) string {
+			return formatComponents(v.X, v.Y)
+		})
+	case []Vector3:
+		writePacked(out, namePackedVector3Array, value, func(v Vector3) string {
+			return formatComponents(v.X, v.Y, v.Z)
+		})
+	case []Color:
+		writePacked(out, namePackedColorArray, value, func(c Color) string {
+			return formatComponents(c.R, c.G, c.B, c.A)
+		})
+
+	case []any:
+		return writeArray(out, value)
+	case TypedArray:
+		out.WriteString(nameArray + "[" + value.Type + "](")
+
+		if err := writeArray(out, value.Elements); err != nil {
+			return err
+		}
+
+		out.WriteString(")")
+	case Dictionary:
+		return writeDictionary(out, value)
+	case TypedDictionary:
+		out.WriteString(nameDictionary + "[" + value.KeyType + ", " + value.ValueType + "](")
+
+		if err := writeDictionary(out, value.Entries); err != nil {
+			return err
+		}
+
+		out.WriteString(")")
+	case map[string]any:
+		return writeDictionary(out, sortedEntries(value))
+
+	case Object:
+		out.WriteString(nameObject + "(" + value.Class)
+
+		for _, e := range value.Properties {
+			k, ok := e.Key.(string)
+			if !ok {
+				return fmt.Errorf("%w: object property name: %T", ErrUnsupportedType, e.Key)
+			}
+
+			out.WriteString("," + quote(k) + ":")
+
+			if err := encode(out, e.Value); err != nil {
+				return err
+			}
+		}
+
+		out.WriteString(")")
+	case Reference:
+		out.WriteString(value.Kind + "(" + quote(value.ID) + ")")
+	case Constructor:
+		out.WriteString(value.Name + "(")
+
+		for i, a := range value.Args {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+
+			if err := encodeComponent(out, a); err != nil {
+				return err
+			}
+		}
+
+		out.WriteString(")")
+
+	default:
+		return encodeReflect(out, value)
+	}
+
+	return nil
+}
+
+/* ------------------------- Function: encodeReflect ------------------------ */
+
+// encodeReflect encodes slices and maps (with string keys) of other types.
+func encodeReflect(out *strings.Builder, value any) error {
+	v := reflect.ValueOf(value)
+
+	switch {
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		elements := make([]any, v.Len())
+		for i := range elements {
+			elements[i] = v.Index(i).Interface()
+		}
+
+		return writeArray(out, elements)
+
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		table := make(map[string]any, v.Len())
+		for _, k := range v.MapKeys() {
+			table[k.String()] = v.MapIndex(k).Interface()
+		}
+
+		return writeDictionary(out, sortedEntries(table))
+	}
+
+	return fmt.Errorf("%w: %T", ErrUnsupportedType, value)
+}
+
+/* ------------------------ Function: encodeComponent ----------------------- */
+
+// encodeComponent encodes a constructor argument, which Godot writes without a
+// trailing '.0' for whole floats.
+func encodeComponent(out *strings.Builder, value any) error {
+	if f, ok := value.(float64); ok {
+		out.WriteString(formatComponent(f))
+
+		return nil
+	}
+
+	return encode(out, value)
+}
+
+/* -------------------------- Function: writeArray -------------------------- */
+
+func writeArray(out *strings.Builder, elements []any) error {
+	out.WriteString("[")
+
+	for i, e := range elements {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+
+		if err := encode(out, e); err != nil {
+			return err
+		}
+	}
+
+	out.WriteString("]")
+
+	return nil
+}
+
+/* ------------------------ Function: writeDictionary ----------------------- */
+
+func writeDictionary(out *strings.Builder, entries Dictionary) error {
+	out.WriteString("{")
+
+	for i, e := range entries {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+
+		if err := encode(out, e.Key); err != nil {
+			return err
+		}
+
+		out.WriteString(": ")
+
+		if err := encode(out, e.Value); err != nil {
+			return err
+		}
+	}
+
+	out.WriteString("}")
+
+	return nil
+}
+
+/* ------------------------ Function: writeConstructor ---------------------- */
+
+func writeConstructor(out *strings.Builder, name string, components ...float64) {
+	out.WriteString(name + "(" + formatComponents(components...) + ")")
+}
+
+/* --------------------------- Function: writePacked ------------------------ */
+
+func writePacked[T any](out *strings.Builder, name string, elements []T, fn func(T) string) {
+	out.WriteString(name + "(")
+
+	for i, e := range elements {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+
+		out.WriteString(fn(e))
+	}
+
+	out.WriteString(")")
+}
+
+/* -------------------------- Function: sortedEntries ----------------------- */
+
+func sortedEntries(table map[string]any) Dictionary {
+	keys := make([]string, 0, len(table))
+	for k := range table {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	out := make(Dictionary, len(keys))
+	for i, k := range keys {
+		out[i] = Entry{Key: k, Value: table[k]}
+	}
+
+	return out
+}
+
+/* -------------------------- Function: formatFloat ------------------------- */
+
+// formatFloat formats a standalone float, which must contain a decimal point
+// (or exponent) so that it's not parsed as an integer.
+func formatFloat(f float64) string {
+	s := formatComponent(f)
+	if math.IsInf(f, 0) || math.IsNaN(f) || strings.ContainsAny(s, ".e") {
+		return s
+	}
+
+	return s + ".0"
+}
+
+/* ------------------------ Function: formatComponent ----------------------- */
+
+// formatComponent formats a float within a constructor (e.g. 'Vector2(1, 2)').
+func formatComponent(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "inf_neg"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+/* ----------------------- Function: formatComponents ----------------------- */
+
+func formatComponents(components ...float64) string {
+	elements := make([]string, len(components))
+	for i, c := range components {
+		elements[i] = formatComponent(c)
+	}
+
+	return strings.Join(elements, ", ")
+}
+
+/* ----------------------------- Function: quote ---------------------------- */
+
+// quote encodes 's' as a Godot string literal. Unlike Godot, line breaks are
+// escaped so that values are written on a single line.
+func quote(s string) string {
+	var out strings.Builder
+
+	out.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			out.WriteString(`\` + string(r))
+		case '\n':
+			out.WriteString(`\n`)
+		case '\r':
+			out.WriteString(`\r`)
+		case '\t':
+			out.WriteString(`\t`)
+		default:
+			if unicode.IsControl(r) {
+				fmt.Fprintf(&out, `\u%04x`, r)
+
+				continue
+			}
+
+			out.WriteRune(r)
+		}
+	}
+
+	out.WriteByte('"')
+
+	return out.String()
+}
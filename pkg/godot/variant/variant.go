@@ -0,0 +1,162 @@
+package variant
+
+import (
+	"errors"
+	"reflect"
+)
+
+var (
+	ErrInvalidInput    = errors.New("invalid input")
+	ErrUnsupportedType = errors.New("unsupported type")
+)
+
+const (
+	nameArray      = "Array"
+	nameColor      = "Color"
+	nameDictionary = "Dictionary"
+	nameNodePath   = "NodePath"
+	nameObject     = "Object"
+	nameVector2    = "Vector2"
+	nameVector2i   = "Vector2i"
+	nameVector3    = "Vector3"
+	nameVector3i   = "Vector3i"
+
+	namePackedByteArray    = "PackedByteArray"
+	namePackedColorArray   = "PackedColorArray"
+	namePackedFloat32Array = "PackedFloat32Array"
+	namePackedFloat64Array = "PackedFloat64Array"
+	namePackedInt32Array   = "PackedInt32Array"
+	namePackedInt64Array   = "PackedInt64Array"
+	namePackedStringArray  = "PackedStringArray"
+	namePackedVector2Array = "PackedVector2Array"
+	namePackedVector3Array = "PackedVector3Array"
+)
+
+/* -------------------------------------------------------------------------- */
+/*                                String types                                */
+/* -------------------------------------------------------------------------- */
+
+// StringName is a Godot 'StringName' (i.e. '&"name"').
+type StringName string
+
+// NodePath is a Godot 'NodePath' (i.e. 'NodePath("path")' or '^"path"').
+type NodePath string
+
+/* -------------------------------------------------------------------------- */
+/*                                 Math types                                 */
+/* -------------------------------------------------------------------------- */
+
+// Vector2 is a Godot 'Vector2'.
+type Vector2 struct {
+	X, Y float64
+}
+
+// Vector2i is a Godot 'Vector2i'.
+type Vector2i struct {
+	X, Y int64
+}
+
+// Vector3 is a Godot 'Vector3'.
+type Vector3 struct {
+	X, Y, Z float64
+}
+
+// Vector3i is a Godot 'Vector3i'.
+type Vector3i struct {
+	X, Y, Z int64
+}
+
+// Color is a Godot 'Color', with components in the range '[0, 1]'.
+type Color struct {
+	R, G, B, A float64
+}
+
+/* -------------------------------------------------------------------------- */
+/*                              Container types                               */
+/* -------------------------------------------------------------------------- */
+
+// TypedArray is a Godot 'Array' with a static element type (e.g.
+// 'Array[int]([1, 2])').
+type TypedArray struct {
+	// Type is the element type, as written (e.g. 'int' or 'ExtResource("1")').
+	Type     string
+	Elements []any
+}
+
+// TypedDictionary is a Godot 'Dictionary' with static key and value types
+// (e.g. 'Dictionary[String, int]({"a": 1})').
+type TypedDictionary struct {
+	// KeyType is the key type, as written.
+	KeyType string
+	// ValueType is the value type, as written.
+	ValueType string
+	Entries   Dictionary
+}
+
+// Dictionary is a Godot 'Dictionary'. Keys may be any value, so entries are
+// stored in order rather than in a map.
+type Dictionary []Entry
+
+// Entry is a single key-value pair within a 'Dictionary'.
+type Entry struct {
+	Key   any
+	Value any
+}
+
+/* ------------------------------- Method: Get ------------------------------ */
+
+// Get returns the value of the first entry with the specified key.
+func (d Dictionary) Get(key any) (any, bool) {
+	for _, e := range d {
+		if reflect.DeepEqual(e.Key, key) {
+			return e.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+/* ------------------------------- Method: Map ------------------------------ */
+
+// Map returns the dictionary as a map if all of its keys are strings.
+func (d Dictionary) Map() (map[string]any, bool) {
+	out := make(map[string]any, len(d))
+
+	for _, e := range d {
+		k, ok := e.Key.(string)
+		if !ok {
+			return nil, false
+		}
+
+		out[k] = e.Value
+	}
+
+	return out, true
+}
+
+/* -------------------------------------------------------------------------- */
+/*                               Object types                                 */
+/* -------------------------------------------------------------------------- */
+
+// Object is an inline Godot 'Object' (i.e. 'Object(Class,"key":value,...)').
+type Object struct {
+	Class      string
+	Properties Dictionary
+}
+
+// Reference is a reference to a resource, either within the same file (i.e.
+// 'SubResource("id")'), to an external resource declared in the same file
+// (i.e. 'ExtResource("id")'), or by path (i.e. 'Resource("res://...")').
+type Reference struct {
+	// Kind is the name of the reference constructor (e.g. 'ExtResource').
+	Kind string
+	// ID is the referenced resource's ID or path.
+	ID string
+}
+
+// Constructor is a value written using a constructor which this package doesn't
+// otherwise support (e.g. 'Transform3D(...)' or 'Rect2(...)').
+type Constructor struct {
+	Name string
+	Args []any
+}
@@ -0,0 +1,202 @@
+package variant_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coffeebeats/gdbuild/pkg/godot/variant"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+
+		raw string
+
+		want any
+		err  error
+	}{
+		{name: "null", raw: "null", want: nil},
+		{name: "bool", raw: "true", want: true},
+		{name: "int", raw: "-42", want: int64(-42)},
+		{name: "hex int", raw: "0xFF", want: int64(255)},
+		{name: "float", raw: "1.5e-05", want: 1.5e-05},
+		{name: "whole float", raw: "2.0", want: 2.0},
+		{name: "negative infinity", raw: "inf_neg", want: math.Inf(-1)},
+		{name: "string", raw: `"a \"b\"\né \U01F600 res://c"`, want: "a \"b\"\né \U0001F600 res://c"},
+		{name: "multi-line string", raw: "\"a\nb\"", want: "a\nb"},
+		{name: "string name", raw: `&"name"`, want: variant.StringName("name")},
+		{name: "node path", raw: `NodePath("a/b:c")`, want: variant.NodePath("a/b:c")},
+		{name: "node path shorthand", raw: `^"a/b"`, want: variant.NodePath("a/b")},
+		{name: "array", raw: `[1, "a", [true], ]`, want: []any{int64(1), "a", []any{true}}},
+		{name: "empty array", raw: `[]`, want: []any{}},
+		{
+			name: "typed array",
+			raw:  `Array[ExtResource("1_abc")]([1])`,
+			want: variant.TypedArray{Type: `ExtResource("1_abc")`, Elements: []any{int64(1)}},
+		},
+		{
+			name: "multi-line dictionary",
+			raw:  "{\n\"a\": 1,\n2: PackedStringArray(\"x\") ; Comment.\n}",
+			want: variant.Dictionary{{Key: "a", Value: int64(1)}, {Key: int64(2), Value: []string{"x"}}},
+		},
+		{
+			name: "typed dictionary",
+			raw:  `Dictionary[String, int]({"a": 1})`,
+			want: variant.TypedDictionary{
+				KeyType:   "String",
+				ValueType: "int",
+				Entries:   variant.Dictionary{{Key: "a", Value: int64(1)}},
+			},
+		},
+		{name: "packed string array", raw: `PackedStringArray("res://a", "b")`, want: []string{"res://a", "b"}},
+		{name: "packed int32 array", raw: `PackedInt32Array(1, -2)`, want: []int32{1, -2}},
+		{name: "packed int64 array", raw: `PackedInt64Array(1, -2)`, want: []int64{1, -2}},
+		{name: "packed float32 array", raw: `PackedFloat32Array(1, 2.5)`, want: []float32{1, 2.5}},
+		{name: "packed float64 array", raw: `PackedFloat64Array(1, 2.5)`, want: []float64{1, 2.5}},
+		{name: "packed byte array", raw: `PackedByteArray(0, 255)`, want: []byte{0, 255}},
+		{name: "base64 packed byte array", raw: `PackedByteArray("AP8=")`, want: []byte{0, 255}},
+		{name: "packed byte array out of range", raw: `PackedByteArray(256)`, err: variant.ErrInvalidInput},
+		{
+			name: "packed vector2 array",
+			raw:  `PackedVector2Array(1, 2, 3, 4.5)`,
+			want: []variant.Vector2{{X: 1, Y: 2}, {X: 3, Y: 4.5}},
+		},
+		{name: "packed vector3 array", raw: `PackedVector3Array(1, 2, 3)`, want: []variant.Vector3{{X: 1, Y: 2, Z: 3}}},
+		{name: "packed color array", raw: `PackedColorArray(1, 0, 0, 1)`, want: []variant.Color{{R: 1, G: 0, B: 0, A: 1}}},
+		{name: "packed vector2 array with odd size", raw: `PackedVector2Array(1, 2, 3)`, err: variant.ErrInvalidInput},
+		{name: "vector2", raw: `Vector2(1, -2.5)`, want: variant.Vector2{X: 1, Y: -2.5}},
+		{name: "vector2i", raw: `Vector2i(640, 480)`, want: variant.Vector2i{X: 640, Y: 480}},
+		{name: "vector3", raw: `Vector3(0, 1, 0.5)`, want: variant.Vector3{X: 0, Y: 1, Z: 0.5}},
+		{name: "vector3i", raw: `Vector3i(1, 2, 3)`, want: variant.Vector3i{X: 1, Y: 2, Z: 3}},
+		{name: "vector2 with wrong arguments", raw: `Vector2(1)`, err: variant.ErrInvalidInput},
+		{name: "color", raw: `Color(1, 0.5, 0, 1)`, want: variant.Color{R: 1, G: 0.5, B: 0, A: 1}},
+		{
+			name: "object",
+			raw:  `Object(InputEventKey,"resource_local_to_scene":false,"keycode":4194320)`,
+			want: variant.Object{Class: "InputEventKey", Properties: variant.Dictionary{
+				{Key: "resource_local_to_scene", Value: false},
+				{Key: "keycode", Value: int64(4194320)},
+			}},
+		},
+		{name: "external resource", raw: `ExtResource("1_abc")`, want: variant.Reference{Kind: "ExtResource", ID: "1_abc"}},
+		{name: "godot 3 external resource", raw: `ExtResource( 2 )`, want: variant.Reference{Kind: "ExtResource", ID: "2"}},
+		{
+			name: "other constructor",
+			raw:  `Rect2(0, 0, 1.5, 2)`,
+			want: variant.Constructor{Name: "Rect2", Args: []any{int64(0), int64(0), 1.5, int64(2)}},
+		},
+		{name: "unterminated string", raw: `"abc`, err: variant.ErrInvalidInput},
+		{name: "unterminated array", raw: `[1, 2`, err: variant.ErrInvalidInput},
+		{name: "trailing input", raw: `1 2`, err: variant.ErrInvalidInput},
+		{name: "unknown identifier", raw: `abc`, err: variant.ErrInvalidInput},
+		{name: "empty input", raw: ``, err: variant.ErrInvalidInput},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: The value is decoded.
+			got, err := variant.Decode(tc.raw)
+
+			// Then: The returned error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+
+			// Then: The decoded value matches expectations.
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name string
+
+		value any
+
+		want string
+		err  error
+	}{
+		{name: "null", value: nil, want: "null"},
+		{name: "bool", value: false, want: "false"},
+		{name: "int", value: uint16(7), want: "7"},
+		{name: "float", value: 0.25, want: "0.25"},
+		{name: "whole float", value: float32(3), want: "3.0"},
+		{name: "infinity", value: math.Inf(-1), want: "inf_neg"},
+		{name: "string", value: "a \"b\"\nc\\", want: `"a \"b\"\nc\\"`},
+		{name: "string with resource path", value: "res://a, res://b", want: `"res://a, res://b"`},
+		{name: "string name", value: variant.StringName("a"), want: `&"a"`},
+		{name: "node path", value: variant.NodePath("a/b"), want: `NodePath("a/b")`},
+		{name: "vector2", value: variant.Vector2{X: 1, Y: 2.5}, want: "Vector2(1, 2.5)"},
+		{name: "vector3i", value: variant.Vector3i{X: 1, Y: 2, Z: 3}, want: "Vector3i(1, 2, 3)"},
+		{name: "color", value: variant.Color{R: 1, G: 1, B: 1, A: 0.5}, want: "Color(1, 1, 1, 0.5)"},
+		{name: "packed string array", value: []string{"a", "b"}, want: `PackedStringArray("a", "b")`},
+		{name: "empty packed string array", value: []string{}, want: `PackedStringArray()`},
+		{name: "packed byte array", value: []byte{1, 2}, want: `PackedByteArray(1, 2)`},
+		{name: "packed float32 array", value: []float32{1, 0.5}, want: `PackedFloat32Array(1, 0.5)`},
+		{
+			name:  "packed vector2 array",
+			value: []variant.Vector2{{X: 1, Y: 2}, {X: 3, Y: 4}},
+			want:  `PackedVector2Array(1, 2, 3, 4)`,
+		},
+		{name: "array", value: []any{int64(1), "a", 1.0}, want: `[1, "a", 1.0]`},
+		{name: "slice of ints", value: []int{1, 2}, want: `[1, 2]`},
+		{
+			name:  "typed array",
+			value: variant.TypedArray{Type: "int", Elements: []any{1}},
+			want:  `Array[int]([1])`,
+		},
+		{
+			name:  "map",
+			value: map[string]any{"b": true, "a": []any{}},
+			want:  `{"a": [], "b": true}`,
+		},
+		{name: "map of strings", value: map[string]string{"a": "b"}, want: `{"a": "b"}`},
+		{
+			name:  "dictionary",
+			value: variant.Dictionary{{Key: int64(2), Value: "a"}, {Key: "b", Value: nil}},
+			want:  `{2: "a", "b": null}`,
+		},
+		{
+			name:  "object",
+			value: variant.Object{Class: "InputEventKey", Properties: variant.Dictionary{{Key: "keycode", Value: 1}}},
+			want:  `Object(InputEventKey,"keycode":1)`,
+		},
+		{name: "reference", value: variant.Reference{Kind: "SubResource", ID: "x"}, want: `SubResource("x")`},
+		{
+			name:  "constructor",
+			value: variant.Constructor{Name: "Rect2", Args: []any{int64(0), 1.0, 1.5}},
+			want:  `Rect2(0, 1, 1.5)`,
+		},
+		{name: "unsupported type", value: struct{}{}, err: variant.ErrUnsupportedType},
+		{name: "nested unsupported type", value: []any{make(chan int)}, err: variant.ErrUnsupportedType},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// When: The value is encoded.
+			got, err := variant.Encode(tc.value)
+
+			// Then: The returned error matches expectations.
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("output: got %v, want %v", err, tc.err)
+			}
+
+			// Then: The encoded value matches expectations.
+			assert.Equal(t, tc.want, got)
+
+			if tc.err != nil {
+				return
+			}
+
+			// Then: The encoded value can be decoded.
+			_, err = variant.Decode(got)
+			require.NoError(t, err)
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/coffeebeats/gdbuild/internal/osutil"
 	"github.com/coffeebeats/gdbuild/pkg/godot/engine"
 	"github.com/coffeebeats/gdbuild/pkg/godot/platform"
+	"github.com/coffeebeats/gdbuild/pkg/godot/project"
 )
 
 var ErrMissingInput = errors.New("missing input")
@@ -35,12 +36,19 @@ type Context struct {
 	// Verbose determines whether to enable additional logging output.
 	Verbose bool
 
+	// EncryptionKey is the hex-encoded script encryption key. It's resolved
+	// once per invocation since reading it may require running a command.
+	EncryptionKey string
+
 	// Features is the list of feature tags to enable.
 	Features []string
 	// Platform is the target platform to build for.
 	Platform platform.OS
 	// Profile is the GDBuild optimization level to build with.
 	Profile engine.Profile
+	// Project contains the settings of the Godot project being exported. Like
+	// 'Target', it's only set for 'gdbuild target'.
+	Project *project.Project
 
 	// PathManifest is the path to the GDBuild manifest. This is used to locate
 	// relative paths in various other properties.
@@ -53,6 +53,32 @@ func TargetArchive(storePath string, checksum string) (string, error) {
 	return filepath.Join(storePath, storeDirExport, checksum+archive.FileExtension), nil
 }
 
+/* -------------------------------------------------------------------------- */
+/*                           Function: ImportArchive                           */
+/* -------------------------------------------------------------------------- */
+
+// ImportArchive returns the full path (starting with the store path) to the
+// archive of a Godot project's import state within the store. Archives are
+// grouped by 'key', which identifies the editor and import settings used, and
+// named by a checksum of the imported assets.
+//
+// NOTE: This does *not* mean the import archive exists.
+func ImportArchive(storePath string, key, checksum string) (string, error) {
+	if storePath == "" {
+		return "", ErrMissingStore
+	}
+
+	if key == "" {
+		return "", fmt.Errorf("%w: key: %s", ErrInvalidInput, key)
+	}
+
+	if checksum == "" {
+		return "", fmt.Errorf("%w: checksum: %s", ErrInvalidInput, checksum)
+	}
+
+	return filepath.Join(storePath, storeDirImport, key, checksum+archive.FileExtension), nil
+}
+
 /* -------------------------------------------------------------------------- */
 /*                               Function: Path                               */
 /* -------------------------------------------------------------------------- */
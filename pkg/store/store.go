@@ -7,14 +7,17 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 
+	"github.com/coffeebeats/gdbuild/internal/archive"
 	"github.com/coffeebeats/gdbuild/internal/osutil"
 )
 
 const (
 	storeDirExport   = "exports"
+	storeDirImport   = "imports"
 	storeDirTemplate = "templates"
 	storeFileLayout  = "layout.v0" // simplify migrating in the future
 )
@@ -39,6 +42,11 @@ func Clear(storePath string) error {
 		return err
 	}
 
+	// Clear the entire project import cache directory.
+	if err := os.RemoveAll(filepath.Join(storePath, storeDirImport)); err != nil {
+		return err
+	}
+
 	// Clear the entire export template cache directory.
 	if err := os.RemoveAll(filepath.Join(storePath, storeDirTemplate)); err != nil {
 		return err
@@ -102,6 +110,59 @@ func HasTemplate(storePath string, checksum string) (bool, error) {
 	return true, nil
 }
 
+/* -------------------------------------------------------------------------- */
+/*                        Function: LatestImportArchive                       */
+/* -------------------------------------------------------------------------- */
+
+// LatestImportArchive returns the path to the most recently cached import
+// archive with the specified key (see 'ImportArchive'). An empty string is
+// returned if there isn't one.
+func LatestImportArchive(storePath string, key string) (string, error) {
+	if storePath == "" {
+		return "", ErrMissingStore
+	}
+
+	if key == "" {
+		return "", fmt.Errorf("%w: 'key'", ErrMissingInput)
+	}
+
+	root := filepath.Join(storePath, storeDirImport, key)
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		return "", nil
+	}
+
+	var (
+		latest  string
+		modTime time.Time
+	)
+
+	for _, entry := range entries {
+		// NOTE: Skip hidden files, which are archives still being written.
+		if entry.IsDir() ||
+			strings.HasPrefix(entry.Name(), ".") ||
+			!strings.HasSuffix(entry.Name(), archive.FileExtension) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return "", err
+		}
+
+		if latest == "" || info.ModTime().After(modTime) {
+			latest, modTime = filepath.Join(root, entry.Name()), info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
+
 /* -------------------------------------------------------------------------- */
 /*                            Function: ListExports                           */
 /* -------------------------------------------------------------------------- */
@@ -158,6 +219,48 @@ func ListTemplates(storePath string) ([]osutil.Path, error) {
 	return out, nil
 }
 
+/* -------------------------------------------------------------------------- */
+/*                        Function: PruneImportArchives                       */
+/* -------------------------------------------------------------------------- */
+
+// PruneImportArchives removes all cached import archives with the specified
+// key (see 'ImportArchive') other than the archive at 'keep'.
+func PruneImportArchives(storePath string, key, keep string) error {
+	if storePath == "" {
+		return ErrMissingStore
+	}
+
+	if key == "" {
+		return fmt.Errorf("%w: 'key'", ErrMissingInput)
+	}
+
+	root := filepath.Join(storePath, storeDirImport, key)
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		return nil
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(root, entry.Name())
+		if entry.IsDir() || path == keep || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		log.Debugf("removed stale import archive from store: %s", entry.Name())
+	}
+
+	return nil
+}
+
 /* -------------------------------------------------------------------------- */
 /*                              Function: Remove                              */
 /* -------------------------------------------------------------------------- */
@@ -234,7 +337,7 @@ func Touch(storePath string) error {
 	}
 
 	// Create the required subdirectories, if needed.
-	for _, d := range []string{storeDirExport, storeDirTemplate} {
+	for _, d := range []string{storeDirExport, storeDirImport, storeDirTemplate} {
 		path := filepath.Join(storePath, d)
 		if err := os.MkdirAll(path, osutil.ModeUserRWXGroupRX); err != nil {
 			return err
@@ -3,10 +3,14 @@ package target
 import (
 	"context"
 	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/log"
 
 	"github.com/coffeebeats/gdbuild/internal/action"
 	"github.com/coffeebeats/gdbuild/internal/archive"
 	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/godot/encryption"
 	"github.com/coffeebeats/gdbuild/pkg/godot/export"
 	"github.com/coffeebeats/gdbuild/pkg/run"
 	"github.com/coffeebeats/gdbuild/pkg/store"
@@ -44,10 +48,17 @@ func Action(rc *run.Context, xp *export.Export) (action.Action, error) { //nolin
 		return nil, err
 	}
 
+	var verifyPacks action.Action
+	if xp.VerifyPacks {
+		verifyPacks = export.NewVerifyPacksAction(rc, xp)
+	}
+
 	return action.InOrder(
 		export.NewInstallEditorGodotAction(rc, xp.Version, rc.GodotPath()),
 		xp.RunBefore,
 		exportAction,
+		verifyPacks,
+		xp.Postexport,
 		xp.RunAfter,
 		run.NewVerifyArtifactsAction(rc, rc.PathOut, artifacts),
 		cacheArtifacts,
@@ -60,7 +71,8 @@ func Action(rc *run.Context, xp *export.Export) (action.Action, error) { //nolin
 
 // NewExtractTemplateAction creates an 'action.Action' which extracts the cached
 // Godot export template into a temporary directory and populates the provided
-// string variable 'path' with a path to it.
+// string variable 'path' with a path to it. The export template's recorded
+// encryption key fingerprint, if any, must match the key being exported with.
 func NewExtractTemplateAction(
 	rc *run.Context,
 	pathArchive osutil.Path,
@@ -71,7 +83,26 @@ func NewExtractTemplateAction(
 	}
 
 	fn := func(ctx context.Context) error {
-		return archive.Extract(ctx, pathArchive.String(), pathTmp)
+		if err := archive.Extract(ctx, pathArchive.String(), pathTmp); err != nil {
+			return err
+		}
+
+		ok, err := encryption.VerifyFingerprintFile(pathTmp, rc.EncryptionKey)
+		if err != nil {
+			return fmt.Errorf(
+				"cannot export target '%s' using template '%s' (an export template embeds a single key, "+
+					"so targets sharing one must use the same key): %w",
+				rc.Target,
+				pathArchive,
+				err,
+			)
+		}
+
+		if !ok && rc.EncryptionKey != "" {
+			log.Warnf("export template has no recorded encryption key; unable to verify key: %s", pathArchive)
+		}
+
+		return nil
 	}
 
 	return action.WithDescription[action.Function]{
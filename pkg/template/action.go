@@ -1,9 +1,12 @@
 package template
 
 import (
+	"context"
 	"errors"
 
 	"github.com/coffeebeats/gdbuild/internal/action"
+	"github.com/coffeebeats/gdbuild/internal/osutil"
+	"github.com/coffeebeats/gdbuild/pkg/godot/encryption"
 	"github.com/coffeebeats/gdbuild/pkg/godot/template"
 	"github.com/coffeebeats/gdbuild/pkg/run"
 	"github.com/coffeebeats/gdbuild/pkg/store"
@@ -35,6 +38,21 @@ func Action(rc *run.Context, tl *template.Template) (action.Action, error) { //n
 	pathBin := rc.BinPath()
 	artifacts := tl.Artifacts(rc)
 
+	// Record the encryption key's fingerprint alongside the template so that
+	// exports can detect a template compiled with a different key.
+	var writeFingerprint action.Action
+
+	for _, b := range tl.Builds {
+		if b.EncryptionKey == "" {
+			continue
+		}
+
+		writeFingerprint = newWriteFingerprintAction(pathBin, b.EncryptionKey)
+		artifacts = append(artifacts, encryption.FilenameFingerprint)
+
+		break
+	}
+
 	cacheArtifacts, err := store.NewCacheTemplateAction(rc, pathBin, artifacts, cs)
 	if err != nil {
 		return nil, err
@@ -43,6 +61,7 @@ func Action(rc *run.Context, tl *template.Template) (action.Action, error) { //n
 	actions = append(
 		actions,
 		tl.Postbuild,
+		writeFingerprint,
 		run.NewVerifyArtifactsAction(rc, pathBin, artifacts),
 		cacheArtifacts,
 		run.NewCopyArtifactsAction(rc, pathBin, artifacts),
@@ -50,3 +69,16 @@ func Action(rc *run.Context, tl *template.Template) (action.Action, error) { //n
 
 	return action.InOrder(actions...), nil
 }
+
+/* ------------------- Function: newWriteFingerprintAction ------------------ */
+
+func newWriteFingerprintAction(root osutil.Path, key string) action.WithDescription[action.Function] {
+	fn := func(_ context.Context) error {
+		return encryption.WriteFingerprintFile(root.String(), key)
+	}
+
+	return action.WithDescription[action.Function]{
+		Action:      fn,
+		Description: "record encryption key fingerprint: " + encryption.Fingerprint(key),
+	}
+}